@@ -0,0 +1,174 @@
+// Command merkle builds merkle tree roots and proofs from files of leaves and verifies the proofs.
+//
+// Usage:
+//
+//	merkle root   [flags] [file]
+//	merkle prove  [flags] -indices 1,4 [file]
+//	merkle verify [-hasher name] -root hex [proof file]
+//
+// The leaves are read from the file or from stdin. They are hashed with the hasher before the tree is built, unless
+// -hash-leaves=false is given because the input already consists of leaf hashes. The proofs are printed and read in
+// the JSON format of merkle.Proof.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ComposableFi/go-merkle-trees/internal/cli"
+	"github.com/ComposableFi/go-merkle-trees/merkle"
+)
+
+const usage = `usage:
+  merkle root   [flags] [file]
+  merkle prove  [flags] -indices 1,4 [file]
+  merkle verify [-hasher name] -root hex [proof file]`
+
+var (
+	errUsage               = errors.New(usage)
+	errNoLeaves            = errors.New("there are no leaves in the input")
+	errIndexOutOfRange     = errors.New("leaf index is out of the tree range")
+	errProofNotVerified    = errors.New("proof is not valid")
+	errUnexpectedArguments = errors.New("too many arguments")
+	errMissingRoot         = errors.New("the root is required")
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "merkle:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command of the arguments, it's separated from main to be tested
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "root":
+		return runRoot(args[1:], stdin, stdout, stderr)
+	case "prove":
+		return runProve(args[1:], stdin, stdout, stderr)
+	case "verify":
+		return runVerify(args[1:], stdin, stdout, stderr)
+	}
+	return errUsage
+}
+
+// buildTree reads the leaves from the file argument of the command and builds the tree, it returns the tree and the
+// number of its leaves
+func buildTree(fs *flag.FlagSet, flags cli.LeafFlags, stdin io.Reader) (merkle.Tree, uint64, error) {
+	if fs.NArg() > 1 {
+		return merkle.Tree{}, 0, errUnexpectedArguments
+	}
+
+	h, leaves, err := flags.ReadLeaves(fs.Arg(0), stdin)
+	if err != nil {
+		return merkle.Tree{}, 0, err
+	}
+	if len(leaves) == 0 {
+		return merkle.Tree{}, 0, errNoLeaves
+	}
+
+	tree, err := merkle.NewTree(h).FromLeaves(leaves)
+	return tree, uint64(len(leaves)), err
+}
+
+func runRoot(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("root", stderr)
+	flags := cli.NewLeafFlags(fs, "sha256")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tree, _, err := buildTree(fs, flags, stdin)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(stdout, cli.EncodeHex(tree.Root()))
+	return err
+}
+
+func runProve(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("prove", stderr)
+	flags := cli.NewLeafFlags(fs, "sha256")
+	indicesList := fs.String("indices", "", "the comma separated indices of the proven leaves")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	indices, err := cli.ParseIndices(*indicesList)
+	if err != nil {
+		return err
+	}
+	tree, leavesCount, err := buildTree(fs, flags, stdin)
+	if err != nil {
+		return err
+	}
+	if indices[len(indices)-1] >= leavesCount {
+		return errIndexOutOfRange
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(tree.Proof(indices))
+}
+
+func runVerify(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("verify", stderr)
+	hasherName := fs.String("hasher", "sha256", "the hash function, sha256 or keccak256")
+	rootHex := fs.String("root", "", "the hex encoded root")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return errUnexpectedArguments
+	}
+	if *rootHex == "" {
+		return errMissingRoot
+	}
+
+	h, err := cli.NewHasher(*hasherName)
+	if err != nil {
+		return err
+	}
+	root, err := cli.ParseHex(*rootHex)
+	if err != nil {
+		return err
+	}
+
+	input, err := cli.OpenInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	proof := merkle.NewProof(nil, nil, 0, h)
+	if err := json.NewDecoder(input).Decode(&proof); err != nil {
+		return err
+	}
+
+	verified, err := proof.Verify(root)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errProofNotVerified
+	}
+
+	_, err = fmt.Fprintln(stdout, "proof is valid")
+	return err
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("merkle "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/merkle"
+	"github.com/stretchr/testify/require"
+)
+
+// the leaves and the root of examples/sha256
+const (
+	exampleLeaves = "Hello\nDorood\nHi\nHey\nHola\n"
+	exampleRoot   = "0xb56268c89cf6081da5ba203b8e113464ee905fff1d86b24d1ca87f629d717544"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestRoot(t *testing.T) {
+	out, err := runCommand(t, exampleLeaves, "root")
+	require.NoError(t, err)
+	require.Equal(t, exampleRoot+"\n", out)
+
+	// the same leaves hashed in advance and hex encoded
+	var hexLeaves []string
+	for _, leaf := range strings.Fields(exampleLeaves) {
+		h, err := hasher.Sha256Hasher{}.Hash([]byte(leaf))
+		require.NoError(t, err)
+		hexLeaves = append(hexLeaves, "0x"+hex.EncodeToString(h))
+	}
+	out, err = runCommand(t, strings.Join(hexLeaves, "\n"), "root", "-format", "hex", "-hash-leaves=false")
+	require.NoError(t, err)
+	require.Equal(t, exampleRoot+"\n", out)
+
+	// the file is split into the chunks of the given size
+	path := filepath.Join(t.TempDir(), "leaves")
+	require.NoError(t, os.WriteFile(path, []byte("aaaabbbbcc"), 0o600))
+	out, err = runCommand(t, "", "root", "-format", "chunks", "-chunk-size", "4", "-hasher", "keccak256", path)
+	require.NoError(t, err)
+
+	var chunks [][]byte
+	for _, chunk := range []string{"aaaa", "bbbb", "cc"} {
+		h, err := hasher.Keccak256Hasher{}.Hash([]byte(chunk))
+		require.NoError(t, err)
+		chunks = append(chunks, h)
+	}
+	tree, err := merkle.NewTree(hasher.Keccak256Hasher{}).FromLeaves(chunks)
+	require.NoError(t, err)
+	require.Equal(t, "0x"+tree.RootHex()+"\n", out)
+}
+
+func TestProveAndVerify(t *testing.T) {
+	proof, err := runCommand(t, exampleLeaves, "prove", "-indices", "3,1,3")
+	require.NoError(t, err)
+	require.Contains(t, proof, `"totalLeavesCount": 5`)
+
+	out, err := runCommand(t, proof, "verify", "-root", exampleRoot)
+	require.NoError(t, err)
+	require.Equal(t, "proof is valid\n", out)
+
+	path := filepath.Join(t.TempDir(), "proof.json")
+	require.NoError(t, os.WriteFile(path, []byte(proof), 0o600))
+	_, err = runCommand(t, "", "verify", "-root", exampleRoot, path)
+	require.NoError(t, err)
+
+	_, err = runCommand(t, proof, "verify", "-root", strings.Replace(exampleRoot, "b5", "b6", 1))
+	require.ErrorIs(t, err, errProofNotVerified)
+	_, err = runCommand(t, proof, "verify", "-root", exampleRoot, "-hasher", "keccak256")
+	require.ErrorIs(t, err, errProofNotVerified)
+}
+
+func TestInvalidArguments(t *testing.T) {
+	_, err := runCommand(t, exampleLeaves)
+	require.ErrorIs(t, err, errUsage)
+	_, err = runCommand(t, exampleLeaves, "build")
+	require.ErrorIs(t, err, errUsage)
+	_, err = runCommand(t, "", "root")
+	require.ErrorIs(t, err, errNoLeaves)
+	_, err = runCommand(t, exampleLeaves, "prove", "-indices", "5")
+	require.ErrorIs(t, err, errIndexOutOfRange)
+	_, err = runCommand(t, exampleLeaves, "prove")
+	require.Error(t, err)
+	_, err = runCommand(t, exampleLeaves, "root", "-hasher", "md5")
+	require.Error(t, err)
+	_, err = runCommand(t, exampleLeaves, "root", "-format", "csv")
+	require.Error(t, err)
+	_, err = runCommand(t, "zz\n", "root", "-format", "hex")
+	require.Error(t, err)
+	_, err = runCommand(t, "{}", "verify")
+	require.ErrorIs(t, err, errMissingRoot)
+	_, err = runCommand(t, "{", "verify", "-root", exampleRoot)
+	require.Error(t, err)
+}
@@ -0,0 +1,342 @@
+// Command mmr builds merkle mountain ranges from files of leaves, inspects them and generates and verifies their
+// proofs.
+//
+// Usage:
+//
+//	mmr append -dir store [flags] [file]
+//	mmr info   [-dir store | flags [file]]
+//	mmr prove  [-dir store | flags [file]] -indices 1,4
+//	mmr verify [-hasher name] -root hex [proof file]
+//
+// append pushes the leaves into the file store of the directory, creating it when it does not exist. info and prove
+// read the mmr of the store, or rebuild it in memory from the leaves of the file or stdin when -dir is not given,
+// which is handy to inspect a leaf dump. The leaves are hashed with the hasher before they are pushed, unless
+// -hash-leaves=false is given because the input already consists of leaf hashes. The proofs are printed and read in
+// the JSON format of mmr.Proof.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ComposableFi/go-merkle-trees/internal/cli"
+	"github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const usage = `usage:
+  mmr append -dir store [flags] [file]
+  mmr info   [-dir store | flags [file]]
+  mmr prove  [-dir store | flags [file]] -indices 1,4
+  mmr verify [-hasher name] -root hex [proof file]`
+
+// defaultHasher is keccak256, the hasher of the Substrate and BEEFY mmrs
+const defaultHasher = "keccak256"
+
+var (
+	errUsage               = errors.New(usage)
+	errNoLeaves            = errors.New("there are no leaves in the mmr")
+	errIndexOutOfRange     = errors.New("leaf index is out of the mmr range")
+	errProofNotVerified    = errors.New("proof is not valid")
+	errUnexpectedArguments = errors.New("too many arguments")
+	errMissingRoot         = errors.New("the root is required")
+	errMissingDir          = errors.New("the store directory is required")
+	errDirWithInput        = errors.New("the leaves file can't be used with the store directory")
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "mmr:", err)
+		os.Exit(1)
+	}
+}
+
+// run executes the command of the arguments, it's separated from main to be tested
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "append":
+		return runAppend(args[1:], stdin, stdout, stderr)
+	case "info":
+		return runInfo(args[1:], stdin, stdout, stderr)
+	case "prove":
+		return runProve(args[1:], stdin, stdout, stderr)
+	case "verify":
+		return runVerify(args[1:], stdin, stdout, stderr)
+	}
+	return errUsage
+}
+
+// tree is an mmr with the store that keeps its nodes
+type tree struct {
+	mmr   *mmr.MMR
+	store mmr.Store
+	close func() error
+}
+
+// openStore opens the file store of the directory for an mmr of the hasher, the store records the hasher name so it
+// can't be reopened with another hasher
+func openStore(dir string, hasherName string, h types.Hasher) (*mmr.FileStore, error) {
+	// the nodes of the store are hashes, so they are as long as the output of the hasher
+	hash, err := h.Hash(nil)
+	if err != nil {
+		return nil, err
+	}
+	return mmr.OpenFileStoreWithHasher(dir, len(hash), cli.HasherName(hasherName))
+}
+
+// openTree opens the mmr of the store directory when it's given, otherwise it builds the mmr in memory from the leaves
+// of the file argument of the command
+func openTree(fs *flag.FlagSet, dir string, flags cli.LeafFlags, stdin io.Reader) (*tree, error) {
+	if fs.NArg() > 1 {
+		return nil, errUnexpectedArguments
+	}
+
+	if dir != "" {
+		if fs.NArg() != 0 {
+			return nil, errDirWithInput
+		}
+		h, err := cli.NewHasher(*flags.Hasher)
+		if err != nil {
+			return nil, err
+		}
+		store, err := openStore(dir, *flags.Hasher, h)
+		if err != nil {
+			return nil, err
+		}
+		return &tree{
+			mmr:   mmr.NewMMR(store.Size(), store, []types.Leaf{}, h),
+			store: store,
+			close: store.Close,
+		}, nil
+	}
+
+	h, leaves, err := flags.ReadLeaves(fs.Arg(0), stdin)
+	if err != nil {
+		return nil, err
+	}
+	store := mmr.NewMemStore()
+	t := &tree{
+		mmr:   mmr.NewMMR(0, store, []types.Leaf{}, h),
+		store: store,
+		close: func() error { return nil },
+	}
+	if err := t.push(leaves); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// push appends the leaves to the mmr and commits them to the store
+func (t *tree) push(leaves [][]byte) error {
+	for i := 0; i < len(leaves); i++ {
+		if _, err := t.mmr.Push(leaves[i]); err != nil {
+			return err
+		}
+	}
+	return t.mmr.Commit()
+}
+
+// leavesCount returns the number of the leaves of the mmr
+func (t *tree) leavesCount() uint64 {
+	return mmr.MMRSizeToLeafCount(t.mmr.MMRSize())
+}
+
+// peaks returns the peak hashes of the mmr from left to right
+func (t *tree) peaks() ([][]byte, error) {
+	var peaks [][]byte
+	for _, pos := range mmr.GetPeaks(t.mmr.MMRSize()) {
+		peak, err := t.store.GetElem(pos)
+		if err != nil {
+			return nil, err
+		}
+		if peak == nil {
+			return nil, mmr.ErrInconsistentStore
+		}
+		peaks = append(peaks, peak)
+	}
+	return peaks, nil
+}
+
+// proof returns the proof of the leaves of the indices, with the leaf hashes set
+func (t *tree) proof(indices []uint64) (*mmr.Proof, error) {
+	if indices[len(indices)-1] >= t.leavesCount() {
+		return nil, errIndexOutOfRange
+	}
+
+	positions := make([]uint64, len(indices))
+	leaves := make([]types.Leaf, len(indices))
+	for i, index := range indices {
+		positions[i] = mmr.LeafIndexToPos(index)
+		hash, err := t.store.GetElem(positions[i])
+		if err != nil {
+			return nil, err
+		}
+		if hash == nil {
+			return nil, mmr.ErrInconsistentStore
+		}
+		leaves[i] = types.Leaf{Index: index, Hash: hash}
+	}
+
+	proof, err := t.mmr.GenProof(positions)
+	if err != nil {
+		return nil, err
+	}
+	proof.LeavesToVerify(leaves)
+	return proof, nil
+}
+
+func runAppend(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("append", stderr)
+	dir := fs.String("dir", "", "the directory of the mmr file store")
+	flags := cli.NewLeafFlags(fs, defaultHasher)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dir == "" {
+		return errMissingDir
+	}
+	if fs.NArg() > 1 {
+		return errUnexpectedArguments
+	}
+
+	h, leaves, err := flags.ReadLeaves(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	store, err := openStore(*dir, *flags.Hasher, h)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	t := &tree{mmr: mmr.NewMMR(store.Size(), store, []types.Leaf{}, h), store: store}
+	if err := t.push(leaves); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "appended %d leaves, the mmr has %d leaves\n", len(leaves), t.leavesCount())
+	return err
+}
+
+func runInfo(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("info", stderr)
+	dir := fs.String("dir", "", "the directory of the mmr file store")
+	flags := cli.NewLeafFlags(fs, defaultHasher)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	t, err := openTree(fs, *dir, flags, stdin)
+	if err != nil {
+		return err
+	}
+	defer t.close()
+	if t.mmr.IsEmpty() {
+		return errNoLeaves
+	}
+
+	root, err := t.mmr.Root()
+	if err != nil {
+		return err
+	}
+	peaks, err := t.peaks()
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(stdout, "root: %s\nmmr size: %d\nleaves: %d\npeaks:\n",
+		cli.EncodeHex(root), t.mmr.MMRSize(), t.leavesCount()); err != nil {
+		return err
+	}
+	for _, peak := range peaks {
+		if _, err := fmt.Fprintf(stdout, "  %s\n", cli.EncodeHex(peak)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runProve(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("prove", stderr)
+	dir := fs.String("dir", "", "the directory of the mmr file store")
+	flags := cli.NewLeafFlags(fs, defaultHasher)
+	indicesList := fs.String("indices", "", "the comma separated indices of the proven leaves")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	indices, err := cli.ParseIndices(*indicesList)
+	if err != nil {
+		return err
+	}
+	t, err := openTree(fs, *dir, flags, stdin)
+	if err != nil {
+		return err
+	}
+	defer t.close()
+
+	proof, err := t.proof(indices)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(proof)
+}
+
+func runVerify(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := newFlagSet("verify", stderr)
+	hasherName := fs.String("hasher", defaultHasher, "the hash function, sha256 or keccak256")
+	rootHex := fs.String("root", "", "the hex encoded root")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return errUnexpectedArguments
+	}
+	if *rootHex == "" {
+		return errMissingRoot
+	}
+
+	h, err := cli.NewHasher(*hasherName)
+	if err != nil {
+		return err
+	}
+	root, err := cli.ParseHex(*rootHex)
+	if err != nil {
+		return err
+	}
+
+	input, err := cli.OpenInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	proof := mmr.NewProof(0, nil, nil, h)
+	if err := json.NewDecoder(input).Decode(proof); err != nil {
+		return err
+	}
+
+	if !proof.Verify(root) {
+		return errProofNotVerified
+	}
+
+	_, err = fmt.Fprintln(stdout, "proof is valid")
+	return err
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("mmr "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/stretchr/testify/require"
+)
+
+// the leaves and the mmr root of examples/keccak
+const (
+	exampleLeaves = "Hello\nDorood\nHi\nHey\nHola\n"
+	exampleRoot   = "0x1adbc0b99fafea65674f1784305bf10f4b8fb48fe93abe122a1359c917553bea"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return stdout.String(), err
+}
+
+func TestInfo(t *testing.T) {
+	out, err := runCommand(t, exampleLeaves, "info")
+	require.NoError(t, err)
+	require.Equal(t, "root: "+exampleRoot+"\n"+
+		"mmr size: 8\n"+
+		"leaves: 5\n"+
+		"peaks:\n"+
+		"  0xfe45c8a894d94d2f06fea0d1debb2288e7bded448cba7a12382c24900ba0880f\n"+
+		"  0x98d72beb4bd8117253d41e6075451e4c62e8f4cb6538536ef1fc13333c7a4085\n", out)
+}
+
+func TestAppend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+
+	out, err := runCommand(t, "Hello\nDorood\nHi\n", "append", "-dir", dir)
+	require.NoError(t, err)
+	require.Equal(t, "appended 3 leaves, the mmr has 3 leaves\n", out)
+
+	path := filepath.Join(t.TempDir(), "leaves")
+	require.NoError(t, os.WriteFile(path, []byte("Hey\nHola\n"), 0o600))
+	out, err = runCommand(t, "", "append", "-dir", dir, path)
+	require.NoError(t, err)
+	require.Equal(t, "appended 2 leaves, the mmr has 5 leaves\n", out)
+
+	stored, err := runCommand(t, "", "info", "-dir", dir)
+	require.NoError(t, err)
+	rebuilt, err := runCommand(t, exampleLeaves, "info")
+	require.NoError(t, err)
+	require.Equal(t, rebuilt, stored)
+
+	// the store records its hasher, so the hashes of another hasher of the same size aren't mixed in
+	_, err = runCommand(t, "Hey\n", "append", "-dir", dir, "-hasher", "sha256")
+	require.ErrorIs(t, err, mmr.ErrHasherMismatch)
+	_, err = runCommand(t, "", "info", "-dir", dir, "-hasher", "sha256")
+	require.ErrorIs(t, err, mmr.ErrHasherMismatch)
+	aliased, err := runCommand(t, "", "info", "-dir", dir, "-hasher", "keccak")
+	require.NoError(t, err)
+	require.Equal(t, stored, aliased)
+}
+
+func TestInfoWriteError(t *testing.T) {
+	err := run([]string{"info"}, strings.NewReader(exampleLeaves), failingWriter{}, &bytes.Buffer{})
+	require.ErrorIs(t, err, errWrite)
+}
+
+var errWrite = errors.New("write failed")
+
+// failingWriter is a writer that fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+func TestProveAndVerify(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	_, err := runCommand(t, exampleLeaves, "append", "-dir", dir)
+	require.NoError(t, err)
+
+	for _, args := range [][]string{{"prove"}, {"prove", "-dir", dir}} {
+		proof, err := runCommand(t, exampleLeaves, append(args, "-indices", "4,1")...)
+		require.NoError(t, err)
+		require.Contains(t, proof, `"mmrSize": 8`)
+
+		out, err := runCommand(t, proof, "verify", "-root", exampleRoot)
+		require.NoError(t, err)
+		require.Equal(t, "proof is valid\n", out)
+
+		_, err = runCommand(t, proof, "verify", "-root", strings.Replace(exampleRoot, "1a", "1b", 1))
+		require.ErrorIs(t, err, errProofNotVerified)
+		_, err = runCommand(t, proof, "verify", "-root", exampleRoot, "-hasher", "sha256")
+		require.ErrorIs(t, err, errProofNotVerified)
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+
+	_, err := runCommand(t, exampleLeaves)
+	require.ErrorIs(t, err, errUsage)
+	_, err = runCommand(t, exampleLeaves, "root")
+	require.ErrorIs(t, err, errUsage)
+	_, err = runCommand(t, exampleLeaves, "append")
+	require.ErrorIs(t, err, errMissingDir)
+	_, err = runCommand(t, "", "info")
+	require.ErrorIs(t, err, errNoLeaves)
+	_, err = runCommand(t, "", "info", "-dir", dir)
+	require.ErrorIs(t, err, errNoLeaves)
+	_, err = runCommand(t, "", "info", "-dir", dir, "leaves.txt")
+	require.ErrorIs(t, err, errDirWithInput)
+	_, err = runCommand(t, exampleLeaves, "prove", "-indices", "5")
+	require.ErrorIs(t, err, errIndexOutOfRange)
+	_, err = runCommand(t, exampleLeaves, "prove")
+	require.Error(t, err)
+	_, err = runCommand(t, exampleLeaves, "info", "-hasher", "md5")
+	require.Error(t, err)
+	_, err = runCommand(t, "{}", "verify")
+	require.ErrorIs(t, err, errMissingRoot)
+	_, err = runCommand(t, `{"mmrSize": 2}`, "verify", "-root", exampleRoot)
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+// Command solgen generates the Solidity libraries that verify the merkle and mmr proofs of this library on Ethereum.
+//
+// Usage:
+//
+//	solgen [-out dir] [-pragma version] [-license id] [-merkle-library name] [-mmr-library name]
+//
+// The merkle verifier is written to MerkleProof.sol and the mmr verifier to MMRProof.sol, named after their
+// libraries. Each file also contains a contract that exposes the library to external calls.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ComposableFi/go-merkle-trees/solidity"
+)
+
+const sourceFileMode = 0o644
+
+func main() {
+	merkleOptions := solidity.DefaultMerkleOptions()
+	mmrOptions := solidity.DefaultMMROptions()
+
+	out := flag.String("out", ".", "the directory of the generated files")
+	pragma := flag.String("pragma", merkleOptions.Pragma, "the solidity version pragma")
+	license := flag.String("license", merkleOptions.License, "the SPDX license identifier")
+	flag.StringVar(&merkleOptions.LibraryName, "merkle-library", merkleOptions.LibraryName,
+		"the name of the merkle proof library")
+	flag.StringVar(&merkleOptions.ContractName, "merkle-contract", merkleOptions.ContractName,
+		"the name of the merkle proof verifier contract")
+	flag.StringVar(&mmrOptions.LibraryName, "mmr-library", mmrOptions.LibraryName, "the name of the mmr proof library")
+	flag.StringVar(&mmrOptions.ContractName, "mmr-contract", mmrOptions.ContractName,
+		"the name of the mmr proof verifier contract")
+	flag.Parse()
+
+	merkleOptions.Pragma, mmrOptions.Pragma = *pragma, *pragma
+	merkleOptions.License, mmrOptions.License = *license, *license
+
+	if err := run(*out, merkleOptions, mmrOptions); err != nil {
+		fmt.Fprintln(os.Stderr, "solgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(out string, merkleOptions, mmrOptions solidity.Options) error {
+	merkleSource, err := solidity.GenerateMerkleVerifier(merkleOptions)
+	if err != nil {
+		return err
+	}
+	mmrSource, err := solidity.GenerateMMRVerifier(mmrOptions)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(out, os.ModePerm); err != nil {
+		return err
+	}
+	if err := writeSource(out, merkleOptions.LibraryName, merkleSource); err != nil {
+		return err
+	}
+	return writeSource(out, mmrOptions.LibraryName, mmrSource)
+}
+
+func writeSource(out, name, source string) error {
+	path := filepath.Join(out, name+".sol")
+	if err := os.WriteFile(path, []byte(source), sourceFileMode); err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
@@ -79,7 +79,10 @@ func main() {
 		panic(err)
 	}
 
-	mmrTree.Commit()
+	err = mmrTree.Commit()
+	if err != nil {
+		panic(err)
+	}
 
 	verifyResult = mmrProof.Verify(mmrRoot)
 
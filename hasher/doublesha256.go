@@ -0,0 +1,15 @@
+package hasher
+
+import (
+	"crypto/sha256"
+)
+
+// DoubleSha256Hasher is hasher type for the double sha256 of Bitcoin, sha256(sha256(b))
+type DoubleSha256Hasher struct{}
+
+// Hash generates double sha256 hash from bytes
+func (hr DoubleSha256Hasher) Hash(b []byte) ([]byte, error) {
+	first := sha256.Sum256(b)
+	second := sha256.Sum256(first[:])
+	return second[:], nil
+}
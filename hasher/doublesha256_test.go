@@ -0,0 +1,16 @@
+package hasher
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestDoubleSha256Hasher(t *testing.T) {
+	hash, err := DoubleSha256Hasher{}.Hash(nil)
+	if err != nil {
+		t.Fatalf("double sha256: %s", err.Error())
+	}
+	if expected := "5df6e0e2761359d30a8275058e299fcc0381534545f55cf43e41983f5d4c9456"; hex.EncodeToString(hash) != expected {
+		t.Errorf("want the double sha256 of the empty data %s got %x", expected, hash)
+	}
+}
@@ -0,0 +1,37 @@
+package hasher
+
+import "github.com/ComposableFi/go-merkle-trees/types"
+
+const (
+	// RFC6962LeafPrefix is the domain separation prefix of the leaf hashes
+	RFC6962LeafPrefix = 0x00
+	// RFC6962NodePrefix is the domain separation prefix of the inner node hashes
+	RFC6962NodePrefix = 0x01
+)
+
+// RFC6962Hasher hashes the nodes of a Certificate Transparency tree as defined in RFC 6962 and RFC 9162. The Hash
+// method hashes the inner nodes, so the hasher can be passed to the merkle tree as is, while the leaves should be
+// hashed with HashLeaf before they are added to the tree.
+type RFC6962Hasher struct {
+	hasher types.Hasher
+}
+
+// NewRFC6962Hasher creates a Certificate Transparency hasher on top of the given hasher, usually Sha256Hasher
+func NewRFC6962Hasher(hasher types.Hasher) RFC6962Hasher {
+	return RFC6962Hasher{hasher: hasher}
+}
+
+// Hash generates the hash of an inner node from the concatenated children hashes
+func (hr RFC6962Hasher) Hash(b []byte) ([]byte, error) {
+	return hr.hasher.Hash(append([]byte{RFC6962NodePrefix}, b...))
+}
+
+// HashLeaf generates the hash of a leaf from the leaf data
+func (hr RFC6962Hasher) HashLeaf(b []byte) ([]byte, error) {
+	return hr.hasher.Hash(append([]byte{RFC6962LeafPrefix}, b...))
+}
+
+// HashEmpty generates the root hash of an empty tree
+func (hr RFC6962Hasher) HashEmpty() ([]byte, error) {
+	return hr.hasher.Hash([]byte{})
+}
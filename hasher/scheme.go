@@ -0,0 +1,105 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+// ErrInvalidPrefixes is returned when the leaf and node prefixes of a PrefixedHasher don't separate the domains, they
+// must not be empty and neither of them can be a prefix of the other one.
+var ErrInvalidPrefixes = errors.New("leaf and node prefixes must not be empty or a prefix of each other")
+
+// LegacyHasher is the hashing scheme the trees of this library have always used: the leaves and the concatenated
+// children of the inner nodes are hashed by the same hash function without any prefixes. An inner node can be
+// passed off as a leaf in this scheme, so it should only be used to keep existing roots valid.
+type LegacyHasher struct {
+	hasher types.Hasher
+}
+
+// NewLegacyHasher creates the legacy hashing scheme of the given hasher
+func NewLegacyHasher(hasher types.Hasher) LegacyHasher {
+	return LegacyHasher{hasher: hasher}
+}
+
+// Hash generates the hash of an inner node from the concatenated children hashes
+func (hr LegacyHasher) Hash(b []byte) ([]byte, error) {
+	return hr.hasher.Hash(b)
+}
+
+// HashLeaf generates the hash of a leaf from the leaf data
+func (hr LegacyHasher) HashLeaf(b []byte) ([]byte, error) {
+	return hr.hasher.Hash(b)
+}
+
+// PrefixedHasher separates the domains of the leaves and the inner nodes by prefixing the hashed data with distinct
+// leaf and node prefixes. RFC6962Hasher is the prefixed scheme of the 0x00 and 0x01 prefixes.
+type PrefixedHasher struct {
+	hasher     types.Hasher
+	leafPrefix []byte
+	nodePrefix []byte
+}
+
+// NewPrefixedHasher creates a domain separated hashing scheme of the given hasher and prefixes. It returns
+// ErrInvalidPrefixes if a prefix is empty or is a prefix of the other one.
+func NewPrefixedHasher(hasher types.Hasher, leafPrefix, nodePrefix []byte) (PrefixedHasher, error) {
+	if bytes.HasPrefix(leafPrefix, nodePrefix) || bytes.HasPrefix(nodePrefix, leafPrefix) {
+		return PrefixedHasher{}, ErrInvalidPrefixes
+	}
+
+	return PrefixedHasher{
+		hasher:     hasher,
+		leafPrefix: append([]byte{}, leafPrefix...),
+		nodePrefix: append([]byte{}, nodePrefix...),
+	}, nil
+}
+
+// Hash generates the hash of an inner node from the concatenated children hashes
+func (hr PrefixedHasher) Hash(b []byte) ([]byte, error) {
+	return hr.hasher.Hash(prefixed(hr.nodePrefix, b))
+}
+
+// HashLeaf generates the hash of a leaf from the leaf data
+func (hr PrefixedHasher) HashLeaf(b []byte) ([]byte, error) {
+	return hr.hasher.Hash(prefixed(hr.leafPrefix, b))
+}
+
+// SeparateLeafHasher separates the domains of the leaves and the inner nodes by hashing them with different hash
+// functions, for example a keyed hash function for the leaves.
+type SeparateLeafHasher struct {
+	leafHasher types.Hasher
+	nodeHasher types.Hasher
+}
+
+// NewSeparateLeafHasher creates a hashing scheme which hashes the leaves with the leaf hasher and the inner nodes
+// with the node hasher
+func NewSeparateLeafHasher(leafHasher, nodeHasher types.Hasher) SeparateLeafHasher {
+	return SeparateLeafHasher{leafHasher: leafHasher, nodeHasher: nodeHasher}
+}
+
+// Hash generates the hash of an inner node from the concatenated children hashes
+func (hr SeparateLeafHasher) Hash(b []byte) ([]byte, error) {
+	return hr.nodeHasher.Hash(b)
+}
+
+// HashLeaf generates the hash of a leaf from the leaf data
+func (hr SeparateLeafHasher) HashLeaf(b []byte) ([]byte, error) {
+	return hr.leafHasher.Hash(b)
+}
+
+// HashLeaf hashes the leaf data in the scheme of the hasher: by its HashLeaf method if it's a types.LeafHasher, or
+// by its Hash method as the legacy scheme does otherwise.
+func HashLeaf(hasher types.Hasher, data []byte) ([]byte, error) {
+	if leafHasher, ok := hasher.(types.LeafHasher); ok {
+		return leafHasher.HashLeaf(data)
+	}
+	return hasher.Hash(data)
+}
+
+// prefixed returns a new slice of the prefix followed by the data
+func prefixed(prefix, data []byte) []byte {
+	b := make([]byte, 0, len(prefix)+len(data))
+	b = append(b, prefix...)
+	return append(b, data...)
+}
@@ -0,0 +1,60 @@
+package hasher
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+func TestPrefixedHasher(t *testing.T) {
+	for _, prefixes := range [][2][]byte{{nil, {1}}, {{1}, {1}}, {{1}, {1, 2}}, {{0, 1}, {0}}} {
+		if _, err := NewPrefixedHasher(Sha256Hasher{}, prefixes[0], prefixes[1]); !errors.Is(err, ErrInvalidPrefixes) {
+			t.Errorf("want ErrInvalidPrefixes for the prefixes %x and %x got %v", prefixes[0], prefixes[1], err)
+		}
+	}
+
+	prefixedHasher, err := NewPrefixedHasher(Sha256Hasher{}, []byte{RFC6962LeafPrefix}, []byte{RFC6962NodePrefix})
+	if err != nil {
+		t.Fatalf("new prefixed hasher: %s", err.Error())
+	}
+	rfc6962Hasher := NewRFC6962Hasher(Sha256Hasher{})
+	data := []byte("leaf")
+
+	leaf, _ := prefixedHasher.HashLeaf(data)
+	expectedLeaf, _ := rfc6962Hasher.HashLeaf(data)
+	node, _ := prefixedHasher.Hash(data)
+	expectedNode, _ := rfc6962Hasher.Hash(data)
+	if !bytes.Equal(leaf, expectedLeaf) || !bytes.Equal(node, expectedNode) {
+		t.Errorf("the prefixed hasher of the 0x00 and 0x01 prefixes doesn't match the RFC 6962 hasher")
+	}
+	if bytes.Equal(leaf, node) {
+		t.Errorf("the leaf and node hashes of the same data must differ")
+	}
+}
+
+func TestHashLeaf(t *testing.T) {
+	data := []byte("leaf")
+	plain, _ := Keccak256Hasher{}.Hash(data)
+
+	for name, hasher := range map[string]types.Hasher{
+		"plain hasher":  Keccak256Hasher{},
+		"legacy hasher": NewLegacyHasher(Keccak256Hasher{}),
+		"separate leaf": NewSeparateLeafHasher(Keccak256Hasher{}, Sha256Hasher{}),
+	} {
+		leaf, err := HashLeaf(hasher, data)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+		if !bytes.Equal(leaf, plain) {
+			t.Errorf("%s: want the leaf hash %x got %x", name, plain, leaf)
+		}
+	}
+
+	node, _ := NewSeparateLeafHasher(Keccak256Hasher{}, Sha256Hasher{}).Hash(data)
+	expectedNode, _ := Sha256Hasher{}.Hash(data)
+	if !bytes.Equal(node, expectedNode) {
+		t.Errorf("the separate leaf hasher must hash the nodes with the node hasher")
+	}
+}
@@ -0,0 +1,218 @@
+// Package cli contains the helpers shared by the command line tools of the repository
+package cli
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// FormatLines reads every non empty line as the raw bytes of a leaf
+	FormatLines = "lines"
+	// FormatHex reads every non empty line as a hex encoded leaf, with or without the 0x prefix
+	FormatHex = "hex"
+	// FormatChunks splits the input into leaves of a fixed size, the last leaf can be shorter
+	FormatChunks = "chunks"
+
+	// maxLineSize is the maximum size of a line of the lines and hex formats
+	maxLineSize = 1 << 20
+	// defaultChunkSize is the default leaf size of FormatChunks
+	defaultChunkSize = 32
+)
+
+var (
+	errUnknownHasher    = errors.New("unknown hasher, use sha256 or keccak256")
+	errUnknownFormat    = errors.New("unknown leaves format, use lines, hex or chunks")
+	errInvalidChunkSize = errors.New("chunk size must be positive")
+	errNoIndices        = errors.New("no indices are given")
+)
+
+// LeafFlags are the flags of the commands that read leaves
+type LeafFlags struct {
+	Hasher     *string
+	Format     *string
+	ChunkSize  *int
+	HashLeaves *bool
+}
+
+// NewLeafFlags defines the leaf flags in the flag set with the given default hasher
+func NewLeafFlags(fs *flag.FlagSet, defaultHasher string) LeafFlags {
+	return LeafFlags{
+		Hasher:     fs.String("hasher", defaultHasher, "the hash function, sha256 or keccak256"),
+		Format:     fs.String("format", FormatLines, "the leaves format: lines, hex or chunks"),
+		ChunkSize:  fs.Int("chunk-size", defaultChunkSize, "the leaf size of the chunks format"),
+		HashLeaves: fs.Bool("hash-leaves", true, "hash the leaves before building the tree"),
+	}
+}
+
+// ReadLeaves returns the hasher of the flags and the leaves of the file of the given path, or of stdin if the path
+// is empty or -. The leaves are hashed unless -hash-leaves=false is given.
+func (f LeafFlags) ReadLeaves(path string, stdin io.Reader) (types.Hasher, [][]byte, error) {
+	h, err := NewHasher(*f.Hasher)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	input, err := OpenInput(path, stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer input.Close()
+
+	leaves, err := ReadLeaves(input, *f.Format, *f.ChunkSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if *f.HashLeaves {
+		if leaves, err = HashLeaves(h, leaves); err != nil {
+			return nil, nil, err
+		}
+	}
+	return h, leaves, nil
+}
+
+// NewHasher returns the hasher of the given name, sha256 or keccak256
+func NewHasher(name string) (types.Hasher, error) {
+	switch name {
+	case "sha256":
+		return hasher.Sha256Hasher{}, nil
+	case "keccak256", "keccak":
+		return hasher.Keccak256Hasher{}, nil
+	}
+	return nil, errUnknownHasher
+}
+
+// HasherName returns the name of the hasher of the given name, so the aliases of a hasher have the same name
+func HasherName(name string) string {
+	if name == "keccak" {
+		return "keccak256"
+	}
+	return name
+}
+
+// OpenInput opens the file of the given path, or returns stdin if the path is empty or -
+func OpenInput(path string, stdin io.Reader) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(path)
+}
+
+// ReadLeaves reads the leaves from the reader in the given format. The chunk size is used only by FormatChunks.
+func ReadLeaves(r io.Reader, format string, chunkSize int) ([][]byte, error) {
+	switch format {
+	case FormatLines, FormatHex:
+		return readLines(r, format == FormatHex)
+	case FormatChunks:
+		return readChunks(r, chunkSize)
+	}
+	return nil, errUnknownFormat
+}
+
+// HashLeaves hashes every leaf with the hasher
+func HashLeaves(h types.Hasher, leaves [][]byte) ([][]byte, error) {
+	hashes := make([][]byte, len(leaves))
+	for i := 0; i < len(leaves); i++ {
+		hash, err := h.Hash(leaves[i])
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
+
+// ParseIndices parses a comma separated list of indices and returns them sorted without duplicates
+func ParseIndices(s string) ([]uint64, error) {
+	var indices []uint64
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		index, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		indices = append(indices, index)
+	}
+	if len(indices) == 0 {
+		return nil, errNoIndices
+	}
+
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	unique := indices[:1]
+	for _, index := range indices[1:] {
+		if index != unique[len(unique)-1] {
+			unique = append(unique, index)
+		}
+	}
+	return unique, nil
+}
+
+// ParseHex decodes a hex string with or without the 0x prefix
+func ParseHex(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	return hex.DecodeString(s)
+}
+
+// EncodeHex encodes the bytes into a 0x prefixed hex string
+func EncodeHex(b []byte) string {
+	return "0x" + hex.EncodeToString(b)
+}
+
+func readLines(r io.Reader, isHex bool) ([][]byte, error) {
+	var leaves [][]byte
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		if !isHex {
+			leaves = append(leaves, []byte(line))
+			continue
+		}
+		leaf, err := ParseHex(line)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leaf)
+	}
+	return leaves, scanner.Err()
+}
+
+func readChunks(r io.Reader, chunkSize int) ([][]byte, error) {
+	if chunkSize <= 0 {
+		return nil, errInvalidChunkSize
+	}
+
+	var leaves [][]byte
+	reader := bufio.NewReader(r)
+	for {
+		chunk := make([]byte, chunkSize)
+		n, err := io.ReadFull(reader, chunk)
+		if n > 0 {
+			leaves = append(leaves, chunk[:n])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return leaves, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
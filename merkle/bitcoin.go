@@ -0,0 +1,467 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// bitcoinHashSize is the size of the transaction ids and the merkle nodes of Bitcoin
+	bitcoinHashSize = 32
+	// bitcoinHeaderSize is the size of a Bitcoin block header
+	bitcoinHeaderSize = 80
+	// bitcoinHeaderRootOffset is the offset of the merkle root in a Bitcoin block header
+	bitcoinHeaderRootOffset = 36
+	// maxBitcoinTransactions is the maximum number of the transactions of a block, the limit of
+	// CPartialMerkleTree::ExtractMatches of Bitcoin Core, MAX_BLOCK_WEIGHT / MIN_TRANSACTION_WEIGHT
+	maxBitcoinTransactions = 4000000 / 240
+	bitsPerByte            = 8
+)
+
+// BitcoinPartialTree is the partial merkle tree of a BIP37 merkleblock message, which proves that the matched
+// transactions are included in a block. The hashes are in the internal byte order and the flags are the traversal
+// bits of BIP37 in the depth-first order.
+type BitcoinPartialTree struct {
+	TransactionsCount uint32
+	Hashes            [][]byte
+	Flags             []bool
+}
+
+// BitcoinMerkleBlock is the block header and the partial merkle tree of a BIP37 merkleblock message
+type BitcoinMerkleBlock struct {
+	Header      []byte
+	PartialTree BitcoinPartialTree
+}
+
+// BitcoinMerkleRoot returns the merkle root of the transaction ids of a block, the ids and the root are in the
+// internal byte order. Unlike the regular tree, the last node of an odd layer is hashed with itself and the nodes are
+// hashed with DoubleSha256Hasher. Duplicating the node lets a list of transactions ending with the repeated
+// transactions have the same root as the list without them (CVE-2012-2459), mutated is true when two siblings are
+// equal, and such a list must be rejected as an invalid block.
+func BitcoinMerkleRoot(txids [][]byte) (root []byte, mutated bool, err error) {
+	if len(txids) == 0 {
+		return nil, false, errEmptyTree
+	}
+	if err := checkBitcoinHashes(txids); err != nil {
+		return nil, false, err
+	}
+
+	layer := txids
+	for len(layer) > 1 {
+		parents := make([][]byte, (len(layer)+1)/halfDivider)
+		for i := range parents {
+			left, right := layer[getLeftIndex(i)], layer[getLeftIndex(i)]
+			if getRightIndex(i) < len(layer) {
+				right = layer[getRightIndex(i)]
+				mutated = mutated || bytes.Equal(left, right)
+			}
+			if parents[i], err = hashBitcoinPair(left, right); err != nil {
+				return nil, false, err
+			}
+		}
+		layer = parents
+	}
+
+	return layer[0], mutated, nil
+}
+
+// ParseTxid parses the hex encoded transaction id as displayed by the block explorers and the RPC of Bitcoin Core,
+// and returns it in the internal byte order, which is reversed
+func ParseTxid(s string) ([]byte, error) {
+	txid, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(txid) != bitcoinHashSize {
+		return nil, errInvalidHashSize
+	}
+	return reverseBytes(txid), nil
+}
+
+// FormatTxid returns the hex encoded transaction id or block hash of the internal byte order in the displayed order
+func FormatTxid(txid []byte) string {
+	return hex.EncodeToString(reverseBytes(txid))
+}
+
+// NewBitcoinPartialTree builds the BIP37 partial merkle tree of the transaction ids of a block that proves the
+// transactions whose matches are true, like the CPartialMerkleTree constructor of Bitcoin Core
+func NewBitcoinPartialTree(txids [][]byte, matches []bool) (BitcoinPartialTree, error) {
+	if len(txids) == 0 {
+		return BitcoinPartialTree{}, errEmptyTree
+	}
+	if len(txids) != len(matches) || len(txids) > maxBitcoinTransactions {
+		return BitcoinPartialTree{}, errInvalidProofSize
+	}
+	if err := checkBitcoinHashes(txids); err != nil {
+		return BitcoinPartialTree{}, err
+	}
+
+	b := bitcoinTreeBuilder{
+		txids:   txids,
+		matches: matches,
+		tree:    BitcoinPartialTree{TransactionsCount: uint32(len(txids)), Hashes: [][]byte{}},
+	}
+	if err := b.traverse(b.tree.height(), 0); err != nil {
+		return BitcoinPartialTree{}, err
+	}
+	return b.tree, nil
+}
+
+// ExtractMatches verifies the structure of the partial tree and returns its merkle root and the matched transaction
+// ids with their indices in the block, like CPartialMerkleTree::ExtractMatches of Bitcoin Core. The root must be
+// compared with the merkle root of the block header. It fails on a tree with two equal siblings, which is the
+// CVE-2012-2459 mutation of the block, and when some hashes or flags are not used.
+func (p BitcoinPartialTree) ExtractMatches() ([]byte, Leaves, error) {
+	if p.TransactionsCount == 0 {
+		return nil, nil, errEmptyTree
+	}
+	if p.TransactionsCount > maxBitcoinTransactions || len(p.Hashes) > int(p.TransactionsCount) ||
+		len(p.Flags) < len(p.Hashes) {
+		return nil, nil, errInvalidProofSize
+	}
+	if err := checkBitcoinHashes(p.Hashes); err != nil {
+		return nil, nil, err
+	}
+
+	e := bitcoinTreeExtractor{tree: p, matches: Leaves{}}
+	root, err := e.traverse(p.height(), 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// all of the hashes and all of the flags but the padding of the last byte must be used
+	if (e.flagsUsed+bitsPerByte-1)/bitsPerByte != (len(p.Flags)+bitsPerByte-1)/bitsPerByte ||
+		e.hashesUsed != len(p.Hashes) {
+		return nil, nil, errInvalidProofSize
+	}
+	return root, e.matches, nil
+}
+
+// MarshalBinary encodes the partial tree in the format of the merkleblock message of BIP37, the little endian
+// transactions count, the compact size prefixed hashes and the compact size prefixed flag bytes. The flags are
+// packed into the bytes from the least significant bit.
+func (p BitcoinPartialTree) MarshalBinary() ([]byte, error) {
+	data := make([]byte, countSize, countSize+len(p.Hashes)*bitcoinHashSize)
+	binary.LittleEndian.PutUint32(data, p.TransactionsCount)
+
+	data = appendCompactSize(data, uint64(len(p.Hashes)))
+	for _, hash := range p.Hashes {
+		if len(hash) != bitcoinHashSize {
+			return nil, errInvalidHashSize
+		}
+		data = append(data, hash...)
+	}
+
+	flags := make([]byte, (len(p.Flags)+bitsPerByte-1)/bitsPerByte)
+	for i, flag := range p.Flags {
+		if flag {
+			flags[i/bitsPerByte] |= 1 << (i % bitsPerByte)
+		}
+	}
+	data = appendCompactSize(data, uint64(len(flags)))
+	return append(data, flags...), nil
+}
+
+// UnmarshalBinary decodes the partial tree from the format of MarshalBinary. All of the bits of the flag bytes are
+// decoded, so the flags include the padding of the last byte.
+func (p *BitcoinPartialTree) UnmarshalBinary(data []byte) error {
+	d := decoder{data: data}
+	transactionsCount, err := d.next(countSize)
+	if err != nil {
+		return err
+	}
+
+	hashesCount, err := d.compactSizeCount(bitcoinHashSize)
+	if err != nil {
+		return err
+	}
+	hashes := make([][]byte, hashesCount)
+	for i := range hashes {
+		hash, err := d.next(bitcoinHashSize)
+		if err != nil {
+			return err
+		}
+		hashes[i] = copyBytes(hash)
+	}
+
+	flagsCount, err := d.compactSizeCount(1)
+	if err != nil {
+		return err
+	}
+	flagBytes, err := d.next(flagsCount)
+	if err != nil {
+		return err
+	}
+	if len(d.data) != 0 {
+		return errInvalidProofEncoding
+	}
+	flags := make([]bool, flagsCount*bitsPerByte)
+	for i := range flags {
+		flags[i] = flagBytes[i/bitsPerByte]&(1<<(i%bitsPerByte)) != 0
+	}
+
+	*p = BitcoinPartialTree{
+		TransactionsCount: binary.LittleEndian.Uint32(transactionsCount),
+		Hashes:            hashes,
+		Flags:             flags,
+	}
+	return nil
+}
+
+// MarshalBinary encodes the merkleblock message of BIP37, the block header followed by the partial tree
+func (b BitcoinMerkleBlock) MarshalBinary() ([]byte, error) {
+	if len(b.Header) != bitcoinHeaderSize {
+		return nil, errInvalidProofEncoding
+	}
+	tree, err := b.PartialTree.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(copyBytes(b.Header), tree...), nil
+}
+
+// UnmarshalBinary decodes the merkleblock message of BIP37
+func (b *BitcoinMerkleBlock) UnmarshalBinary(data []byte) error {
+	if len(data) < bitcoinHeaderSize {
+		return errInvalidProofEncoding
+	}
+	var tree BitcoinPartialTree
+	if err := tree.UnmarshalBinary(data[bitcoinHeaderSize:]); err != nil {
+		return err
+	}
+	*b = BitcoinMerkleBlock{Header: copyBytes(data[:bitcoinHeaderSize]), PartialTree: tree}
+	return nil
+}
+
+// MerkleRoot returns the merkle root of the block header in the internal byte order
+func (b BitcoinMerkleBlock) MerkleRoot() []byte {
+	if len(b.Header) != bitcoinHeaderSize {
+		return nil
+	}
+	return b.Header[bitcoinHeaderRootOffset : bitcoinHeaderRootOffset+bitcoinHashSize]
+}
+
+// BlockHash returns the hash of the block header in the internal byte order
+func (b BitcoinMerkleBlock) BlockHash() ([]byte, error) {
+	if len(b.Header) != bitcoinHeaderSize {
+		return nil, errInvalidProofEncoding
+	}
+	return hasher.DoubleSha256Hasher{}.Hash(b.Header)
+}
+
+// Verify extracts the matches of the partial tree and checks that its root is the merkle root of the block header, it
+// returns the matched transaction ids with their indices in the block. The header itself, its proof of work and its
+// chain must be verified separately.
+func (b BitcoinMerkleBlock) Verify() (Leaves, error) {
+	if len(b.Header) != bitcoinHeaderSize {
+		return nil, errInvalidProofEncoding
+	}
+	root, matches, err := b.PartialTree.ExtractMatches()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(root, b.MerkleRoot()) {
+		return nil, errRootMismatch
+	}
+	return matches, nil
+}
+
+// height returns the height of the tree of the transactions
+func (p BitcoinPartialTree) height() uint {
+	var height uint
+	for p.width(height) > 1 {
+		height++
+	}
+	return height
+}
+
+// width returns the number of the nodes of the layer at the height, the leaves are at the height zero
+func (p BitcoinPartialTree) width(height uint) uint64 {
+	return (uint64(p.TransactionsCount) + (1 << height) - 1) >> height
+}
+
+// bitcoinTreeBuilder builds the hashes and the flags of a partial tree
+type bitcoinTreeBuilder struct {
+	txids   [][]byte
+	matches []bool
+	tree    BitcoinPartialTree
+}
+
+// traverse adds the flags and the hashes of the node at the height and the position in the depth-first order, the
+// TraverseAndBuild function of Bitcoin Core
+func (b *bitcoinTreeBuilder) traverse(height uint, pos uint64) error {
+	parentOfMatch := false
+	for i := pos << height; i < (pos+1)<<height && i < uint64(len(b.txids)); i++ {
+		parentOfMatch = parentOfMatch || b.matches[i]
+	}
+	b.tree.Flags = append(b.tree.Flags, parentOfMatch)
+
+	if height == 0 || !parentOfMatch {
+		hash, err := b.hash(height, pos)
+		if err != nil {
+			return err
+		}
+		b.tree.Hashes = append(b.tree.Hashes, hash)
+		return nil
+	}
+
+	if err := b.traverse(height-1, pos*halfDivider); err != nil {
+		return err
+	}
+	if pos*halfDivider+1 < b.tree.width(height-1) {
+		return b.traverse(height-1, pos*halfDivider+1)
+	}
+	return nil
+}
+
+// hash returns the hash of the node at the height and the position
+func (b *bitcoinTreeBuilder) hash(height uint, pos uint64) ([]byte, error) {
+	if height == 0 {
+		return b.txids[pos], nil
+	}
+
+	left, err := b.hash(height-1, pos*halfDivider)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*halfDivider+1 < b.tree.width(height-1) {
+		if right, err = b.hash(height-1, pos*halfDivider+1); err != nil {
+			return nil, err
+		}
+	}
+	return hashBitcoinPair(left, right)
+}
+
+// bitcoinTreeExtractor computes the root of a partial tree and collects its matches
+type bitcoinTreeExtractor struct {
+	tree       BitcoinPartialTree
+	flagsUsed  int
+	hashesUsed int
+	matches    Leaves
+}
+
+// traverse returns the hash of the node at the height and the position, consuming the flags and the hashes in the
+// depth-first order, the TraverseAndExtract function of Bitcoin Core
+func (e *bitcoinTreeExtractor) traverse(height uint, pos uint64) ([]byte, error) {
+	if e.flagsUsed >= len(e.tree.Flags) {
+		return nil, errInvalidProofSize
+	}
+	parentOfMatch := e.tree.Flags[e.flagsUsed]
+	e.flagsUsed++
+
+	if height == 0 || !parentOfMatch {
+		if e.hashesUsed >= len(e.tree.Hashes) {
+			return nil, errInvalidProofSize
+		}
+		hash := e.tree.Hashes[e.hashesUsed]
+		e.hashesUsed++
+		if height == 0 && parentOfMatch {
+			e.matches = append(e.matches, types.Leaf{Index: pos, Hash: hash})
+		}
+		return hash, nil
+	}
+
+	left, err := e.traverse(height-1, pos*halfDivider)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*halfDivider+1 < e.tree.width(height-1) {
+		if right, err = e.traverse(height-1, pos*halfDivider+1); err != nil {
+			return nil, err
+		}
+		// the right node can only be equal to the left one when it's the duplicated last node
+		if bytes.Equal(left, right) {
+			return nil, errDuplicateSubtree
+		}
+	}
+	return hashBitcoinPair(left, right)
+}
+
+// hashBitcoinPair hashes the concatenation of the nodes with DoubleSha256Hasher
+func hashBitcoinPair(left, right []byte) ([]byte, error) {
+	pair := make([]byte, 0, len(left)+len(right))
+	return hasher.DoubleSha256Hasher{}.Hash(append(append(pair, left...), right...))
+}
+
+// checkBitcoinHashes checks that every hash has the size of the Bitcoin hashes
+func checkBitcoinHashes(hashes [][]byte) error {
+	for _, hash := range hashes {
+		if len(hash) != bitcoinHashSize {
+			return errInvalidHashSize
+		}
+	}
+	return nil
+}
+
+// reverseBytes returns a reversed copy of the bytes
+func reverseBytes(b []byte) []byte {
+	reversed := make([]byte, len(b))
+	for i := range b {
+		reversed[len(b)-1-i] = b[i]
+	}
+	return reversed
+}
+
+// appendCompactSize appends the Bitcoin variable length integer to the data
+func appendCompactSize(data []byte, n uint64) []byte {
+	switch {
+	case n < 0xfd:
+		return append(data, byte(n))
+	case n <= 0xffff:
+		b := make([]byte, 2)
+		binary.LittleEndian.PutUint16(b, uint16(n))
+		return append(append(data, 0xfd), b...)
+	case n <= 0xffffffff:
+		b := make([]byte, countSize)
+		binary.LittleEndian.PutUint32(b, uint32(n))
+		return append(append(data, 0xfe), b...)
+	default:
+		b := make([]byte, indexSize)
+		binary.LittleEndian.PutUint64(b, n)
+		return append(append(data, 0xff), b...)
+	}
+}
+
+// compactSizeCount reads a Bitcoin variable length integer, the number of the items that follow, it fails if the
+// integer is not canonical or the remaining data can't hold that many items of the given size
+func (d *decoder) compactSizeCount(itemSize int) (int, error) {
+	prefix, err := d.byte()
+	if err != nil {
+		return 0, err
+	}
+
+	var n, min uint64
+	switch prefix {
+	case 0xfd:
+		b, err := d.next(2)
+		if err != nil {
+			return 0, err
+		}
+		n, min = uint64(binary.LittleEndian.Uint16(b)), 0xfd
+	case 0xfe:
+		b, err := d.next(countSize)
+		if err != nil {
+			return 0, err
+		}
+		n, min = uint64(binary.LittleEndian.Uint32(b)), 0x10000
+	case 0xff:
+		b, err := d.next(indexSize)
+		if err != nil {
+			return 0, err
+		}
+		n, min = binary.LittleEndian.Uint64(b), 0x100000000
+	default:
+		n = uint64(prefix)
+	}
+
+	if n < min || n > uint64(len(d.data)/itemSize) {
+		return 0, errInvalidProofEncoding
+	}
+	return int(n), nil
+}
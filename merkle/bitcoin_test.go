@@ -0,0 +1,178 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/stretchr/testify/require"
+)
+
+// bitcoinBlock100000 is the header and the transaction ids of the Bitcoin block 100000
+var bitcoinBlock100000 = struct {
+	hash   string
+	header string
+	root   string
+	txids  []string
+}{
+	hash: "000000000003ba27aa200b1cecaad478d2b00432346c3f1f3986da1afd33e506",
+	header: "0100000050120119172a610421a6c3011dd330d9df07b63616c2cc1f1cd00200000000006657a9252aacd5c0b2940996ecff952228" +
+		"c3067cc38d4885efb5a4ac4247e9f337221b4d4c86041b0f2b5710",
+	root: "f3e94742aca4b5ef85488dc37c06c3282295ffec960994b2c0d5ac2a25a95766",
+	txids: []string{
+		"8c14f0db3df150123e6f3dbbf30f8b955a8249b62ac1d1ff16284aefa3d06d87",
+		"fff2525b8931402dd09222c50775608f75787bd2b87e56995a7bdd30f79702c4",
+		"6359f0868171b1d194cbee1af2f16ea598ae8fad666d9b012c8ed2b79a236ec4",
+		"e9a66845e05d5abc0ad04ec80f774a7e585c6e8db975962d069a522137b80c1d",
+	},
+}
+
+func bitcoinTxids(t *testing.T) [][]byte {
+	txids := make([][]byte, len(bitcoinBlock100000.txids))
+	for i, s := range bitcoinBlock100000.txids {
+		txid, err := ParseTxid(s)
+		require.NoError(t, err)
+		txids[i] = txid
+	}
+	return txids
+}
+
+func TestBitcoinMerkleRoot(t *testing.T) {
+	txids := bitcoinTxids(t)
+	root, mutated, err := BitcoinMerkleRoot(txids)
+	require.NoError(t, err)
+	require.False(t, mutated)
+	require.Equal(t, bitcoinBlock100000.root, FormatTxid(root))
+
+	// the first three transactions, the last one is hashed with itself
+	root, mutated, err = BitcoinMerkleRoot(txids[:3])
+	require.NoError(t, err)
+	require.False(t, mutated)
+
+	// CVE-2012-2459, repeating the last transaction doesn't change the root but the block is mutated
+	mutatedRoot, mutated, err := BitcoinMerkleRoot(append(txids[:3:3], txids[2]))
+	require.NoError(t, err)
+	require.True(t, mutated)
+	require.Equal(t, root, mutatedRoot)
+
+	// a single transaction is the root
+	root, _, err = BitcoinMerkleRoot(txids[:1])
+	require.NoError(t, err)
+	require.Equal(t, txids[0], root)
+
+	_, _, err = BitcoinMerkleRoot(nil)
+	require.ErrorIs(t, err, errEmptyTree)
+	_, _, err = BitcoinMerkleRoot([][]byte{txids[0][1:]})
+	require.ErrorIs(t, err, errInvalidHashSize)
+	_, err = ParseTxid(bitcoinBlock100000.root[2:])
+	require.ErrorIs(t, err, errInvalidHashSize)
+}
+
+func TestBitcoinMerkleBlock(t *testing.T) {
+	txids := bitcoinTxids(t)
+	header, err := hex.DecodeString(bitcoinBlock100000.header)
+	require.NoError(t, err)
+
+	// the proof of the second transaction, the flags are 1, 1, 0, 1, 0 and the hashes are the first two
+	// transactions and the parent of the last two
+	tree, err := NewBitcoinPartialTree(txids, []bool{false, true, false, false})
+	require.NoError(t, err)
+	parent, err := hashBitcoinPair(txids[2], txids[3])
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, true, false, true, false}, tree.Flags)
+	require.Equal(t, [][]byte{txids[0], txids[1], parent}, tree.Hashes)
+
+	block := BitcoinMerkleBlock{Header: header, PartialTree: tree}
+	hash, err := block.BlockHash()
+	require.NoError(t, err)
+	require.Equal(t, bitcoinBlock100000.hash, FormatTxid(hash))
+
+	data, err := block.MarshalBinary()
+	require.NoError(t, err)
+	expected := bitcoinBlock100000.header + "04000000" + "03" + hex.EncodeToString(txids[0]) +
+		hex.EncodeToString(txids[1]) + hex.EncodeToString(parent) + "01" + "0b"
+	require.Equal(t, expected, hex.EncodeToString(data))
+
+	var decoded BitcoinMerkleBlock
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	matches, err := decoded.Verify()
+	require.NoError(t, err)
+	require.Equal(t, Leaves{{Index: 1, Hash: txids[1]}}, matches)
+
+	// a different header
+	decoded.Header[bitcoinHeaderRootOffset]++
+	_, err = decoded.Verify()
+	require.ErrorIs(t, err, errRootMismatch)
+
+	require.ErrorIs(t, decoded.UnmarshalBinary(data[:len(data)-1]), errInvalidProofEncoding)
+	require.ErrorIs(t, decoded.UnmarshalBinary(append(data, 0)), errInvalidProofEncoding)
+	require.ErrorIs(t, decoded.UnmarshalBinary(data[:bitcoinHeaderSize-1]), errInvalidProofEncoding)
+}
+
+func TestBitcoinPartialTree(t *testing.T) {
+	for size := 1; size <= 13; size++ {
+		txids := make([][]byte, size)
+		for i := range txids {
+			txids[i] = make([]byte, bitcoinHashSize)
+			txids[i][0], txids[i][1] = byte(i), byte(size)
+		}
+		root, _, err := BitcoinMerkleRoot(txids)
+		require.NoError(t, err)
+
+		for subset := 0; subset < 1<<size; subset += size {
+			matches := make([]bool, size)
+			expected := Leaves{}
+			for i := range matches {
+				if subset&(1<<i) != 0 {
+					matches[i] = true
+					expected = append(expected, types.Leaf{Index: uint64(i), Hash: txids[i]})
+				}
+			}
+
+			tree, err := NewBitcoinPartialTree(txids, matches)
+			require.NoError(t, err)
+			data, err := tree.MarshalBinary()
+			require.NoError(t, err)
+			var decoded BitcoinPartialTree
+			require.NoError(t, decoded.UnmarshalBinary(data))
+
+			extractedRoot, extracted, err := decoded.ExtractMatches()
+			require.NoError(t, err)
+			require.Equal(t, root, extractedRoot, "size %d subset %b", size, subset)
+			require.Equal(t, expected, extracted, "size %d subset %b", size, subset)
+
+			// an unused hash
+			invalid := decoded
+			invalid.Hashes = append(invalid.Hashes[:len(invalid.Hashes):len(invalid.Hashes)], txids[0])
+			invalid.Flags = append(invalid.Flags, make([]bool, bitsPerByte)...)
+			_, _, err = invalid.ExtractMatches()
+			require.ErrorIs(t, err, errInvalidProofSize)
+		}
+	}
+}
+
+func TestBitcoinPartialTreeDuplicateSubtree(t *testing.T) {
+	txids := bitcoinTxids(t)
+
+	// the mutated block of CVE-2012-2459 can't prove its repeated transaction
+	mutatedTxids := append(txids[:3:3], txids[2])
+	tree, err := NewBitcoinPartialTree(mutatedTxids, []bool{false, false, false, true})
+	require.NoError(t, err)
+	_, _, err = tree.ExtractMatches()
+	require.ErrorIs(t, err, errDuplicateSubtree)
+
+	// the valid block of three transactions proves the same one
+	tree, err = NewBitcoinPartialTree(txids[:3], []bool{false, false, true})
+	require.NoError(t, err)
+	_, matches, err := tree.ExtractMatches()
+	require.NoError(t, err)
+	require.Equal(t, Leaves{{Index: 2, Hash: txids[2]}}, matches)
+
+	_, err = NewBitcoinPartialTree(txids, []bool{true})
+	require.ErrorIs(t, err, errInvalidProofSize)
+	_, _, err = BitcoinPartialTree{}.ExtractMatches()
+	require.ErrorIs(t, err, errEmptyTree)
+	_, _, err = BitcoinPartialTree{TransactionsCount: 1, Hashes: [][]byte{txids[0], txids[1]}, Flags: []bool{true, true}}.
+		ExtractMatches()
+	require.ErrorIs(t, err, errInvalidProofSize)
+}
@@ -0,0 +1,246 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+)
+
+const (
+	// cometBFTHashSize is the size of the sha256 hashes of the CometBFT trees
+	cometBFTHashSize = 32
+	// cometBFTMaxAunts is the maximum number of the aunts of a CometBFT proof, MaxAunts of CometBFT
+	cometBFTMaxAunts = 100
+
+	// the protobuf keys of the fields of tendermint.crypto.Proof, the field number shifted by three with the wire type
+	protoTotalKey    = 1<<3 | protoVarint
+	protoIndexKey    = 2<<3 | protoVarint
+	protoLeafHashKey = 3<<3 | protoBytes
+	protoAuntsKey    = 4<<3 | protoBytes
+
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// CometBFTProof is the merkle proof of CometBFT (Tendermint), the Proof type of its crypto/merkle package. The aunts
+// are the sibling hashes from the bottom to the top of the tree, the RFC 6962 audit path of the leaf.
+type CometBFTProof struct {
+	Total    int64
+	Index    int64
+	LeafHash []byte
+	Aunts    [][]byte
+}
+
+// cometBFTProofJSON is the JSON format of the CometBFT proofs, where the integers are strings and the hashes base64
+type cometBFTProofJSON struct {
+	Total    int64    `json:"total,string"`
+	Index    int64    `json:"index,string"`
+	LeafHash []byte   `json:"leaf_hash"`
+	Aunts    [][]byte `json:"aunts,omitempty"`
+}
+
+// NewCometBFTTree creates a merkle tree with the roots of HashFromByteSlices of CometBFT, which is the RFC 6962 tree
+// of sha256. Add the leaf data with FromData or AppendData, so the leaves are hashed with the 0x00 prefix.
+func NewCometBFTTree() Tree {
+	return NewRFC6962Tree(hasher.Sha256Hasher{})
+}
+
+// CometBFTRoot returns the root of the items like HashFromByteSlices of CometBFT, the root of no items is the sha256
+// of the empty data
+func CometBFTRoot(items [][]byte) ([]byte, error) {
+	if len(items) == 0 {
+		return hasher.NewRFC6962Hasher(hasher.Sha256Hasher{}).HashEmpty()
+	}
+
+	tree, err := NewCometBFTTree().FromData(items)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root(), nil
+}
+
+// CometBFTProof returns the CometBFT proof of the leaf at the given index, the tree must be created by NewCometBFTTree
+func (t *Tree) CometBFTProof(index uint64) (CometBFTProof, error) {
+	aunts, err := t.InclusionProof(index)
+	if err != nil {
+		return CometBFTProof{}, err
+	}
+	leafHash, _ := t.node(0, index)
+
+	return CometBFTProof{
+		Total:    int64(t.leavesLen()),
+		Index:    int64(index),
+		LeafHash: leafHash,
+		Aunts:    aunts,
+	}, nil
+}
+
+// Verify verifies that the proof is the proof of the leaf data in the tree of the root, like the Verify method of the
+// CometBFT proofs. It fails if the proof is malformed, as ValidateBasic of CometBFT does.
+func (p CometBFTProof) Verify(root, leaf []byte) (bool, error) {
+	if err := p.validate(); err != nil {
+		return false, err
+	}
+
+	h := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{})
+	leafHash, err := h.HashLeaf(leaf)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(leafHash, p.LeafHash) {
+		return false, nil
+	}
+
+	return VerifyInclusion(h, uint64(p.Index), uint64(p.Total), p.LeafHash, p.Aunts, root)
+}
+
+// MarshalJSON encodes the proof in the JSON format of CometBFT
+func (p CometBFTProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cometBFTProofJSON(p))
+}
+
+// UnmarshalJSON decodes the proof from the JSON format of CometBFT
+func (p *CometBFTProof) UnmarshalJSON(data []byte) error {
+	var proof cometBFTProofJSON
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return err
+	}
+	*p = CometBFTProof(proof)
+	return nil
+}
+
+// MarshalBinary encodes the proof into the protobuf format of the tendermint.crypto.Proof message
+func (p CometBFTProof) MarshalBinary() ([]byte, error) {
+	var data []byte
+	if p.Total != 0 {
+		data = appendProtoVarint(append(data, protoTotalKey), uint64(p.Total))
+	}
+	if p.Index != 0 {
+		data = appendProtoVarint(append(data, protoIndexKey), uint64(p.Index))
+	}
+	if len(p.LeafHash) != 0 {
+		data = appendProtoBytes(append(data, protoLeafHashKey), p.LeafHash)
+	}
+	for _, aunt := range p.Aunts {
+		data = appendProtoBytes(append(data, protoAuntsKey), aunt)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes the proof from the protobuf format of the tendermint.crypto.Proof message, the unknown
+// fields are skipped
+func (p *CometBFTProof) UnmarshalBinary(data []byte) error {
+	var proof CometBFTProof
+	d := decoder{data: data}
+	for len(d.data) > 0 {
+		key, err := d.protoVarint()
+		if err != nil {
+			return err
+		}
+
+		switch key {
+		case protoTotalKey, protoIndexKey:
+			n, err := d.protoVarint()
+			if err != nil {
+				return err
+			}
+			if key == protoTotalKey {
+				proof.Total = int64(n)
+			} else {
+				proof.Index = int64(n)
+			}
+		case protoLeafHashKey, protoAuntsKey:
+			b, err := d.protoBytes()
+			if err != nil {
+				return err
+			}
+			if key == protoLeafHashKey {
+				proof.LeafHash = b
+			} else {
+				proof.Aunts = append(proof.Aunts, b)
+			}
+		default:
+			if err := d.skipProtoField(key); err != nil {
+				return err
+			}
+		}
+	}
+
+	*p = proof
+	return nil
+}
+
+// validate checks the sizes of the proof like ValidateBasic of CometBFT
+func (p CometBFTProof) validate() error {
+	if p.Total < 0 || p.Index < 0 || p.Index >= p.Total {
+		return errLeafIndexOutOfRange
+	}
+	if len(p.LeafHash) != cometBFTHashSize || len(p.Aunts) > cometBFTMaxAunts {
+		return errInvalidProofSize
+	}
+	for _, aunt := range p.Aunts {
+		if len(aunt) != cometBFTHashSize {
+			return errInvalidHashSize
+		}
+	}
+	return nil
+}
+
+// appendProtoVarint appends the protobuf varint to the data
+func appendProtoVarint(data []byte, n uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return append(data, b[:binary.PutUvarint(b, n)]...)
+}
+
+// appendProtoBytes appends the length prefixed bytes to the data
+func appendProtoBytes(data, b []byte) []byte {
+	return append(appendProtoVarint(data, uint64(len(b))), b...)
+}
+
+// protoVarint reads a protobuf varint
+func (d *decoder) protoVarint() (uint64, error) {
+	n, size := binary.Uvarint(d.data)
+	if size <= 0 {
+		return 0, errInvalidProofEncoding
+	}
+	d.data = d.data[size:]
+	return n, nil
+}
+
+// protoBytes reads a copy of the length prefixed bytes
+func (d *decoder) protoBytes() ([]byte, error) {
+	size, err := d.protoVarint()
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(d.data)) {
+		return nil, errInvalidProofEncoding
+	}
+	b, err := d.next(int(size))
+	if err != nil {
+		return nil, err
+	}
+	return copyBytes(b), nil
+}
+
+// skipProtoField skips the value of an unknown protobuf field
+func (d *decoder) skipProtoField(key uint64) error {
+	var err error
+	switch key & 0x7 {
+	case protoVarint:
+		_, err = d.protoVarint()
+	case protoFixed64:
+		_, err = d.next(indexSize)
+	case protoBytes:
+		_, err = d.protoBytes()
+	case protoFixed32:
+		_, err = d.next(countSize)
+	default:
+		err = errInvalidProofEncoding
+	}
+	return err
+}
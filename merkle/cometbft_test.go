@@ -0,0 +1,112 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCometBFTRoot(t *testing.T) {
+	// the test vectors of HashFromByteSlices of CometBFT
+	cases := []struct {
+		name  string
+		items [][]byte
+		root  string
+	}{
+		{"nil", nil, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"empty", [][]byte{}, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"single", [][]byte{{1, 2, 3}}, "054edec1d0211f624fed0cbca9d4f9400b0e491c43742af2c5b0abebf0c990d8"},
+		{"single blank", [][]byte{{}}, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"},
+		{"two", [][]byte{{1, 2, 3}, {4, 5, 6}}, "82e6cfce00453804379b53962939eaa7906b39904be0813fcadd31b100773c4b"},
+		{"many", [][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}},
+			"f326493eceab4f2d9ffbc78c59432a0a005d6ea98392045c74df5d14a113be18"},
+	}
+
+	for _, c := range cases {
+		root, err := CometBFTRoot(c.items)
+		require.NoError(t, err, c.name)
+		require.Equal(t, c.root, hex.EncodeToString(root), c.name)
+	}
+}
+
+func TestCometBFTProof(t *testing.T) {
+	items := [][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}}
+	tree, err := NewCometBFTTree().FromData(items)
+	require.NoError(t, err)
+
+	for i := range items {
+		proof, err := tree.CometBFTProof(uint64(i))
+		require.NoError(t, err)
+		require.Equal(t, int64(len(items)), proof.Total)
+		require.Equal(t, int64(i), proof.Index)
+
+		verified, err := proof.Verify(tree.Root(), items[i])
+		require.NoError(t, err)
+		require.True(t, verified, "leaf %d", i)
+
+		verified, err = proof.Verify(tree.Root(), items[(i+1)%len(items)])
+		require.NoError(t, err)
+		require.False(t, verified, "leaf %d", i)
+
+		data, err := proof.MarshalBinary()
+		require.NoError(t, err)
+		var decoded CometBFTProof
+		require.NoError(t, decoded.UnmarshalBinary(data))
+		require.Equal(t, proof, decoded)
+
+		data, err = json.Marshal(proof)
+		require.NoError(t, err)
+		decoded = CometBFTProof{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.Equal(t, proof, decoded)
+	}
+
+	_, err = tree.CometBFTProof(uint64(len(items)))
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+}
+
+func TestCometBFTProofEncoding(t *testing.T) {
+	hash := func(b byte) []byte {
+		h := make([]byte, cometBFTHashSize)
+		h[0] = b
+		return h
+	}
+	proof := CometBFTProof{Total: 300, Index: 2, LeafHash: hash(1), Aunts: [][]byte{hash(2), hash(3)}}
+
+	// the protobuf encoding of tendermint.crypto.Proof, the total is the two bytes varint of 300
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	expected := "08ac02" + "1002" + "1a20" + hex.EncodeToString(hash(1)) + "2220" + hex.EncodeToString(hash(2)) +
+		"2220" + hex.EncodeToString(hash(3))
+	require.Equal(t, expected, hex.EncodeToString(data))
+
+	// the unknown fields are skipped
+	var decoded CometBFTProof
+	require.NoError(t, decoded.UnmarshalBinary(append(data, 0x28, 0x01, 0x32, 0x01, 0xff)))
+	require.Equal(t, proof, decoded)
+	require.ErrorIs(t, decoded.UnmarshalBinary(data[:len(data)-1]), errInvalidProofEncoding)
+	require.ErrorIs(t, decoded.UnmarshalBinary([]byte{0x08}), errInvalidProofEncoding)
+
+	// the JSON format of the CometBFT RPC, with the integers as strings and the hashes in base64
+	data, err = json.Marshal(CometBFTProof{Total: 2, Index: 1, LeafHash: []byte{1}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"total":"2","index":"1","leaf_hash":"AQ=="}`, string(data))
+
+	// malformed proofs are rejected before they are verified
+	root := hash(0)
+	for _, invalid := range []struct {
+		proof CometBFTProof
+		err   error
+	}{
+		{CometBFTProof{Total: 2, Index: 2, LeafHash: hash(1)}, errLeafIndexOutOfRange},
+		{CometBFTProof{Total: -1, Index: 0, LeafHash: hash(1)}, errLeafIndexOutOfRange},
+		{CometBFTProof{Total: 2, Index: 1, LeafHash: []byte{1}}, errInvalidProofSize},
+		{CometBFTProof{Total: 2, Index: 1, LeafHash: hash(1), Aunts: [][]byte{{1}}}, errInvalidHashSize},
+		{CometBFTProof{Total: 2, Index: 1, LeafHash: hash(1), Aunts: make([][]byte, cometBFTMaxAunts+1)}, errInvalidProofSize},
+	} {
+		_, err := invalid.proof.Verify(root, []byte{1})
+		require.ErrorIs(t, err, invalid.err)
+	}
+}
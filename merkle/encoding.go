@@ -0,0 +1,283 @@
+package merkle
+
+import (
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// proofEncodingVersion is the version of the binary proof format
+	proofEncodingVersion = 1
+
+	versionSize = 1
+	countSize   = 4
+	indexSize   = 8
+)
+
+// MarshalBinary encodes the proof into the binary format. All of the integers are big endian and every hash is
+// prefixed with its length, so hashes of any size can be encoded:
+//
+//	version          uint8
+//	totalLeavesCount uint64
+//	leavesCount      uint32
+//	leaves           leavesCount * (index uint64, hashLength uint32, hash)
+//	proofHashesCount uint32
+//	proofHashes      proofHashesCount * (hashLength uint32, hash)
+//
+// The leaves are encoded sorted by their indices.
+func (p Proof) MarshalBinary() ([]byte, error) {
+	leaves := make(Leaves, len(p.leaves))
+	copy(leaves, p.leaves)
+	sortLeavesAscending(leaves)
+
+	data := make([]byte, 0, versionSize+indexSize+countSize)
+	data = append(data, proofEncodingVersion)
+	data = appendUint64(data, p.totalLeavesCount)
+
+	data = appendUint32(data, uint32(len(leaves)))
+	for _, leaf := range leaves {
+		data = appendUint64(data, leaf.Index)
+		data = appendHash(data, leaf.Hash)
+	}
+
+	data = appendUint32(data, uint32(len(p.proofHashes)))
+	for _, hash := range p.proofHashes {
+		data = appendHash(data, hash)
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes the proof from the binary format of MarshalBinary. The hasher of the proof is kept,
+// so the proof must be created with NewProof and the hasher of the tree before it's decoded. The leaf indices
+// must be unique and in the range of the tree, and the number of the proof hashes must match the leaves.
+func (p *Proof) UnmarshalBinary(data []byte) error {
+	if p.hasher == nil {
+		return errMissingHasher
+	}
+	d := decoder{data: data}
+
+	version, err := d.byte()
+	if err != nil {
+		return err
+	}
+	if version != proofEncodingVersion {
+		return errUnsupportedProofVersion
+	}
+
+	totalLeavesCount, err := d.uint64()
+	if err != nil {
+		return err
+	}
+
+	leavesCount, err := d.count(indexSize + countSize)
+	if err != nil {
+		return err
+	}
+	leaves := make(Leaves, leavesCount)
+	for i := 0; i < leavesCount; i++ {
+		index, err := d.uint64()
+		if err != nil {
+			return err
+		}
+
+		hash, err := d.hash()
+		if err != nil {
+			return err
+		}
+		leaves[i] = types.Leaf{Index: index, Hash: hash}
+	}
+
+	proofHashesCount, err := d.count(countSize)
+	if err != nil {
+		return err
+	}
+	proofHashes := make([][]byte, proofHashesCount)
+	for i := 0; i < proofHashesCount; i++ {
+		if proofHashes[i], err = d.hash(); err != nil {
+			return err
+		}
+	}
+
+	if len(d.data) != 0 {
+		return errInvalidProofEncoding
+	}
+	if err := validateProof(leaves, proofHashesCount, totalLeavesCount); err != nil {
+		return err
+	}
+
+	p.leaves = leaves
+	p.proofHashes = proofHashes
+	p.totalLeavesCount = totalLeavesCount
+	return nil
+}
+
+// MarshalJSON encodes the proof into JSON with 0x prefixed hex hashes and the leaves sorted by their indices:
+//
+//	{"leaves": [{"index": 1, "hash": "0x..."}], "proofHashes": ["0x..."], "totalLeavesCount": 6}
+func (p Proof) MarshalJSON() ([]byte, error) {
+	leaves := make(Leaves, len(p.leaves))
+	copy(leaves, p.leaves)
+	sortLeavesAscending(leaves)
+
+	return json.Marshal(proofJSON{
+		Leaves:           leaves,
+		ProofHashes:      types.HexList(p.proofHashes),
+		TotalLeavesCount: p.totalLeavesCount,
+	})
+}
+
+// UnmarshalJSON decodes the proof from the JSON format of MarshalJSON. Like UnmarshalBinary, it keeps the hasher
+// of the proof and validates the leaves and the number of the proof hashes.
+func (p *Proof) UnmarshalJSON(data []byte) error {
+	if p.hasher == nil {
+		return errMissingHasher
+	}
+	var decoded proofJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	leaves := decoded.Leaves
+	sortLeavesAscending(leaves)
+	if err := validateProof(leaves, len(decoded.ProofHashes), decoded.TotalLeavesCount); err != nil {
+		return err
+	}
+
+	p.leaves = leaves
+	p.proofHashes = types.BytesList(decoded.ProofHashes)
+	p.totalLeavesCount = decoded.TotalLeavesCount
+	return nil
+}
+
+// proofJSON is the JSON representation of Proof
+type proofJSON struct {
+	Leaves           Leaves          `json:"leaves"`
+	ProofHashes      []hexutil.Bytes `json:"proofHashes"`
+	TotalLeavesCount uint64          `json:"totalLeavesCount"`
+}
+
+// validateProof checks that the proof has leaves, that the sorted leaf indices are unique and in the range of the
+// tree, and that the number of the proof hashes matches the number of the hashes required to calculate the root from
+// the leaves
+func validateProof(leaves Leaves, proofHashesCount int, totalLeavesCount uint64) error {
+	if totalLeavesCount == 0 {
+		return errEmptyTree
+	}
+	if len(leaves) == 0 {
+		return errInvalidProofLeaves
+	}
+
+	for i := 0; i < len(leaves); i++ {
+		if leaves[i].Index >= totalLeavesCount || (i > 0 && leaves[i].Index <= leaves[i-1].Index) {
+			return errInvalidProofLeaves
+		}
+	}
+
+	if proofHashesCount != expectedProofHashesCount(leaves, totalLeavesCount) {
+		return errInvalidProofSize
+	}
+	return nil
+}
+
+// expectedProofHashesCount returns the number of the proof hashes required to calculate the root from the sorted
+// leaves
+func expectedProofHashesCount(leaves Leaves, totalLeavesCount uint64) int {
+	leafIndices := make([]uint64, len(leaves))
+	for i := 0; i < len(leaves); i++ {
+		leafIndices[i] = leaves[i].Index
+	}
+
+	count := 0
+	for _, layer := range proofIndicesByLayers(leafIndices, totalLeavesCount) {
+		count += len(layer)
+	}
+	return count
+}
+
+// appendUint32 appends the big endian encoded number to the data
+func appendUint32(data []byte, n uint32) []byte {
+	b := make([]byte, countSize)
+	binary.BigEndian.PutUint32(b, n)
+	return append(data, b...)
+}
+
+// appendUint64 appends the big endian encoded number to the data
+func appendUint64(data []byte, n uint64) []byte {
+	b := make([]byte, indexSize)
+	binary.BigEndian.PutUint64(b, n)
+	return append(data, b...)
+}
+
+// appendHash appends the length prefixed hash to the data
+func appendHash(data, hash []byte) []byte {
+	data = appendUint32(data, uint32(len(hash)))
+	return append(data, hash...)
+}
+
+// decoder reads the values of the binary proof format and returns errInvalidProofEncoding when the data is too short
+type decoder struct {
+	data []byte
+}
+
+func (d *decoder) next(size int) ([]byte, error) {
+	if len(d.data) < size {
+		return nil, errInvalidProofEncoding
+	}
+	b := d.data[:size]
+	d.data = d.data[size:]
+	return b, nil
+}
+
+func (d *decoder) byte() (byte, error) {
+	b, err := d.next(versionSize)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.next(countSize)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	b, err := d.next(indexSize)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// count reads the number of the items that follow, it fails if the remaining data can't hold that many items of
+// the given minimal size, so a corrupted count never causes a huge allocation
+func (d *decoder) count(minItemSize int) (int, error) {
+	count, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(count)*uint64(minItemSize) > uint64(len(d.data)) {
+		return 0, errInvalidProofEncoding
+	}
+	return int(count), nil
+}
+
+// hash reads a length prefixed hash and returns its copy
+func (d *decoder) hash() ([]byte, error) {
+	size, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	b, err := d.next(int(size))
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, b...), nil
+}
@@ -0,0 +1,199 @@
+package merkle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProofBinaryRoundTrip(t *testing.T) {
+	var leaves [][]byte
+	for i := 0; i < 9; i++ {
+		h, err := hasher.Sha256Hasher{}.Hash([]byte{byte(i)})
+		require.NoError(t, err)
+		leaves = append(leaves, h)
+
+		tree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leaves)
+		require.NoError(t, err)
+
+		for _, c := range combinations(tree.leaves()) {
+			var indices []uint64
+			for _, leaf := range c {
+				indices = append(indices, leaf.Index)
+			}
+			proof := tree.Proof(indices)
+
+			data, err := proof.MarshalBinary()
+			require.NoError(t, err)
+
+			decoded := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			require.Equal(t, proof.ProofHashesHex(), decoded.ProofHashesHex())
+			require.Equal(t, proof.totalLeavesCount, decoded.totalLeavesCount)
+			require.ElementsMatch(t, proof.leaves, decoded.leaves)
+
+			verified, err := decoded.Verify(tree.Root())
+			require.NoError(t, err)
+			require.True(t, verified)
+		}
+	}
+}
+
+func TestProofBinaryFormat(t *testing.T) {
+	proof := NewProof(Leaves{{Index: 1, Hash: []byte{0xbb}}, {Index: 0, Hash: []byte{0xaa}}},
+		[][]byte{{0xcc, 0xdd}}, 3, hasher.Sha256Hasher{})
+
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0x01,
+		0, 0, 0, 0, 0, 0, 0, 3,
+		0, 0, 0, 2,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0xaa,
+		0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0xbb,
+		0, 0, 0, 1,
+		0, 0, 0, 2, 0xcc, 0xdd,
+	}, data)
+
+	// the decoded hashes don't share the memory with the input
+	decoded := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	data[len(data)-1] = 0
+	require.Equal(t, []byte{0xcc, 0xdd}, decoded.ProofHashes()[0])
+}
+
+func TestProofBinaryInvalidInput(t *testing.T) {
+	validProof := NewProof(Leaves{{Index: 0, Hash: []byte{0xaa}}, {Index: 1, Hash: []byte{0xbb}}},
+		[][]byte{{0xcc}}, 3, hasher.Sha256Hasher{})
+	valid, err := validProof.MarshalBinary()
+	require.NoError(t, err)
+
+	encode := func(total uint64, leaves []types.Leaf, proofHashes [][]byte) []byte {
+		data, err := NewProof(leaves, proofHashes, total, hasher.Sha256Hasher{}).MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+
+	hugeCount := append([]byte{}, valid[:9]...)
+	hugeCount = append(hugeCount, 0xff, 0xff, 0xff, 0xff)
+
+	hugeHash := append([]byte{}, valid[:21]...)
+	hugeHash = appendUint32(hugeHash, 0xffffffff)
+
+	cases := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"empty", []byte{}, errInvalidProofEncoding},
+		{"unsupported version", append([]byte{2}, valid[1:]...), errUnsupportedProofVersion},
+		{"truncated", valid[:len(valid)-1], errInvalidProofEncoding},
+		{"trailing data", append(append([]byte{}, valid...), 0), errInvalidProofEncoding},
+		{"huge leaves count", hugeCount, errInvalidProofEncoding},
+		{"huge hash length", hugeHash, errInvalidProofEncoding},
+		{"leaf out of range", encode(2, Leaves{{Index: 2, Hash: []byte{0xaa}}}, nil), errInvalidProofLeaves},
+		{"duplicated leaves", []byte{
+			0x01,
+			0, 0, 0, 0, 0, 0, 0, 3,
+			0, 0, 0, 2,
+			0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0xaa,
+			0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0xaa,
+			0, 0, 0, 0,
+		}, errInvalidProofLeaves},
+		{"missing proof hash", encode(3, Leaves{{Index: 0, Hash: []byte{0xaa}}}, [][]byte{{0xbb}}), errInvalidProofSize},
+		{"extra proof hash", encode(3, validProof.leaves, [][]byte{{0xcc}, {0xdd}}), errInvalidProofSize},
+		{"proof hashes without leaves", encode(3, nil, [][]byte{{0xcc}}), errInvalidProofLeaves},
+		{"no leaves", encode(3, nil, nil), errInvalidProofLeaves},
+		{"empty tree", encode(0, nil, nil), errEmptyTree},
+		{"leaf of empty tree", encode(0, Leaves{{Index: 0, Hash: []byte{0xaa}}}, nil), errEmptyTree},
+	}
+
+	for _, c := range cases {
+		proof := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+		require.ErrorIs(t, proof.UnmarshalBinary(c.data), c.err, c.name)
+	}
+}
+
+func TestProofDecodingWithoutHasher(t *testing.T) {
+	proof := NewProof(Leaves{{Index: 0, Hash: []byte{0xaa}}, {Index: 1, Hash: []byte{0xbb}}},
+		[][]byte{{0xcc}}, 3, hasher.Sha256Hasher{})
+	data, err := proof.MarshalBinary()
+	require.NoError(t, err)
+	jsonData, err := json.Marshal(proof)
+	require.NoError(t, err)
+
+	// a zero proof has no hasher to verify the decoded proof with
+	var decoded Proof
+	require.ErrorIs(t, decoded.UnmarshalBinary(data), errMissingHasher)
+	require.ErrorIs(t, json.Unmarshal(jsonData, &decoded), errMissingHasher)
+}
+
+func TestProofJSONRoundTrip(t *testing.T) {
+	testData := setupTestData()
+	tree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(testData.leafHashes)
+	require.NoError(t, err)
+
+	proof := tree.Proof([]uint64{1, 4})
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+
+	decoded := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, proof.ProofHashesHex(), decoded.ProofHashesHex())
+
+	verified, err := decoded.Verify(tree.Root())
+	require.NoError(t, err)
+	require.True(t, verified)
+}
+
+func TestProofJSONFormat(t *testing.T) {
+	proof := NewProof(Leaves{{Index: 1, Hash: []byte{0xbb}}, {Index: 0, Hash: []byte{0xaa}}},
+		[][]byte{{0xcc, 0xdd}}, 3, hasher.Sha256Hasher{})
+
+	data, err := json.Marshal(proof)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"leaves": [{"index": 0, "hash": "0xaa"}, {"index": 1, "hash": "0xbb"}],
+		"proofHashes": ["0xccdd"],
+		"totalLeavesCount": 3
+	}`, string(data))
+
+	// the leaves can be in any order
+	decoded := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"leaves": [{"index": 1, "hash": "0xbb"}, {"index": 0, "hash": "0xaa"}],
+		"proofHashes": ["0xccdd"],
+		"totalLeavesCount": 3
+	}`), &decoded))
+	require.Equal(t, Leaves{{Index: 0, Hash: []byte{0xaa}}, {Index: 1, Hash: []byte{0xbb}}}, decoded.leaves)
+}
+
+func TestProofJSONInvalidInput(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"leaf out of range", `{"leaves":[{"index":3,"hash":"0xaa"}],"proofHashes":[],"totalLeavesCount":3}`, errInvalidProofLeaves},
+		{"duplicated leaves", `{"leaves":[{"index":1,"hash":"0xaa"},{"index":1,"hash":"0xaa"}],"proofHashes":["0xcc"],"totalLeavesCount":3}`,
+			errInvalidProofLeaves},
+		{"missing proof hash", `{"leaves":[{"index":0,"hash":"0xaa"}],"proofHashes":[],"totalLeavesCount":3}`, errInvalidProofSize},
+		{"empty tree", `{"leaves":[],"proofHashes":[],"totalLeavesCount":0}`, errEmptyTree},
+		{"no leaves", `{"leaves":[],"proofHashes":[],"totalLeavesCount":3}`, errInvalidProofLeaves},
+		{"missing leaf hash", `{"leaves":[{"index":0}],"proofHashes":[],"totalLeavesCount":1}`, nil},
+		{"hash without prefix", `{"leaves":[{"index":0,"hash":"aa"}],"proofHashes":[],"totalLeavesCount":1}`, nil},
+		{"string index", `{"leaves":[{"index":"0","hash":"0xaa"}],"proofHashes":[],"totalLeavesCount":1}`, nil},
+	}
+
+	for _, c := range cases {
+		proof := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+		err := json.Unmarshal([]byte(c.data), &proof)
+		require.Error(t, err, c.name)
+		if c.err != nil {
+			require.ErrorIs(t, err, c.err, c.name)
+		}
+	}
+}
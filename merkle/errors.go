@@ -3,5 +3,26 @@ package merkle
 import "errors"
 
 var (
-	errNotEnoughParentNodes = errors.New("not enough parent nodes")
+	errNotEnoughParentNodes    = errors.New("not enough parent nodes")
+	errNoCommitsToRollback     = errors.New("there are no commits to rollback")
+	errLeafIndexOutOfRange     = errors.New("leaf index is out of the tree range")
+	errTreeSizeOutOfRange      = errors.New("tree size is out of the tree range")
+	errInvalidProofSize        = errors.New("proof size does not match the tree size")
+	errUnsupportedProofVersion = errors.New("unsupported proof encoding version")
+	errInvalidProofEncoding    = errors.New("invalid proof encoding")
+	errInvalidHashSize         = errors.New("hash size is invalid")
+	errRootMismatch            = errors.New("root does not match the block header")
+	errDuplicateSubtree        = errors.New("tree has duplicate subtrees")
+	errEmptyTree               = errors.New("there are no leaves in the tree")
+	errInvalidProofLeaves      = errors.New("proof leaf indices are not unique or out of the tree range")
+	errInvalidSumSize          = errors.New("sum size must be positive")
+	errNegativeSum             = errors.New("sum is missing or negative")
+	errSumOverflow             = errors.New("sum overflows the sum size")
+	errInvalidTreeDepth        = errors.New("tree depth is out of the supported range")
+	errTreeFull                = errors.New("there are no empty leaves in the tree")
+	errLeafNotRetained         = errors.New("leaf is not retained by the tree")
+	errInvalidDepositData      = errors.New("deposit data field size is invalid")
+	errInvalidProofUpdate      = errors.New("proof update does not match the proof")
+	errMissingHasher           = errors.New("proof has no hasher, it must be created with NewProof")
+	errInvalidLeavesData       = errors.New("leaves data does not match the proof leaves")
 )
@@ -0,0 +1,343 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// DepositContractDepth is the depth of the incremental tree of the Ethereum deposit contract
+	DepositContractDepth = 32
+	// maxIncrementalDepth is the depth of the deepest incremental tree whose leaves can be counted by an uint64
+	maxIncrementalDepth = 63
+
+	depositPubkeySize                = 48
+	depositWithdrawalCredentialsSize = 32
+	depositSignatureSize             = 96
+	depositChunkSize                 = 32
+	depositAmountSize                = 8
+)
+
+// IncrementalTree is an append only merkle tree of a fixed depth like the trees of the Ethereum deposit contract and
+// the Tornado style mixers. The empty leaves are zero leaves and only the frontier of the tree is stored, the branch
+// of the last left node of every layer, so a leaf is inserted and the root is calculated in O(depth). Like in the
+// deposit contract, the last leaf of the tree is never inserted, since the frontier of a full tree is empty.
+//
+// The leaves that are retained when they are inserted keep their sibling nodes up to date while the tree grows, so
+// their proofs against the current root are available without storing the other leaves. The retained leaves are
+// indexed by the siblings they wait for, so the insertion stays O(depth) however many leaves are retained.
+type IncrementalTree struct {
+	hasher      types.Hasher
+	depth       int
+	zeroHashes  [][]byte
+	branch      [][]byte
+	leavesCount uint64
+	// retained are the sibling nodes of the retained leaves by the leaf index, a nil node isn't known yet
+	retained map[uint64][][]byte
+	// pending are the indices of the retained leaves by the unknown sibling nodes they wait for
+	pending map[incrementalNode]map[uint64]struct{}
+}
+
+// incrementalNode is the position of a node in an incremental tree
+type incrementalNode struct {
+	layer int
+	index uint64
+}
+
+// IncrementalSnapshot is the frontier of an incremental tree, which is enough to restore the tree and keep inserting
+// leaves. It's the state the deposit contract keeps in its storage.
+type IncrementalSnapshot struct {
+	Branch      [][]byte
+	LeavesCount uint64
+}
+
+// NewIncrementalTree creates an empty incremental tree of the depth, the zero leaf is the value of the empty leaves
+func NewIncrementalTree(h types.Hasher, depth int, zeroLeaf []byte) (*IncrementalTree, error) {
+	if depth < 1 || depth > maxIncrementalDepth {
+		return nil, errInvalidTreeDepth
+	}
+
+	zeroHashes := make([][]byte, depth+1)
+	zeroHashes[0] = copyBytes(zeroLeaf)
+	for i := 1; i <= depth; i++ {
+		zeroHash, err := hashIncrementalPair(h, zeroHashes[i-1], zeroHashes[i-1])
+		if err != nil {
+			return nil, err
+		}
+		zeroHashes[i] = zeroHash
+	}
+
+	t := &IncrementalTree{
+		hasher:     h,
+		depth:      depth,
+		zeroHashes: zeroHashes,
+		retained:   make(map[uint64][][]byte),
+		pending:    make(map[incrementalNode]map[uint64]struct{}),
+	}
+	t.branch = append([][]byte{}, zeroHashes[:depth]...)
+	return t, nil
+}
+
+// NewDepositTree creates the empty incremental tree of the Ethereum deposit contract, the sha256 tree of depth 32 and
+// zero leaves of 32 zero bytes
+func NewDepositTree() (*IncrementalTree, error) {
+	return NewIncrementalTree(hasher.Sha256Hasher{}, DepositContractDepth, make([]byte, depositChunkSize))
+}
+
+// Depth returns the depth of the tree
+func (t *IncrementalTree) Depth() int {
+	return t.depth
+}
+
+// LeavesCount returns the number of the inserted leaves
+func (t *IncrementalTree) LeavesCount() uint64 {
+	return t.leavesCount
+}
+
+// Insert appends the leaf to the tree and returns its index
+func (t *IncrementalTree) Insert(leaf []byte) (uint64, error) {
+	return t.insert(leaf, false)
+}
+
+// InsertRetained appends the leaf to the tree, retains it for the proofs and returns its index
+func (t *IncrementalTree) InsertRetained(leaf []byte) (uint64, error) {
+	return t.insert(leaf, true)
+}
+
+// Forget stops keeping the proof of the retained leaf
+func (t *IncrementalTree) Forget(index uint64) {
+	siblings, ok := t.retained[index]
+	if !ok {
+		return
+	}
+	for layer, sibling := range siblings {
+		if sibling == nil {
+			t.unpend(incrementalNode{layer: layer, index: siblingIndex(index >> layer)}, index)
+		}
+	}
+	delete(t.retained, index)
+}
+
+// Root returns the root of the tree, the empty leaves are the zero leaves
+func (t *IncrementalTree) Root() ([]byte, error) {
+	return t.frontierNode(t.depth)
+}
+
+// DepositRoot returns the root of the deposit contract, the root of the tree mixed in with the little endian number of
+// the leaves like get_deposit_root
+func (t *IncrementalTree) DepositRoot() ([]byte, error) {
+	root, err := t.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	count := make([]byte, depositChunkSize)
+	binary.LittleEndian.PutUint64(count, t.leavesCount)
+	return hashIncrementalPair(t.hasher, root, count)
+}
+
+// Proof returns the sibling nodes of the retained leaf from the bottom to the top, the proof of the leaf against the
+// current root
+func (t *IncrementalTree) Proof(index uint64) ([][]byte, error) {
+	siblings, ok := t.retained[index]
+	if !ok {
+		return nil, errLeafNotRetained
+	}
+
+	proof := make([][]byte, t.depth)
+	for layer := 0; layer < t.depth; layer++ {
+		if siblings[layer] != nil {
+			proof[layer] = siblings[layer]
+			continue
+		}
+
+		// the sibling is on the right and it is either empty or the partial subtree of the last leaf
+		if siblingIndex(index>>layer)<<layer >= t.leavesCount {
+			proof[layer] = t.zeroHashes[layer]
+			continue
+		}
+		node, err := t.frontierNode(layer)
+		if err != nil {
+			return nil, err
+		}
+		proof[layer] = node
+	}
+	return proof, nil
+}
+
+// Snapshot returns the frontier of the tree, the retained leaves are not part of it
+func (t *IncrementalTree) Snapshot() IncrementalSnapshot {
+	branch := make([][]byte, len(t.branch))
+	for i, node := range t.branch {
+		branch[i] = copyBytes(node)
+	}
+	return IncrementalSnapshot{Branch: branch, LeavesCount: t.leavesCount}
+}
+
+// Restore replaces the state of the tree with the snapshot of a tree of the same depth, hasher and zero leaf. The
+// retained leaves are forgotten.
+func (t *IncrementalTree) Restore(snapshot IncrementalSnapshot) error {
+	if len(snapshot.Branch) != t.depth {
+		return errInvalidProofSize
+	}
+	if snapshot.LeavesCount >= 1<<t.depth {
+		return errTreeSizeOutOfRange
+	}
+
+	t.branch = make([][]byte, t.depth)
+	for i, node := range snapshot.Branch {
+		t.branch[i] = copyBytes(node)
+	}
+	t.leavesCount = snapshot.LeavesCount
+	t.retained = make(map[uint64][][]byte)
+	t.pending = make(map[incrementalNode]map[uint64]struct{})
+	return nil
+}
+
+// insert appends the leaf like the deposit function of the deposit contract, the completed nodes are stored in the
+// branch and in the siblings of the retained leaves
+func (t *IncrementalTree) insert(leaf []byte, retain bool) (uint64, error) {
+	index := t.leavesCount
+	if index >= 1<<t.depth-1 {
+		return 0, errTreeFull
+	}
+
+	size := index + 1
+	node := copyBytes(leaf)
+	for layer := 0; layer < t.depth; layer++ {
+		// node is the last completed node of the layer
+		t.fillRetained(layer, size>>layer-1, node)
+		if isOdd(size >> layer) {
+			t.branch[layer] = node
+			break
+		}
+
+		var err error
+		if node, err = hashIncrementalPair(t.hasher, t.branch[layer], node); err != nil {
+			return 0, err
+		}
+	}
+	t.leavesCount = size
+
+	if retain {
+		// the left siblings of the leaf are in the branch, only the layers above them are updated by the insertion
+		siblings := make([][]byte, t.depth)
+		for layer := 0; layer < t.depth; layer++ {
+			if isOdd(index >> layer) {
+				siblings[layer] = t.branch[layer]
+				continue
+			}
+			key := incrementalNode{layer: layer, index: siblingIndex(index >> layer)}
+			if t.pending[key] == nil {
+				t.pending[key] = make(map[uint64]struct{})
+			}
+			t.pending[key][index] = struct{}{}
+		}
+		t.retained[index] = siblings
+	}
+	return index, nil
+}
+
+// fillRetained stores the completed node of the layer in the siblings of the retained leaves it is the sibling of
+func (t *IncrementalTree) fillRetained(layer int, nodeIndex uint64, node []byte) {
+	key := incrementalNode{layer: layer, index: nodeIndex}
+	for index := range t.pending[key] {
+		t.retained[index][layer] = node
+	}
+	delete(t.pending, key)
+}
+
+// unpend removes the retained leaf from the leaves waiting for the node
+func (t *IncrementalTree) unpend(key incrementalNode, index uint64) {
+	delete(t.pending[key], index)
+	if len(t.pending[key]) == 0 {
+		delete(t.pending, key)
+	}
+}
+
+// frontierNode returns the node of the layer that contains the next leaf, the root of the subtree of the last leaves
+// padded with the zero leaves like get_deposit_root calculates the root
+func (t *IncrementalTree) frontierNode(layer int) ([]byte, error) {
+	node := t.zeroHashes[0]
+	for i := 0; i < layer; i++ {
+		var err error
+		if isOdd(t.leavesCount >> i) {
+			node, err = hashIncrementalPair(t.hasher, t.branch[i], node)
+		} else {
+			node, err = hashIncrementalPair(t.hasher, node, t.zeroHashes[i])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// VerifyIncrementalProof verifies the proof of the leaf at the index of an incremental tree against the root, like
+// is_valid_merkle_branch. The depth of the tree is the number of the proof nodes.
+func VerifyIncrementalProof(h types.Hasher, leaf []byte, index uint64, proof [][]byte, root []byte) (bool, error) {
+	if len(proof) < maxIncrementalDepth && index>>len(proof) != 0 {
+		return false, errLeafIndexOutOfRange
+	}
+
+	node := leaf
+	for layer, sibling := range proof {
+		var err error
+		if isOdd(index >> layer) {
+			node, err = hashIncrementalPair(h, sibling, node)
+		} else {
+			node, err = hashIncrementalPair(h, node, sibling)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return bytes.Equal(node, root), nil
+}
+
+// DepositDataRoot returns the leaf of a deposit, the SSZ hash_tree_root of the DepositData that the deposit contract
+// calculates from the public key, the withdrawal credentials, the amount in Gwei and the signature
+func DepositDataRoot(pubkey, withdrawalCredentials []byte, amount uint64, signature []byte) ([]byte, error) {
+	if len(pubkey) != depositPubkeySize || len(withdrawalCredentials) != depositWithdrawalCredentialsSize ||
+		len(signature) != depositSignatureSize {
+		return nil, errInvalidDepositData
+	}
+
+	h := hasher.Sha256Hasher{}
+	pubkeyRoot, err := h.Hash(append(copyBytes(pubkey), make([]byte, depositChunkSize-depositPubkeySize%depositChunkSize)...))
+	if err != nil {
+		return nil, err
+	}
+	signatureLeft, err := h.Hash(signature[:2*depositChunkSize])
+	if err != nil {
+		return nil, err
+	}
+	signatureRight, err := h.Hash(append(copyBytes(signature[2*depositChunkSize:]), make([]byte, depositChunkSize)...))
+	if err != nil {
+		return nil, err
+	}
+	signatureRoot, err := hashIncrementalPair(h, signatureLeft, signatureRight)
+	if err != nil {
+		return nil, err
+	}
+
+	amountChunk := make([]byte, depositChunkSize)
+	binary.LittleEndian.PutUint64(amountChunk[:depositAmountSize], amount)
+	left, err := hashIncrementalPair(h, pubkeyRoot, withdrawalCredentials)
+	if err != nil {
+		return nil, err
+	}
+	right, err := hashIncrementalPair(h, amountChunk, signatureRoot)
+	if err != nil {
+		return nil, err
+	}
+	return hashIncrementalPair(h, left, right)
+}
+
+// hashIncrementalPair returns the hash of the concatenated nodes, it never modifies the nodes
+func hashIncrementalPair(h types.Hasher, left, right []byte) ([]byte, error) {
+	return h.Hash(append(copyBytes(left), right...))
+}
@@ -0,0 +1,270 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/ssz"
+)
+
+func TestDepositTreeRoots(t *testing.T) {
+	tree, err := NewDepositTree()
+	require.NoError(t, err)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	emptyRoot, err := ssz.ZeroHash(DepositContractDepth)
+	require.NoError(t, err)
+	require.Equal(t, emptyRoot, root)
+	depositRoot, err := tree.DepositRoot()
+	require.NoError(t, err)
+	require.Equal(t, "d70a234731285c6804c2a4f56711ddb8c82c99740f207854891028af34e27e5e", hex.EncodeToString(depositRoot))
+
+	// the deposit root is the hash_tree_root of the list of the deposit data roots
+	var leaves [][]byte
+	for i := 0; i < 40; i++ {
+		pubkey := bytes.Repeat([]byte{byte(i)}, depositPubkeySize)
+		credentials := bytes.Repeat([]byte{byte(i + 1)}, depositWithdrawalCredentialsSize)
+		signature := bytes.Repeat([]byte{byte(i + 2)}, depositSignatureSize)
+		leaf, err := DepositDataRoot(pubkey, credentials, 32e9, signature)
+		require.NoError(t, err)
+		leaves = append(leaves, leaf)
+
+		index, err := tree.Insert(leaf)
+		require.NoError(t, err)
+		require.Equal(t, uint64(i), index)
+
+		expected, err := ssz.Merkleize(leaves, 1<<DepositContractDepth)
+		require.NoError(t, err)
+		root, err = tree.Root()
+		require.NoError(t, err)
+		require.Equal(t, expected, root, "%d leaves", i+1)
+
+		expected, err = ssz.MixInLength(expected, uint64(i+1))
+		require.NoError(t, err)
+		depositRoot, err = tree.DepositRoot()
+		require.NoError(t, err)
+		require.Equal(t, expected, depositRoot, "%d leaves", i+1)
+	}
+	require.Equal(t, uint64(40), tree.LeavesCount())
+}
+
+// TestDepositContractRoots checks the deposit data roots and the deposit roots against the deposit contract. The
+// deposits are the interop deposits of the first 5 validators of prysm v5.0.3, runtime/interop.DepositDataFromKeys of
+// DeterministicallyGenerateKeys(0, 5). They were made to the bytecode of the consensus-specs deposit contract copied by
+// prysm in contracts/deposit, executed by the go-ethereum EVM, which checks the deposit data roots, and the deposit
+// roots were read by get_deposit_root after every deposit.
+func TestDepositContractRoots(t *testing.T) {
+	deposits := []struct {
+		pubkey, credentials, signature, dataRoot, depositRoot string
+	}{
+		{
+			pubkey:      "a99a76ed7796f7be22d5b7e85deeb7c5677e88e511e0b337618f8c4eb61349b4bf2d153f649f7b53359fe8b94a38e44c",
+			credentials: "00fad2a6bfb0e7f1f0f45460944fbd8dfa7f37da06a4d13b3983cc90bb46963b",
+			signature: "a9ac65fdd32e9ea916127b5c307a4abde9bde12e751f372c5f0aa84f62f09eba673b25949673c5c5d01527ecff90205e" +
+				"02389d709a74715b5f3f30d3defd0fc559e9480eae522463d7c9e6b77649132ba1fa3b4b33f7b1f471d22829df9f9416",
+			dataRoot:    "97f892cc0b7e6ac39e28c650ea91c06c32ffcf6a37f9fffd30998d1faf7767d3",
+			depositRoot: "7f49c76c73e81a2f62575e951d12cc04c4267ad393dc195a2e7d6b395766addc",
+		},
+		{
+			pubkey:      "b89bebc699769726a318c8e9971bd3171297c61aea4a6578a7a4f94b547dcba5bac16a89108b6b6a1fe3695d1a874a0b",
+			credentials: "00ec7ef7780c9d151597924036262dd28dc60e1228f4da6fecf9d402cb3f3594",
+			signature: "b2932d835a256a5078c66632e8e31d9abe479d964177ea95658d5b5cc35cd1d82fd91abd79303a2a4921181b6b809446" +
+				"03fbc69503b5db7652cbd2200880bb322d623feb7d71ee17c0e3f7fe988429df0688e47e9a3846706eb86f318fc6e4ac",
+			dataRoot:    "eb890631149094d68f0ddab356af40a4c09d9c781d27f6d2a9539d583a123fd4",
+			depositRoot: "605342e6d6ddb8386d037b4c2338d20f32be4ebf78ba15774fcaae7249f63a83",
+		},
+		{
+			pubkey:      "a3a32b0f8b4ddb83f1a0a853d81dd725dfe577d4f4c3db8ece52ce2b026eca84815c1a7e8e92a4de3d755733bf7e4a9b",
+			credentials: "0036085c6c608e6d048505b04402568c36cce1e025722de44f9c3685a5c80fa6",
+			signature: "b24ebd9badbe8fac4eb26b05b0f7a1d85f95fb11d84db575fb046bf94d9df9a2d26734e5b081e93b71e041c8c6a438f4" +
+				"1581949529d5cae4703c583084ee103fb35e76035be709e3850eec0da9639de14afb41c0764c1ab3466c7ef28a11ba48",
+			dataRoot:    "a9f6df518990a565c118a4d2c04ac9f5c66339b815cb37569ed036c5ed4b14f9",
+			depositRoot: "d46c40c599aae854eff252d6ec2467f142106c469b27068bfe3d5841f842495b",
+		},
+		{
+			pubkey:      "88c141df77cd9d8d7a71a75c826c41a9c9f03c6ee1b180f3e7852f6a280099ded351b58d66e653af8e42816a4d8f532e",
+			credentials: "005a7de495bcec04d3b5e74ae09ffe493a9dd06d7dcbf18c78455571e87d901a",
+			signature: "ae9934df9db14b740c7b9f30bc5feaff24372afe19c94b30ecf5d1ae14fb616dabaf6529a8fd662b7dc2d610eb80708c" +
+				"18b18f2cc6e282490059ea02a302e106305c57281bddc1260c952d6df5dc7fedb1d1a97446359f13caaf08f6792a59c5",
+			dataRoot:    "a37196e760e46416793f7e1e516e2becfc1a331d42083d2bf4c5387f91fddcf5",
+			depositRoot: "61499f63bce3e3c93bcd12447589ce1ae9fbdaeb460fef68f6e8ba3d700b94e7",
+		},
+		{
+			pubkey:      "81283b7a20e1ca460ebd9bbd77005d557370cabb1f9a44f530c4c4c66230f675f8df8b4c2818851aa7d77a80ca5a4a5e",
+			credentials: "004a28c193c65c91b7ebb5b5d14ffa7f75dc48ad4bc66de82f70fc55a2df1215",
+			signature: "8af2333a31c2b53c60d05368a7544864000f4f9b9e6395c5331bd2a4b059a86774d5f2a13a17f6ce4b7c394dcbe9262a" +
+				"0d1966f3e5216173e8e9448978b04c0103c639f05b5bf864681d3baad16ebe226707d90ab15fb822c46e81bbaca4bf97",
+			dataRoot:    "0624047046d41178bdb12d164e76a3a901c9b590dc9af23eb4b0123046e0c08f",
+			depositRoot: "cfe85b360d6724e57ea976b304008633fb54a14dd22fc823540c42f7e570c2be",
+		},
+	}
+
+	tree, err := NewDepositTree()
+	require.NoError(t, err)
+	for i, deposit := range deposits {
+		dataRoot, err := DepositDataRoot(common.FromHex(deposit.pubkey), common.FromHex(deposit.credentials), 32e9,
+			common.FromHex(deposit.signature))
+		require.NoError(t, err)
+		require.Equal(t, deposit.dataRoot, hex.EncodeToString(dataRoot), "deposit %d", i)
+
+		_, err = tree.Insert(dataRoot)
+		require.NoError(t, err)
+		depositRoot, err := tree.DepositRoot()
+		require.NoError(t, err)
+		require.Equal(t, deposit.depositRoot, hex.EncodeToString(depositRoot), "%d deposits", i+1)
+	}
+}
+
+func TestDepositDataRoot(t *testing.T) {
+	pubkey := bytes.Repeat([]byte{1}, depositPubkeySize)
+	credentials := bytes.Repeat([]byte{2}, depositWithdrawalCredentialsSize)
+	signature := bytes.Repeat([]byte{3}, depositSignatureSize)
+
+	// the SSZ container of the Bytes48, Bytes32, uint64 and Bytes96 fields
+	pubkeyRoot, err := ssz.Merkleize(ssz.Pack(pubkey), 0)
+	require.NoError(t, err)
+	signatureRoot, err := ssz.Merkleize(ssz.Pack(signature), 0)
+	require.NoError(t, err)
+	amount := make([]byte, ssz.ChunkSize)
+	binary.LittleEndian.PutUint64(amount, 32e9)
+	expected, err := ssz.Merkleize([][]byte{pubkeyRoot, credentials, amount, signatureRoot}, 0)
+	require.NoError(t, err)
+
+	root, err := DepositDataRoot(pubkey, credentials, 32e9, signature)
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+
+	_, err = DepositDataRoot(pubkey[1:], credentials, 32e9, signature)
+	require.ErrorIs(t, err, errInvalidDepositData)
+}
+
+func TestIncrementalTreeProofs(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	tree, err := NewIncrementalTree(h, 6, make([]byte, 32))
+	require.NoError(t, err)
+
+	// the last leaf is never inserted like in the deposit contract
+	leaves := keccakLeaves(t, 63)
+	retained := map[uint64]bool{0: true, 5: true, 16: true, 31: true, 36: true, 62: true}
+	for i, leaf := range leaves {
+		if retained[uint64(i)] {
+			_, err = tree.InsertRetained(leaf)
+		} else {
+			_, err = tree.Insert(leaf)
+		}
+		require.NoError(t, err)
+		root, err := tree.Root()
+		require.NoError(t, err)
+
+		for index := range retained {
+			if index > uint64(i) {
+				continue
+			}
+			proof, err := tree.Proof(index)
+			require.NoError(t, err)
+			require.Len(t, proof, 6)
+			verified, err := VerifyIncrementalProof(h, leaves[index], index, proof, root)
+			require.NoError(t, err)
+			require.True(t, verified, "leaf %d of %d", index, i+1)
+
+			verified, err = VerifyIncrementalProof(h, leaves[index], index^1, proof, root)
+			require.NoError(t, err)
+			require.False(t, verified, "leaf %d of %d", index, i+1)
+		}
+	}
+
+	_, err = tree.Insert(leaves[0])
+	require.ErrorIs(t, err, errTreeFull)
+	_, err = tree.Proof(1)
+	require.ErrorIs(t, err, errLeafNotRetained)
+	tree.Forget(5)
+	_, err = tree.Proof(5)
+	require.ErrorIs(t, err, errLeafNotRetained)
+	_, err = VerifyIncrementalProof(h, leaves[0], 64, make([][]byte, 6), leaves[0])
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+
+	_, err = NewIncrementalTree(h, 0, nil)
+	require.ErrorIs(t, err, errInvalidTreeDepth)
+	_, err = NewIncrementalTree(h, 64, nil)
+	require.ErrorIs(t, err, errInvalidTreeDepth)
+}
+
+func TestIncrementalTreeForget(t *testing.T) {
+	h := hasher.Sha256Hasher{}
+	tree, err := NewDepositTree()
+	require.NoError(t, err)
+	leaves := keccakLeaves(t, 40)
+	for _, leaf := range leaves[:10] {
+		_, err = tree.InsertRetained(leaf)
+		require.NoError(t, err)
+	}
+	for index := uint64(0); index < 10; index += 2 {
+		tree.Forget(index)
+	}
+	tree.Forget(20)
+
+	// only the retained leaves wait for their siblings
+	for key, indices := range tree.pending {
+		for index := range indices {
+			require.True(t, isOdd(index), "leaf %d waits for the node %d of the layer %d", index, key.index, key.layer)
+			require.Nil(t, tree.retained[index][key.layer])
+		}
+	}
+
+	for _, leaf := range leaves[10:] {
+		_, err = tree.Insert(leaf)
+		require.NoError(t, err)
+	}
+	root, err := tree.Root()
+	require.NoError(t, err)
+	for index := uint64(1); index < 10; index += 2 {
+		proof, err := tree.Proof(index)
+		require.NoError(t, err)
+		verified, err := VerifyIncrementalProof(h, leaves[index], index, proof, root)
+		require.NoError(t, err)
+		require.True(t, verified, "leaf %d", index)
+		tree.Forget(index)
+	}
+	require.Empty(t, tree.pending)
+	require.Empty(t, tree.retained)
+}
+
+func TestIncrementalTreeSnapshot(t *testing.T) {
+	tree, err := NewDepositTree()
+	require.NoError(t, err)
+	leaves := keccakLeaves(t, 21)
+	for _, leaf := range leaves[:13] {
+		_, err = tree.Insert(leaf)
+		require.NoError(t, err)
+	}
+
+	snapshot := tree.Snapshot()
+	require.Len(t, snapshot.Branch, DepositContractDepth)
+	restored, err := NewDepositTree()
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(snapshot))
+
+	for _, leaf := range leaves[13:] {
+		_, err = tree.Insert(leaf)
+		require.NoError(t, err)
+		_, err = restored.Insert(leaf)
+		require.NoError(t, err)
+
+		root, err := tree.DepositRoot()
+		require.NoError(t, err)
+		restoredRoot, err := restored.DepositRoot()
+		require.NoError(t, err)
+		require.Equal(t, root, restoredRoot)
+	}
+	require.Equal(t, uint64(13), snapshot.LeavesCount)
+
+	require.ErrorIs(t, restored.Restore(IncrementalSnapshot{Branch: snapshot.Branch[1:]}), errInvalidProofSize)
+	require.ErrorIs(t, restored.Restore(IncrementalSnapshot{Branch: snapshot.Branch, LeavesCount: 1 << 32}),
+		errTreeSizeOutOfRange)
+}
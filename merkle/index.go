@@ -69,7 +69,21 @@ func parentIndex(index uint64) uint64 {
 	return siblingIndex(index) / halfDivider
 }
 
-// extractNewIndicesFromSiblings finds the sibling indices which is not present in leaf indices
-func extractNewIndicesFromSiblings(siblingIndices []uint64, leafIndices []uint64) []uint64 {
-	return sliceDifferences(siblingIndices, leafIndices)
+// proofNodeIndices returns the indices of the siblings of the sorted leaf indices which are not leaf indices
+// themselves, these are the nodes of a layer needed to calculate the next layer. The result is sorted too.
+// ex. the leaf indices 2, 3 and 6 need only the node 7, since 2 and 3 are the siblings of each other
+func proofNodeIndices(leafIndices []uint64) []uint64 {
+	var proofIndices []uint64
+	for i := 0; i < len(leafIndices); i++ {
+		index := leafIndices[i]
+		sibling := siblingIndex(index)
+
+		// a right sibling that is a leaf index too is the next index of the sorted indices
+		if isEvenIndex(index) && i+1 < len(leafIndices) && leafIndices[i+1] == sibling {
+			i++
+			continue
+		}
+		proofIndices = append(proofIndices, sibling)
+	}
+	return proofIndices
 }
@@ -1,15 +1,30 @@
 package merkle
 
 import (
+	"context"
+	"sync"
+
 	"github.com/ComposableFi/go-merkle-trees/hasher"
 	"github.com/ComposableFi/go-merkle-trees/types"
 )
 
+const (
+	// minParentsPerWorker is the minimum number of parents merged by a worker of a parallel build
+	minParentsPerWorker = 1024
+	// contextCheckInterval is the number of parents merged between the checks of the context
+	contextCheckInterval = 1024
+)
+
 // build is a wrapper for buildTree
 func (pt *PartialTree) build(partialLayers Layers, depth uint64) (PartialTree, error) {
+	return pt.buildContext(context.Background(), partialLayers, depth)
+}
+
+// buildContext is a wrapper for buildTree which stops building the tree when the context is done
+func (pt *PartialTree) buildContext(ctx context.Context, partialLayers Layers, depth uint64) (PartialTree, error) {
 
 	// build partial tree layers
-	layers, err := pt.buildTree(partialLayers, depth)
+	layers, err := pt.buildTree(ctx, partialLayers, depth)
 	if err != nil {
 		return PartialTree{}, err
 	}
@@ -21,7 +36,7 @@ func (pt *PartialTree) build(partialLayers Layers, depth uint64) (PartialTree, e
 // from merkle proof, or if a complete set of leaves provided as a first argument and no
 // helper indices given, will construct the whole tree.
 // the layers need to be reversed because we are going to process the tree from the bottom and merge left and right nodes to get parent
-func (pt *PartialTree) buildTree(partialLayers Layers, fullTreeDepth uint64) (Layers, error) {
+func (pt *PartialTree) buildTree(ctx context.Context, partialLayers Layers, fullTreeDepth uint64) (Layers, error) {
 
 	// reverse the layers to process backward
 	reversedLayers := reverseLayers(partialLayers)
@@ -32,59 +47,40 @@ func (pt *PartialTree) buildTree(partialLayers Layers, fullTreeDepth uint64) (La
 	// loop through all indices of full tree depth
 	for i := uint64(0); i < fullTreeDepth; i++ {
 
-		// add nodes to current layer for following process
+		// merge the sorted nodes of the layer into the current layer for following process
 		if len(reversedLayers) > 0 {
 			var nodes Leaves
 			nodes, reversedLayers = popLayer(reversedLayers)
-			currentLayer = append(currentLayer, nodes...)
+			currentLayer = mergeLeaves(currentLayer, nodes)
 		}
 
-		sortLeavesAscending(currentLayer)
-
 		partialTree = append(partialTree, currentLayer)
 
 		// to get siblings we need to have indices and hashes in separate slices
 		indices, hashes := extractIndicesAndHashes(currentLayer)
 
-		// freeup for next round
-		currentLayer = make(Leaves, 0)
-
 		// get parent indices to set the merged node hash
 		parentIndices := parentIndecies(indices)
 
-		// loop through parents and set the merged hash
+		// it means we have not enough parent indices to match hashes with
+		if len(parentIndices) > 0 && len(hashes) <= getLeftIndex(len(parentIndices)-1) {
+			return Layers{}, errNotEnoughParentNodes
+		}
+
+		// merge left and right hashes of every parent
+		parentHashes, err := pt.mergeHashes(ctx, hashes, len(parentIndices))
+		if err != nil {
+			return Layers{}, err
+		}
+
+		// set the parent nodes as the current layer for next round
+		currentLayer = make(Leaves, len(parentIndices))
 		for i := 0; i < len(parentIndices); i++ {
-			parnetNodeIndex := parentIndices[i]
-			leftIndex := getLeftIndex(i)
-			if len(hashes) > leftIndex {
-				rightIndex := getRightIndex(i)
-
-				// calculate left and right hash
-				leftHash := hashes[leftIndex]
-				var rightHash []byte
-				if len(hashes) > rightIndex {
-					rightHash = hashes[rightIndex]
-				} else {
-					rightHash = nil
-				}
-
-				// merge left and right hash and merge them
-				hash, err := hasher.MergeAndHash(pt.hasher, leftHash, rightHash)
-				if err != nil {
-					return Layers{}, err
-				}
-
-				// append parent node to the current layer for next round
-				currentLayer = append(currentLayer, types.Leaf{
-					Index: parnetNodeIndex,
-					Hash:  hash,
-				})
-			} else {
-				// it means we have not enough parent indices to match hashes with
-				return Layers{}, errNotEnoughParentNodes
+			currentLayer[i] = types.Leaf{
+				Index: parentIndices[i],
+				Hash:  parentHashes[i],
 			}
 		}
-
 	}
 
 	// update and return partial tree after traversing the whole depth of full tree
@@ -95,108 +91,88 @@ func (pt *PartialTree) buildTree(partialLayers Layers, fullTreeDepth uint64) (La
 	return partialTree, nil
 }
 
-// Root returns the root of the tree, it is the first item hash of the last layer
-func (pt *PartialTree) Root() []byte {
-
-	if len(pt.layers) > 0 {
-		// get the last layer
-		lastLayer := pt.layers[len(pt.layers)-1]
-
-		// get the first leaf of top layer
-		firstItem := lastLayer[0]
+// mergeHashes returns the hashes of the parents of the layer hashes, where the parent i is the merged hash of the
+// hashes 2i and 2i+1, or the promoted hash 2i if it has no sibling. The parents are split between the workers of the
+// partial tree when the layer is big enough, the result is the same as the sequential merge.
+func (pt *PartialTree) mergeHashes(ctx context.Context, hashes [][]byte, parentsCount int) ([][]byte, error) {
+	parentHashes := make([][]byte, parentsCount)
 
-		// return the hash of most top node as partial tree root
-		return firstItem.Hash
+	// every worker merges at least minParentsPerWorker parents, so small layers are not worth the goroutines
+	workers := pt.workers
+	if maxWorkers := parentsCount / minParentsPerWorker; workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers < 2 {
+		return parentHashes, pt.mergeHashesRange(ctx, hashes, parentHashes, 0, parentsCount)
 	}
 
-	// no root if no layers is available
-	return nil
-}
-
-// contains checks if a node index is present in a layer
-func (pt *PartialTree) contains(layerIndex, nodeIndex uint64) bool {
-
-	// check if the layer exists
-	layerLeaves, ok := layerAtIndex(pt.layers, layerIndex)
-	if ok {
-
-		// check all leaves indices
-		for i := 0; i < len(layerLeaves); i++ {
+	chunkSize := (parentsCount + workers - 1) / workers
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*chunkSize, (w+1)*chunkSize
+		if end > parentsCount {
+			end = parentsCount
+		}
 
-			// if leaves of layer have index
-			if nodeIndex == layerLeaves[i].Index {
-				return true
-			}
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			errs[w] = pt.mergeHashesRange(ctx, hashes, parentHashes, start, end)
+		}(w, start, end)
+	}
+	wg.Wait()
 
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
 	}
-
-	// layer or node in the layer not exist
-	return false
+	return parentHashes, nil
 }
 
-// mergeUnverifiedLayers gets other partial tree into itself, replacing any conflicting nodes with nodes from
-// `other` in the process. Doesn't rehash the nodes, so the integrity of the result is
-// not verified. It gives an advantage in speed, but should be used only if the integrity of
-// the tree can't be broken, for example, it is used in the `.commit` method of the
-// `MerkleTree`, since both partial trees are essentially constructed in place and there's
-// no need to verify integrity of the result.
-func (pt *PartialTree) mergeUnverifiedLayers(other PartialTree) {
-
-	// calculate size of combined layers of new partial tree and current tree
-	depthDifference := len(other.layers) - len(pt.layers)
-	var combinedTreeSize uint64
-	if depthDifference > 0 {
-		combinedTreeSize = uint64(len(other.layers))
-	} else {
-		combinedTreeSize = uint64(len(pt.layers))
-	}
-
-	// loop until we reach the combined size
-	for layerIndex := uint64(0); layerIndex < combinedTreeSize; layerIndex++ {
-		var combinedLayer, filteredLayer Leaves
-
-		// populate existing layer nodes that are missing in the new partial tree
-		selfLayer, ok := layerAtIndex(pt.layers, layerIndex)
-		if ok {
-			for i := 0; i < len(selfLayer); i++ {
-				node := selfLayer[i]
-				if !other.contains(layerIndex, node.Index) {
-					filteredLayer = append(filteredLayer, node)
-				}
+// mergeHashesRange sets the parent hashes from start to end, it checks the context every contextCheckInterval parents
+func (pt *PartialTree) mergeHashesRange(ctx context.Context, hashes, parentHashes [][]byte, start, end int) error {
+	for i := start; i < end; i++ {
+		if (i-start)%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
 			}
-			combinedLayer = append(combinedLayer, filteredLayer...)
 		}
 
-		// append new tree to the combined layer
-		otherLayer, ok := layerAtIndex(other.layers, layerIndex)
-		if ok {
-			combinedLayer = append(combinedLayer, otherLayer...)
+		leftIndex, rightIndex := getLeftIndex(i), getRightIndex(i)
+		var rightHash []byte
+		if len(hashes) > rightIndex {
+			rightHash = hashes[rightIndex]
 		}
 
-		// sort combined and make it final
-		sortLeavesAscending(otherLayer)
+		// the capacity of the left hash is limited so merging doesn't write into the memory shared with other nodes
+		leftHash := hashes[leftIndex][:len(hashes[leftIndex]):len(hashes[leftIndex])]
+		hash, err := hasher.MergeAndHash(pt.hasher, leftHash, rightHash)
+		if err != nil {
+			return err
+		}
+		parentHashes[i] = hash
+	}
+	return nil
+}
 
-		// update or insert all of processes combined nodes into the layer
-		pt.upsertLayer(layerIndex, combinedLayer)
+// Root returns the root of the tree, it is the first item hash of the last layer
+func (pt *PartialTree) Root() []byte {
 
-	}
+	if len(pt.layers) > 0 && len(pt.layers[len(pt.layers)-1]) > 0 {
+		// get the last layer
+		lastLayer := pt.layers[len(pt.layers)-1]
 
-}
+		// get the first leaf of top layer
+		firstItem := lastLayer[0]
 
-// upsertLayer replaces layer at a given index with a new layer. Used during tree merge
-func (pt *PartialTree) upsertLayer(layerIndex uint64, newLayer Leaves) {
-
-	// check layer existance
-	_, ok := layerAtIndex(pt.layers, layerIndex)
-	if ok {
-		// layer exists then update
-		pt.layers[layerIndex] = newLayer
-	} else {
-		// layer not exists then insert
-		pt.layers = append(pt.layers, newLayer)
+		// return the hash of most top node as partial tree root
+		return firstItem.Hash
 	}
 
+	// no root if no layers is available
+	return nil
 }
 
 // layerNodesHashes returns all hashes of all layers
@@ -9,7 +9,7 @@ import (
 func BenchmarkBuildPartialTree(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	partialTreeLayers, uncommittedTreeDepth := mtree.uncommittedPartialTreeLayers()
 	ptree := NewPartialTree(mtree.hasher)
 	for n := 0; n < b.N; n++ {
@@ -20,7 +20,7 @@ func BenchmarkBuildPartialTree(b *testing.B) {
 func BenchmarkReverseLayers(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	partialTreeLayers, _ := mtree.uncommittedPartialTreeLayers()
 	for n := 0; n < b.N; n++ {
 		reverseLayers(partialTreeLayers)
@@ -30,7 +30,7 @@ func BenchmarkReverseLayers(b *testing.B) {
 func BenchmarkLayerNodesHashes(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	partialTreeLayers, uncommittedTreeDepth := mtree.uncommittedPartialTreeLayers()
 	ptree := NewPartialTree(mtree.hasher)
 	ptree.build(partialTreeLayers, uncommittedTreeDepth)
@@ -3,8 +3,8 @@ package merkle
 import (
 	"bytes"
 	"encoding/hex"
-	"math"
 
+	"github.com/ComposableFi/go-merkle-trees/hasher"
 	"github.com/ComposableFi/go-merkle-trees/types"
 )
 
@@ -22,12 +22,37 @@ func (p Proof) Verify(expectedRoot []byte) (bool, error) {
 	return bytes.Equal(extractedRoot, expectedRoot), nil
 }
 
+// VerifyData verifies the proof like Verify, the leaves hashes are the data of the leaves, in the order of Leaves,
+// hashed by hasher.HashLeaf with the proof hasher like Tree.FromData does.
+func (p Proof) VerifyData(expectedRoot []byte, data [][]byte) (bool, error) {
+	if len(data) != len(p.leaves) {
+		return false, errInvalidLeavesData
+	}
+	leaves := make(Leaves, len(p.leaves))
+	for i, leaf := range p.leaves {
+		hash, err := hasher.HashLeaf(p.hasher, data[i])
+		if err != nil {
+			return false, err
+		}
+		leaves[i] = types.Leaf{Index: leaf.Index, Hash: hash}
+	}
+
+	p.leaves = leaves
+	return p.Verify(expectedRoot)
+}
+
 // Root calculates Merkle root based on provided leaves and proof hashes. Used inside the
-// Verify method, but sometimes can be used on its own.
+// Verify method, but sometimes can be used on its own. It returns an error if the number of the proof hashes
+// doesn't match the leaves and the tree size.
 func (p Proof) Root() ([]byte, error) {
 
 	sortLeavesAscending(p.leaves)
 
+	// the proof hashes must match the leaves, a missing hash can't be taken from the proof layers
+	if err := validateProof(p.leaves, len(p.proofHashes), p.totalLeavesCount); err != nil {
+		return []byte{}, err
+	}
+
 	// extract proof leaves indices
 	leafIndices := make([]uint64, len(p.leaves))
 	for i := 0; i < len(p.leaves); i++ {
@@ -37,11 +62,8 @@ func (p Proof) Root() ([]byte, error) {
 	proofLayers := p.proofLayers(leafIndices)
 
 	if len(proofLayers) > 0 {
-		// set the first layer as proof
-		firstLayer := proofLayers[0]
-		firstLayer = append(firstLayer, p.leaves...)
-		sortLeavesAscending(firstLayer)
-		proofLayers[0] = firstLayer
+		// merge the proof nodes of the first layer with the sorted leaves
+		proofLayers[0] = mergeLeaves(proofLayers[0], p.leaves)
 	} else {
 		proofLayers = append(proofLayers, p.leaves)
 	}
@@ -84,28 +106,27 @@ func (p Proof) ProofHashesHex() []string {
 	return hexList
 }
 
+// Leaves returns the proven leaves of the proof
+func (p Proof) Leaves() Leaves {
+	return p.leaves
+}
+
+// TotalLeavesCount returns the number of the leaves of the tree the proof was made for
+func (p Proof) TotalLeavesCount() uint64 {
+	return p.totalLeavesCount
+}
+
 // proofLayers returns the proof layers by indices
 func (p Proof) proofLayers(leafIndices []uint64) Layers {
 
-	depth := treeDepth(p.totalLeavesCount)
-	proofLayers := make(Layers, depth)
-
-	// get uneven layers to remove any of uneven sibling indices in following loop
-	unevenLayers := unevenLayersCountMap(p.totalLeavesCount)
+	proofIndices := proofIndicesByLayers(leafIndices, p.totalLeavesCount)
+	proofLayers := make(Layers, len(proofIndices))
 
 	// copied proof index
 	lastProofIndex := 0
 
-	// loop through depth of tree and update proof indices
-	for layerIndex := uint64(0); layerIndex < depth; layerIndex++ {
-
-		// get siblings without last event index
-		siblingIndices := popLastEvenIndexFromSiblings(leafIndices, unevenLayers[layerIndex])
-
-		// append proof indices inot the result
-		proofNodesIndices := extractNewIndicesFromSiblings(siblingIndices, leafIndices)
-
-		// set the proof leaves from proof hashes
+	// loop through depth of tree and set the proof leaves from proof hashes
+	for layerIndex, proofNodesIndices := range proofIndices {
 		proofIndicesCount := len(proofNodesIndices)
 		proofLeaves := make(Leaves, proofIndicesCount)
 		for j := 0; j < proofIndicesCount; j++ {
@@ -122,47 +143,35 @@ func (p Proof) proofLayers(leafIndices []uint64) Layers {
 
 		// use proof indices and hash to set the layer leaves
 		proofLayers[layerIndex] = proofLeaves
-
-		// go one level up in leaves
-		leafIndices = parentIndecies(leafIndices)
 	}
 	return proofLayers
 }
 
-// popLastEvenIndex removes last uneven index from siblings
-func popLastEvenIndexFromSiblings(leafIndices []uint64, unevenLeavesCount uint64) []uint64 {
-
-	// get siblings
-	siblingIndices := siblingIndecies(leafIndices)
-
-	// remove from siblings if last node index is equal to eneven layer index
-	lastNodeIndex := leafIndices[len(leafIndices)-1]
-	if lastNodeIndex == unevenLeavesCount-1 {
-		siblingIndices = popFromIndexQueue(siblingIndices)
-	}
-
-	return siblingIndices
-}
-
-// unevenLayersCountMap returns map of layer indices that are not even
-func unevenLayersCountMap(totalLeavesCount uint64) map[uint64]uint64 {
+// proofIndicesByLayers returns the indices of the proof nodes of every layer of the tree for the given
+// sorted leaf indices
+func proofIndicesByLayers(leafIndices []uint64, totalLeavesCount uint64) [][]uint64 {
 
-	// set depth to prevent modification by loop
 	depth := treeDepth(totalLeavesCount)
+	proofIndices := make([][]uint64, depth)
 
-	unevenLayers := make(map[uint64]uint64)
+	// loop through depth of tree and update proof indices
+	layerSize := totalLeavesCount
+	for layerIndex := uint64(0); layerIndex < depth; layerIndex++ {
 
-	// loop until reach the full depth of tree
-	for i := uint64(0); i < depth; i++ {
+		// get the siblings which are not leaf indices themselves
+		siblingIndices := proofNodeIndices(leafIndices)
 
-		// if count is not even, append it to result
-		if !isEvenIndex(totalLeavesCount) {
-			unevenLayers[i] = totalLeavesCount
+		// the last node of an uneven layer has no sibling, it's promoted to the next layer instead
+		if count := len(siblingIndices); count > 0 && siblingIndices[count-1] >= layerSize {
+			siblingIndices = siblingIndices[:count-1]
 		}
 
-		// update the index to check and make it half
-		totalLeavesCount = uint64(math.Ceil(float64(totalLeavesCount) / halfDivider))
-	}
+		// append proof indices inot the result
+		proofIndices[layerIndex] = siblingIndices
 
-	return unevenLayers
+		// go one level up in leaves
+		leafIndices = parentIndecies(leafIndices)
+		layerSize = (layerSize + 1) / halfDivider
+	}
+	return proofIndices
 }
@@ -106,6 +106,32 @@ func BenchmarkProofRoot(b *testing.B) {
 	}
 }
 
+func TestEmptyProof(t *testing.T) {
+	require.Equal(t, uint64(0), treeDepth(0))
+
+	// the proof of the empty tree has nothing to verify
+	proof := NewProof(nil, nil, 0, hasher.Sha256Hasher{})
+	verified, err := proof.Verify([]byte{0})
+	require.ErrorIs(t, err, errEmptyTree)
+	require.False(t, verified)
+}
+
+func TestProofWithWrongHashesCount(t *testing.T) {
+	testData := setupTestData()
+	merkleTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(testData.leafHashes)
+	require.NoError(t, err)
+	proof := merkleTree.Proof([]uint64{1, 4})
+
+	for _, proofHashes := range [][][]byte{nil, proof.ProofHashes()[1:], append(proof.ProofHashes(), []byte{0})} {
+		_, err := NewProof(proof.Leaves(), proofHashes, proof.TotalLeavesCount(), hasher.Sha256Hasher{}).Root()
+		require.ErrorIs(t, err, errInvalidProofSize)
+		verified, err := NewProof(proof.Leaves(), proofHashes, proof.TotalLeavesCount(), hasher.Sha256Hasher{}).
+			Verify(merkleTree.Root())
+		require.ErrorIs(t, err, errInvalidProofSize)
+		require.False(t, verified)
+	}
+}
+
 var testAddresses = []string{
 	"9aF1Ca5941148eB6A3e9b9C741b69738292C533f",
 	"DD6ca953fddA25c496165D9040F7F77f75B75002",
@@ -0,0 +1,181 @@
+package merkle
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+// ProofUpdate is the witness that updates the proofs of a tree to the tree grown by the appended leaves. When the
+// leaves are appended, the siblings of the old leaves change only at the right edge of the old tree, so for every
+// layer below the root the update holds the nodes of the grown tree at the indices OldLeavesCount>>layer and the next
+// one. The other siblings are complete subtrees of the old tree and they are taken from the old proof.
+type ProofUpdate struct {
+	OldLeavesCount uint64
+	LeavesCount    uint64
+	// Nodes are the changed nodes of every layer of the grown tree from the bottom to the top, sorted by their indices
+	Nodes Layers
+}
+
+// Frontier is the right edge of a tree, the roots of its complete subtrees of the power of two sizes from the bottom
+// to the top, like the peaks of a merkle mountain range. It is enough to calculate the nodes of the tree that are
+// changed by the appended leaves, so a client that tracks the frontier updates its proofs without the server.
+type Frontier struct {
+	LeavesCount uint64
+	Nodes       [][]byte
+}
+
+// ProofUpdate returns the witness that updates the proofs made for the committed tree of the old number of leaves
+// to the committed tree
+func (t *Tree) ProofUpdate(oldLeavesCount uint64) (ProofUpdate, error) {
+	leavesCount := t.leavesLen()
+	if oldLeavesCount > leavesCount {
+		return ProofUpdate{}, errTreeSizeOutOfRange
+	}
+
+	update := ProofUpdate{OldLeavesCount: oldLeavesCount, LeavesCount: leavesCount}
+	if oldLeavesCount == leavesCount {
+		return update, nil
+	}
+
+	// the root is never a sibling
+	update.Nodes = make(Layers, t.depth())
+	for layerIndex := range update.Nodes {
+		first := oldLeavesCount >> uint(layerIndex)
+		for index := first; index <= first+1; index++ {
+			if hash, found := t.node(uint64(layerIndex), index); found {
+				update.Nodes[layerIndex] = append(update.Nodes[layerIndex], types.Leaf{Index: index, Hash: hash})
+			}
+		}
+	}
+	return update, nil
+}
+
+// Frontier returns the frontier of the committed tree
+func (t *Tree) Frontier() Frontier {
+	leavesCount := t.leavesLen()
+	frontier := Frontier{LeavesCount: leavesCount}
+	for layerIndex := 0; leavesCount>>uint(layerIndex) > 0; layerIndex++ {
+		if isOdd(leavesCount >> uint(layerIndex)) {
+			hash, _ := t.node(uint64(layerIndex), leavesCount>>uint(layerIndex)-1)
+			frontier.Nodes = append(frontier.Nodes, hash)
+		}
+	}
+	return frontier
+}
+
+// Append calculates the right edge of the tree grown by the appended leaves from the frontier. It returns the witness
+// that updates the proofs of the old tree and the frontier of the grown tree, so a client that knows the appended
+// leaves keeps its proofs up to date. An old proof alone is not enough, since it has only the hashes of the partial
+// subtrees at the right edge of the old tree, while the grown tree needs their complete subtrees.
+func (f Frontier) Append(h types.Hasher, leaves [][]byte) (ProofUpdate, Frontier, error) {
+	if len(f.Nodes) != bits.OnesCount64(f.LeavesCount) {
+		return ProofUpdate{}, Frontier{}, errInvalidProofSize
+	}
+
+	oldLeavesCount, leavesCount := f.LeavesCount, f.LeavesCount+uint64(len(leaves))
+	if oldLeavesCount == leavesCount {
+		return ProofUpdate{OldLeavesCount: oldLeavesCount, LeavesCount: leavesCount}, f, nil
+	}
+
+	// every layer of the grown tree is calculated from the frontier node of the layer and the parents of the layer
+	// below, they start at an even index, so the nodes are merged in pairs
+	depth := treeDepth(leavesCount)
+	layers := make(Layers, depth)
+	frontierNodes := f.Nodes
+	for layerIndex := range layers {
+		if isOdd(oldLeavesCount >> uint(layerIndex)) {
+			layers[layerIndex] = Leaves{{Index: oldLeavesCount>>uint(layerIndex) - 1, Hash: frontierNodes[0]}}
+			frontierNodes = frontierNodes[1:]
+		}
+	}
+	for i, leaf := range leaves {
+		layers[0] = append(layers[0], types.Leaf{Index: oldLeavesCount + uint64(i), Hash: leaf})
+	}
+
+	// the right edge is built like the uncommitted layers of a commit, by PartialTree.build which merges the sorted
+	// nodes of every layer with the parents of the layer below
+	partialTree := NewPartialTree(h)
+	partialTree, err := partialTree.build(layers, depth)
+	if err != nil {
+		return ProofUpdate{}, Frontier{}, err
+	}
+	grown := partialTree.getLayers()
+
+	update := ProofUpdate{OldLeavesCount: oldLeavesCount, LeavesCount: leavesCount, Nodes: make(Layers, depth)}
+	for layerIndex := range update.Nodes {
+		first := oldLeavesCount >> uint(layerIndex)
+		for index := first; index <= first+1; index++ {
+			if hash, found := findLayerNode(grown[layerIndex], index); found {
+				update.Nodes[layerIndex] = append(update.Nodes[layerIndex], types.Leaf{Index: index, Hash: hash})
+			}
+		}
+	}
+
+	frontier := Frontier{LeavesCount: leavesCount}
+	for layerIndex := 0; leavesCount>>uint(layerIndex) > 0; layerIndex++ {
+		if isOdd(leavesCount >> uint(layerIndex)) {
+			hash, _ := findLayerNode(grown[layerIndex], leavesCount>>uint(layerIndex)-1)
+			frontier.Nodes = append(frontier.Nodes, hash)
+		}
+	}
+	return update, frontier, nil
+}
+
+// Update returns the proof of the same leaves for the tree grown by the appended leaves. The siblings of the old tree
+// are taken from the proof and the changed ones from the update, the result is verified against the root of the grown
+// tree like any other proof.
+func (p Proof) Update(update ProofUpdate) (Proof, error) {
+	oldLeavesCount := p.totalLeavesCount
+	if oldLeavesCount == 0 {
+		return Proof{}, errEmptyTree
+	}
+	if update.OldLeavesCount != oldLeavesCount || update.LeavesCount < oldLeavesCount {
+		return Proof{}, errInvalidProofUpdate
+	}
+
+	leaves := make(Leaves, len(p.leaves))
+	copy(leaves, p.leaves)
+	sortLeavesAscending(leaves)
+	if err := validateProof(leaves, len(p.proofHashes), oldLeavesCount); err != nil {
+		return Proof{}, err
+	}
+	if update.LeavesCount == oldLeavesCount {
+		return NewProof(leaves, p.proofHashes, oldLeavesCount, p.hasher), nil
+	}
+
+	leafIndices := make([]uint64, len(leaves))
+	for i := 0; i < len(leaves); i++ {
+		leafIndices[i] = leaves[i].Index
+	}
+
+	oldLayers := p.proofLayers(leafIndices)
+	var proofHashes [][]byte
+	for layerIndex, proofIndices := range proofIndicesByLayers(leafIndices, update.LeavesCount) {
+		for _, index := range proofIndices {
+			// the complete subtrees of the old tree are not changed by the appended leaves
+			var hash []byte
+			var found bool
+			if index < oldLeavesCount>>uint(layerIndex) {
+				hash, found = findLayerNode(oldLayers[layerIndex], index)
+			} else if layerIndex < len(update.Nodes) {
+				hash, found = findLayerNode(update.Nodes[layerIndex], index)
+			}
+			if !found {
+				return Proof{}, errInvalidProofUpdate
+			}
+			proofHashes = append(proofHashes, hash)
+		}
+	}
+	return NewProof(leaves, proofHashes, update.LeavesCount, p.hasher), nil
+}
+
+// findLayerNode returns the hash of the node at the index of the layer sorted by the node indices
+func findLayerNode(layer Leaves, index uint64) ([]byte, bool) {
+	i := sort.Search(len(layer), func(i int) bool { return layer[i].Index >= index })
+	if i == len(layer) || layer[i].Index != index {
+		return nil, false
+	}
+	return layer[i].Hash, true
+}
@@ -0,0 +1,88 @@
+package merkle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+)
+
+func TestProofUpdate(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	leaves := keccakLeaves(t, 40)
+	for oldCount := 1; oldCount <= 17; oldCount++ {
+		oldTree, err := NewTree(h).FromLeaves(leaves[:oldCount])
+		require.NoError(t, err)
+
+		for count := oldCount; count <= oldCount+23; count++ {
+			tree, err := NewTree(h).FromLeaves(leaves[:count])
+			require.NoError(t, err)
+			update, err := tree.ProofUpdate(uint64(oldCount))
+			require.NoError(t, err)
+
+			// the frontier of the old tree calculates the same update from the appended leaves
+			appended, frontier, err := oldTree.Frontier().Append(h, leaves[oldCount:count])
+			require.NoError(t, err)
+			require.Equal(t, tree.Frontier(), frontier)
+			require.Equal(t, update, appended)
+
+			for _, indices := range [][]uint64{{0}, {uint64(oldCount - 1)}, {uint64(oldCount / 2)}, {0, uint64(oldCount - 1)}} {
+				proof, err := oldTree.Proof(indices).Update(update)
+				require.NoError(t, err)
+				require.Equal(t, tree.Proof(indices).ProofHashes(), proof.ProofHashes(), "%v of %d to %d", indices, oldCount, count)
+				require.Equal(t, uint64(count), proof.TotalLeavesCount())
+				verified, err := proof.Verify(tree.Root())
+				require.NoError(t, err)
+				require.True(t, verified, "%v of %d to %d", indices, oldCount, count)
+			}
+		}
+	}
+}
+
+func TestProofUpdateErrors(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	leaves := keccakLeaves(t, 12)
+	oldTree, err := NewTree(h).FromLeaves(leaves[:5])
+	require.NoError(t, err)
+	tree, err := NewTree(h).FromLeaves(leaves)
+	require.NoError(t, err)
+
+	_, err = oldTree.ProofUpdate(6)
+	require.ErrorIs(t, err, errTreeSizeOutOfRange)
+
+	update, err := tree.ProofUpdate(4)
+	require.NoError(t, err)
+	_, err = oldTree.Proof([]uint64{1}).Update(update)
+	require.ErrorIs(t, err, errInvalidProofUpdate)
+
+	// the changed node of a layer is missing
+	update, err = tree.ProofUpdate(5)
+	require.NoError(t, err)
+	update.Nodes[2] = update.Nodes[2][:0]
+	_, err = oldTree.Proof([]uint64{1}).Update(update)
+	require.ErrorIs(t, err, errInvalidProofUpdate)
+
+	proof := oldTree.Proof([]uint64{1})
+	proof.proofHashes = proof.proofHashes[1:]
+	_, err = proof.Update(update)
+	require.ErrorIs(t, err, errInvalidProofSize)
+
+	frontier := oldTree.Frontier()
+	require.Len(t, frontier.Nodes, 2)
+	frontier.Nodes = frontier.Nodes[1:]
+	_, _, err = frontier.Append(h, leaves[5:])
+	require.ErrorIs(t, err, errInvalidProofSize)
+
+	// the proofs of the empty tree have no leaves to update
+	_, err = NewProof(nil, nil, 0, h).Update(ProofUpdate{OldLeavesCount: 0, LeavesCount: 4})
+	require.ErrorIs(t, err, errEmptyTree)
+	_, err = oldTree.Proof(nil).Update(update)
+	require.ErrorIs(t, err, errInvalidProofLeaves)
+
+	// the frontier of the empty tree builds the tree from the leaves
+	update, frontier, err = Frontier{}.Append(h, leaves)
+	require.NoError(t, err)
+	require.Equal(t, tree.Frontier(), frontier)
+	require.Equal(t, uint64(12), update.LeavesCount)
+}
@@ -0,0 +1,228 @@
+package merkle
+
+import (
+	"bytes"
+	"math/bits"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+// NewRFC6962Tree creates a Certificate Transparency (RFC 6962 / RFC 9162) compatible merkle tree on top of the
+// given hasher, usually Sha256Hasher. Promoting the odd node of a layer builds the same tree shape as splitting
+// the leaves at the largest power of two smaller than their count, so only the hashing domains differ from the
+// regular tree: the inner nodes are hashed by the tree with the 0x01 prefix, while the leaves must be hashed with
+// hasher.RFC6962Hasher.HashLeaf (the 0x00 prefix) before they are added to the tree.
+func NewRFC6962Tree(h types.Hasher) Tree {
+	return NewTree(hasher.NewRFC6962Hasher(h))
+}
+
+// InclusionProof returns the audit path of the leaf at the given index as defined in RFC 6962 section 2.1.1. The
+// hashes are sorted from the bottom to the top of the tree.
+func (t *Tree) InclusionProof(index uint64) ([][]byte, error) {
+	leavesCount := t.leavesLen()
+	if index >= leavesCount {
+		return nil, errLeafIndexOutOfRange
+	}
+	return t.auditPath(index, 0, leavesCount)
+}
+
+// ConsistencyProof returns the consistency proof between the tree of prevLeavesCount leaves and the current tree
+// as defined in RFC 6962 section 2.1.2. The proof of a tree with itself is empty.
+func (t *Tree) ConsistencyProof(prevLeavesCount uint64) ([][]byte, error) {
+	leavesCount := t.leavesLen()
+	if prevLeavesCount == 0 || prevLeavesCount > leavesCount {
+		return nil, errTreeSizeOutOfRange
+	}
+	if prevLeavesCount == leavesCount {
+		return [][]byte{}, nil
+	}
+	return t.subProof(prevLeavesCount, 0, leavesCount, true)
+}
+
+// auditPath is the PATH(m, D[start:end]) function of RFC 6962
+func (t *Tree) auditPath(m, start, end uint64) ([][]byte, error) {
+	n := end - start
+	if n == 1 {
+		return [][]byte{}, nil
+	}
+
+	k := SplitPoint(n)
+	var path [][]byte
+	var sibling []byte
+	var err error
+	if m < k {
+		if path, err = t.auditPath(m, start, start+k); err != nil {
+			return nil, err
+		}
+		sibling, err = t.subtreeHash(start+k, end)
+	} else {
+		if path, err = t.auditPath(m-k, start+k, end); err != nil {
+			return nil, err
+		}
+		sibling, err = t.subtreeHash(start, start+k)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return append(path, sibling), nil
+}
+
+// subProof is the SUBPROOF(m, D[start:end], b) function of RFC 6962
+func (t *Tree) subProof(m, start, end uint64, completeSubtree bool) ([][]byte, error) {
+	n := end - start
+	if m == n {
+		if completeSubtree {
+			return [][]byte{}, nil
+		}
+		hash, err := t.subtreeHash(start, end)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{hash}, nil
+	}
+
+	k := SplitPoint(n)
+	var proof [][]byte
+	var sibling []byte
+	var err error
+	if m <= k {
+		if proof, err = t.subProof(m, start, start+k, completeSubtree); err != nil {
+			return nil, err
+		}
+		sibling, err = t.subtreeHash(start+k, end)
+	} else {
+		if proof, err = t.subProof(m-k, start+k, end, false); err != nil {
+			return nil, err
+		}
+		sibling, err = t.subtreeHash(start, start+k)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return append(proof, sibling), nil
+}
+
+// subtreeHash returns the hash of the subtree of leaves from start to end. Every subtree used by the RFC 6962
+// proofs starts at a multiple of its power of two size or ends at the last leaf, so it is a node of the tree.
+func (t *Tree) subtreeHash(start, end uint64) ([]byte, error) {
+	layerIndex := uint64(bits.Len64(end - start - 1))
+	hash, found := t.node(layerIndex, start>>layerIndex)
+	if !found {
+		return nil, errNotEnoughParentNodes
+	}
+
+	return hash, nil
+}
+
+// VerifyInclusion verifies the RFC 6962 audit path of the leaf hash at the given index in a tree of treeSize leaves
+// against the root, using the algorithm of RFC 9162 section 2.1.3.2. The hasher must be the one used to hash the
+// inner nodes of the tree, for example hasher.RFC6962Hasher.
+func VerifyInclusion(h types.Hasher, index, treeSize uint64, leafHash []byte, proof [][]byte, root []byte) (bool, error) {
+	if index >= treeSize {
+		return false, errLeafIndexOutOfRange
+	}
+
+	fn, sn := index, treeSize-1
+	r := leafHash
+	for i := 0; i < len(proof); i++ {
+		if sn == 0 {
+			return false, errInvalidProofSize
+		}
+
+		var err error
+		if isOdd(fn) || fn == sn {
+			if r, err = hasher.MergeAndHash(h, copyBytes(proof[i]), r); err != nil {
+				return false, err
+			}
+			for !isOdd(fn) && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else if r, err = hasher.MergeAndHash(h, copyBytes(r), proof[i]); err != nil {
+			return false, err
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+
+	if sn != 0 {
+		return false, errInvalidProofSize
+	}
+
+	return bytes.Equal(r, root), nil
+}
+
+// VerifyConsistency verifies the RFC 6962 consistency proof between the tree of prevTreeSize leaves with the root
+// prevRoot and the tree of treeSize leaves with the root, using the algorithm of RFC 9162 section 2.1.4.2. The hasher
+// must be the one used to hash the inner nodes of the tree, for example hasher.RFC6962Hasher.
+func VerifyConsistency(h types.Hasher, prevTreeSize, treeSize uint64, proof [][]byte, prevRoot, root []byte) (bool, error) {
+	switch {
+	case prevTreeSize == 0 || prevTreeSize > treeSize:
+		return false, errTreeSizeOutOfRange
+	case prevTreeSize == treeSize:
+		if len(proof) != 0 {
+			return false, errInvalidProofSize
+		}
+		return bytes.Equal(prevRoot, root), nil
+	}
+
+	// if the previous tree is a complete subtree of the current one, its root is the first node of the path
+	path := proof
+	if prevTreeSize&(prevTreeSize-1) == 0 {
+		path = append([][]byte{prevRoot}, proof...)
+	}
+	if len(path) == 0 {
+		return false, errInvalidProofSize
+	}
+
+	fn, sn := prevTreeSize-1, treeSize-1
+	for isOdd(fn) {
+		fn, sn = fn>>1, sn>>1
+	}
+
+	fr, sr := path[0], path[0]
+	for i := 1; i < len(path); i++ {
+		if sn == 0 {
+			return false, errInvalidProofSize
+		}
+
+		var err error
+		if isOdd(fn) || fn == sn {
+			if fr, err = hasher.MergeAndHash(h, copyBytes(path[i]), fr); err != nil {
+				return false, err
+			}
+			if sr, err = hasher.MergeAndHash(h, copyBytes(path[i]), sr); err != nil {
+				return false, err
+			}
+			for !isOdd(fn) && fn != 0 {
+				fn, sn = fn>>1, sn>>1
+			}
+		} else if sr, err = hasher.MergeAndHash(h, copyBytes(sr), path[i]); err != nil {
+			return false, err
+		}
+		fn, sn = fn>>1, sn>>1
+	}
+
+	if sn != 0 {
+		return false, errInvalidProofSize
+	}
+
+	return bytes.Equal(fr, prevRoot) && bytes.Equal(sr, root), nil
+}
+
+// SplitPoint returns the largest power of two smaller than n, the size of the left subtree of a tree of n leaves in the
+// RFC 6962 shape. n must be greater than one.
+func SplitPoint(n uint64) uint64 {
+	return 1 << (bits.Len64(n-1) - 1)
+}
+
+// isOdd returns true if the least significant bit of the number is set
+func isOdd(n uint64) bool {
+	return !isEvenIndex(n)
+}
+
+// copyBytes returns a copy of the slice, so appending to the copy never modifies the original backing array
+func copyBytes(b []byte) []byte {
+	return append([]byte{}, b...)
+}
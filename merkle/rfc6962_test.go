@@ -0,0 +1,235 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 6962 test vectors used by the certificate-transparency and trillian projects
+var (
+	rfc6962LeavesData = []string{
+		"", "00", "10", "2021", "3031", "40414243", "5051525354555657", "606162636465666768696a6b6c6d6e6f",
+	}
+	rfc6962Roots = []string{
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+	rfc6962InclusionProofs = []struct {
+		index    uint64
+		treeSize uint64
+		proof    []string
+	}{
+		{0, 1, []string{}},
+		{0, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{5, 8, []string{
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 3, []string{
+			"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		}},
+		{1, 5, []string{
+			"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	}
+	rfc6962ConsistencyProofs = []struct {
+		prevTreeSize uint64
+		treeSize     uint64
+		proof        []string
+	}{
+		{1, 1, []string{}},
+		{1, 8, []string{
+			"96a296d224f285c67bee93c30f8a309157f0daa35dc5b87e410b78630a09cfc7",
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"6b47aaf29ee3c2af9af889bc1fb9254dabd31177f16232dd6aab035ca39bf6e4",
+		}},
+		{6, 8, []string{
+			"0ebc5d3437fbe2db158b9f126a1d118e308181031d0a949f8dededebc558ef6a",
+			"ca854ea128ed050b41b35ffc1b87b8eb2bde461e9e3b5596ece6b9d5975a0ae0",
+			"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		}},
+		{2, 5, []string{
+			"5f083f0a1a33ca076a95279832580db3e0ef4584bdff1f54c8a360f50de3031e",
+			"bc1a0643b12e4d2d7c77918f44e0f4f79a838b6cf9ec5b5c283e1f4d88599e6b",
+		}},
+	}
+)
+
+func rfc6962LeafHashes(t *testing.T) [][]byte {
+	h := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{})
+	var leaves [][]byte
+	for _, v := range rfc6962LeavesData {
+		data, err := hex.DecodeString(v)
+		require.NoError(t, err)
+		leaf, err := h.HashLeaf(data)
+		require.NoError(t, err)
+		leaves = append(leaves, leaf)
+	}
+	return leaves
+}
+
+func rfc6962Tree(t *testing.T, leaves [][]byte) Tree {
+	tree, err := NewRFC6962Tree(hasher.Sha256Hasher{}).FromLeaves(leaves)
+	require.NoError(t, err)
+	return tree
+}
+
+func hexList(hashes [][]byte) []string {
+	list := make([]string, len(hashes))
+	for i := 0; i < len(hashes); i++ {
+		list[i] = hex.EncodeToString(hashes[i])
+	}
+	return list
+}
+
+func TestRFC6962Roots(t *testing.T) {
+	leaves := rfc6962LeafHashes(t)
+	for i := 0; i < len(leaves); i++ {
+		tree := rfc6962Tree(t, leaves[:i+1])
+		require.Equal(t, rfc6962Roots[i], tree.RootHex())
+	}
+
+	// the empty tree root is the hash of an empty string
+	emptyRoot, err := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{}).HashEmpty()
+	require.NoError(t, err)
+	require.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", hex.EncodeToString(emptyRoot))
+}
+
+func TestRFC6962InclusionProofVectors(t *testing.T) {
+	leaves := rfc6962LeafHashes(t)
+	nodeHasher := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{})
+
+	for _, c := range rfc6962InclusionProofs {
+		tree := rfc6962Tree(t, leaves[:c.treeSize])
+		proof, err := tree.InclusionProof(c.index)
+		require.NoError(t, err)
+		require.Equal(t, c.proof, hexList(proof))
+
+		verified, err := VerifyInclusion(nodeHasher, c.index, c.treeSize, leaves[c.index], proof, tree.Root())
+		require.NoError(t, err)
+		require.True(t, verified)
+
+		// the audit path is the proof hashes of the single leaf merkle proof
+		require.Equal(t, c.proof, tree.Proof([]uint64{c.index}).ProofHashesHex())
+	}
+}
+
+func TestRFC6962ConsistencyProofVectors(t *testing.T) {
+	leaves := rfc6962LeafHashes(t)
+	nodeHasher := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{})
+
+	for _, c := range rfc6962ConsistencyProofs {
+		tree := rfc6962Tree(t, leaves[:c.treeSize])
+		proof, err := tree.ConsistencyProof(c.prevTreeSize)
+		require.NoError(t, err)
+		require.Equal(t, c.proof, hexList(proof))
+
+		prevRoot, err := hex.DecodeString(rfc6962Roots[c.prevTreeSize-1])
+		require.NoError(t, err)
+		verified, err := VerifyConsistency(nodeHasher, c.prevTreeSize, c.treeSize, proof, prevRoot, tree.Root())
+		require.NoError(t, err)
+		require.True(t, verified)
+	}
+}
+
+func TestRFC6962AllProofs(t *testing.T) {
+	nodeHasher := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{})
+
+	var leaves [][]byte
+	var roots [][]byte
+	for i := 0; i < 33; i++ {
+		leaf, err := nodeHasher.HashLeaf([]byte{byte(i)})
+		require.NoError(t, err)
+		leaves = append(leaves, leaf)
+		tree := rfc6962Tree(t, leaves)
+		roots = append(roots, tree.Root())
+	}
+
+	for size := uint64(1); size <= uint64(len(leaves)); size++ {
+		tree := rfc6962Tree(t, leaves[:size])
+		root := roots[size-1]
+
+		for index := uint64(0); index < size; index++ {
+			proof, err := tree.InclusionProof(index)
+			require.NoError(t, err)
+
+			verified, err := VerifyInclusion(nodeHasher, index, size, leaves[index], proof, root)
+			require.NoError(t, err)
+			require.True(t, verified, "inclusion of %d in %d", index, size)
+
+			verified, _ = VerifyInclusion(nodeHasher, index, size, leaves[(index+1)%size], proof, root)
+			require.Equal(t, size == 1, verified, "inclusion of a wrong leaf %d in %d", index, size)
+		}
+
+		for prevSize := uint64(1); prevSize <= size; prevSize++ {
+			proof, err := tree.ConsistencyProof(prevSize)
+			require.NoError(t, err)
+
+			verified, err := VerifyConsistency(nodeHasher, prevSize, size, proof, roots[prevSize-1], root)
+			require.NoError(t, err)
+			require.True(t, verified, "consistency of %d and %d", prevSize, size)
+
+			if prevSize > 1 {
+				verified, _ = VerifyConsistency(nodeHasher, prevSize, size, proof, roots[prevSize-2], root)
+				require.False(t, verified, "consistency with a wrong root of %d and %d", prevSize, size)
+			}
+		}
+	}
+}
+
+func TestRFC6962InvalidProofs(t *testing.T) {
+	leaves := rfc6962LeafHashes(t)
+	nodeHasher := hasher.NewRFC6962Hasher(hasher.Sha256Hasher{})
+	tree := rfc6962Tree(t, leaves)
+	root := tree.Root()
+
+	_, err := tree.InclusionProof(8)
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+	_, err = tree.ConsistencyProof(0)
+	require.ErrorIs(t, err, errTreeSizeOutOfRange)
+	_, err = tree.ConsistencyProof(9)
+	require.ErrorIs(t, err, errTreeSizeOutOfRange)
+
+	proof, err := tree.InclusionProof(5)
+	require.NoError(t, err)
+
+	_, err = VerifyInclusion(nodeHasher, 5, 8, leaves[5], proof[:2], root)
+	require.ErrorIs(t, err, errInvalidProofSize)
+	_, err = VerifyInclusion(nodeHasher, 5, 8, leaves[5], append(proof, root), root)
+	require.ErrorIs(t, err, errInvalidProofSize)
+	_, err = VerifyInclusion(nodeHasher, 8, 8, leaves[5], proof, root)
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+
+	// the same tree built without domain separation has a different root
+	verified, err := VerifyInclusion(hasher.Sha256Hasher{}, 5, 8, leaves[5], proof, root)
+	require.NoError(t, err)
+	require.False(t, verified)
+
+	consistencyProof, err := tree.ConsistencyProof(6)
+	require.NoError(t, err)
+	prevRoot, err := hex.DecodeString(rfc6962Roots[5])
+	require.NoError(t, err)
+
+	_, err = VerifyConsistency(nodeHasher, 6, 8, consistencyProof[:1], prevRoot, root)
+	require.ErrorIs(t, err, errInvalidProofSize)
+	_, err = VerifyConsistency(nodeHasher, 8, 8, consistencyProof, root, root)
+	require.ErrorIs(t, err, errInvalidProofSize)
+	_, err = VerifyConsistency(nodeHasher, 9, 8, consistencyProof, prevRoot, root)
+	require.ErrorIs(t, err, errTreeSizeOutOfRange)
+}
@@ -1,28 +1,35 @@
 package merkle
 
-// popFromIndexQueue pops front element of uint64 slice
-func popFromIndexQueue(slice []uint64) []uint64 {
-	newSlice := slice[:len(slice)-1]
-	return newSlice
+import "sort"
 
+// mergeLeaves merges two slices of leaves sorted by their indices into a new sorted slice
+func mergeLeaves(left, right Leaves) Leaves {
+	merged := make(Leaves, 0, len(left)+len(right))
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		if right[j].Index < left[i].Index {
+			merged = append(merged, right[j])
+			j++
+		} else {
+			merged = append(merged, left[i])
+			i++
+		}
+	}
+	merged = append(merged, left[i:]...)
+	return append(merged, right[j:]...)
 }
 
-func sliceDifferences(slice1 []uint64, slice2 []uint64) []uint64 {
-	var diff []uint64
+// sortedUniqueIndices returns a sorted copy of the indices without the repeated ones
+func sortedUniqueIndices(indices []uint64) []uint64 {
+	sorted := make([]uint64, len(indices))
+	copy(sorted, indices)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
 
-	for i := 0; i < len(slice1); i++ {
-		found := false
-		for j := 0; j < len(slice2); j++ {
-			if slice1[i] == slice2[j] {
-				found = true
-				break
-			}
-		}
-		// String not found. We add it to return slice
-		if !found {
-			diff = append(diff, slice1[i])
+	var unique []uint64
+	for i := 0; i < len(sorted); i++ {
+		if i == 0 || sorted[i] != sorted[i-1] {
+			unique = append(unique, sorted[i])
 		}
 	}
-
-	return diff
+	return unique
 }
@@ -2,12 +2,52 @@ package merkle
 
 import (
 	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/stretchr/testify/require"
 )
 
-func BenchmarkDifference(b *testing.B) {
-	s1 := []uint64{11, 15, 84, 88888888, 999999999999999}
-	s2 := []uint64{11, 15, 1333, 7777777777, 999999999999999}
+func TestMergeLeaves(t *testing.T) {
+	left := Leaves{{Index: 1}, {Index: 4}, {Index: 5}}
+	right := Leaves{{Index: 0}, {Index: 2}, {Index: 9}}
+	require.Equal(t, Leaves{{Index: 0}, {Index: 1}, {Index: 2}, {Index: 4}, {Index: 5}, {Index: 9}}, mergeLeaves(left, right))
+	require.Equal(t, left, mergeLeaves(left, nil))
+	require.Equal(t, right, mergeLeaves(nil, right))
+	require.Empty(t, mergeLeaves(nil, nil))
+}
+
+func TestProofNodeIndices(t *testing.T) {
+	require.Equal(t, []uint64{7}, proofNodeIndices([]uint64{2, 3, 6}))
+	require.Equal(t, []uint64{1, 2, 5}, proofNodeIndices([]uint64{0, 3, 4}))
+	require.Equal(t, []uint64(nil), proofNodeIndices([]uint64{0, 1, 4, 5}))
+	require.Equal(t, []uint64(nil), proofNodeIndices(nil))
+}
+
+func TestSortedUniqueIndices(t *testing.T) {
+	indices := []uint64{5, 1, 5, 3, 1}
+	require.Equal(t, []uint64{1, 3, 5}, sortedUniqueIndices(indices))
+	require.Equal(t, []uint64{5, 1, 5, 3, 1}, indices)
+}
+
+func BenchmarkMergeLeaves(b *testing.B) {
+	var left, right Leaves
+	for i := uint64(0); i < 1<<16; i++ {
+		left = append(left, types.Leaf{Index: 2 * i})
+		right = append(right, types.Leaf{Index: 2*i + 1})
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		mergeLeaves(left, right)
+	}
+}
+
+func BenchmarkProofNodeIndices(b *testing.B) {
+	var indices []uint64
+	for i := uint64(0); i < 1<<16; i++ {
+		indices = append(indices, 3*i)
+	}
+	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		sliceDifferences(s1, s2)
+		proofNodeIndices(indices)
 	}
 }
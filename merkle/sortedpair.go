@@ -0,0 +1,295 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// SortedPairTree is a merkle tree compatible with the MerkleProof library and the StandardMerkleTree of OpenZeppelin.
+// The pair of children of every inner node is sorted before it is hashed, so the proofs don't need the position of
+// the proven leaves. The nodes are kept in the array layout of OpenZeppelin, where the children of the node i are the
+// nodes 2i+1 and 2i+2 and the leaves are stored at the end of the array in the reversed order.
+type SortedPairTree struct {
+	nodes [][]byte
+	// treeIndices maps the indices of the leaves passed to the constructor to their node indices
+	treeIndices []uint64
+}
+
+// SortedPairMultiProof is a multiproof in the layout of MerkleProof.multiProofVerify of OpenZeppelin. The leaves are
+// sorted in the order the verifier consumes them, their indices are the indices passed to the tree constructor.
+type SortedPairMultiProof struct {
+	Leaves     Leaves
+	Proof      [][]byte
+	ProofFlags []bool
+}
+
+// NewSortedPairTree builds a sorted pair tree of the leaf hashes with the hasher, usually Keccak256Hasher. When
+// sortLeaves is true the leaves are sorted by their hashes first, as StandardMerkleTree.of and SimpleMerkleTree.of
+// of OpenZeppelin do by default, which is required to get the same roots.
+func NewSortedPairTree(h types.Hasher, leaves [][]byte, sortLeaves bool) (SortedPairTree, error) {
+	if len(leaves) == 0 {
+		return SortedPairTree{}, errEmptyTree
+	}
+
+	order := make([]int, len(leaves))
+	for i := range order {
+		order[i] = i
+	}
+	if sortLeaves {
+		sort.SliceStable(order, func(i, j int) bool {
+			return bytes.Compare(leaves[order[i]], leaves[order[j]]) < 0
+		})
+	}
+
+	t := SortedPairTree{
+		nodes:       make([][]byte, halfDivider*len(leaves)-1),
+		treeIndices: make([]uint64, len(leaves)),
+	}
+	for i, leafIndex := range order {
+		treeIndex := len(t.nodes) - 1 - i
+		t.nodes[treeIndex] = leaves[leafIndex]
+		t.treeIndices[leafIndex] = uint64(treeIndex)
+	}
+
+	for i := len(t.nodes) - 1 - len(leaves); i >= 0; i-- {
+		hash, err := hashSortedPair(h, t.nodes[halfDivider*i+1], t.nodes[halfDivider*i+2])
+		if err != nil {
+			return SortedPairTree{}, err
+		}
+		t.nodes[i] = hash
+	}
+
+	return t, nil
+}
+
+// NewStandardMerkleTree builds the tree of StandardMerkleTree.of(values, leafEncoding) of OpenZeppelin, the leaves
+// are hashed with StandardLeafHash and sorted, and the nodes are hashed with Keccak256Hasher.
+func NewStandardMerkleTree(leafEncoding []string, values [][]interface{}) (SortedPairTree, error) {
+	leaves := make([][]byte, len(values))
+	for i := range values {
+		leaf, err := StandardLeafHash(leafEncoding, values[i])
+		if err != nil {
+			return SortedPairTree{}, err
+		}
+		leaves[i] = leaf
+	}
+	return NewSortedPairTree(hasher.Keccak256Hasher{}, leaves, true)
+}
+
+// StandardLeafHash returns the leaf hash of the StandardMerkleTree of OpenZeppelin, which is
+// keccak256(keccak256(abi.encode(values))). The leaf encoding is the list of the Solidity types of the values, and the
+// values are the Go types of the go-ethereum abi package, for example common.Address for address and *big.Int for
+// uint256. Hashing the encoding twice prevents a leaf from being passed off as an inner node of 64 bytes.
+func StandardLeafHash(leafEncoding []string, values []interface{}) ([]byte, error) {
+	args := make(abi.Arguments, len(leafEncoding))
+	for i, typeName := range leafEncoding {
+		typ, err := abi.NewType(typeName, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = abi.Argument{Type: typ}
+	}
+
+	encoded, err := args.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hasher.Keccak256Hasher{}
+	hash, err := h.Hash(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return h.Hash(hash)
+}
+
+// Root returns the root of the tree
+func (t SortedPairTree) Root() []byte {
+	if len(t.nodes) == 0 {
+		return nil
+	}
+	return t.nodes[0]
+}
+
+// RootHex returns the hex encoded root of the tree
+func (t SortedPairTree) RootHex() string {
+	return hex.EncodeToString(t.Root())
+}
+
+// LeavesLen returns the number of the leaves of the tree
+func (t SortedPairTree) LeavesLen() uint64 {
+	return uint64(len(t.treeIndices))
+}
+
+// Proof returns the proof of the leaf at the given index for MerkleProof.verify of OpenZeppelin, the sibling hashes
+// from the bottom to the top of the tree
+func (t SortedPairTree) Proof(index uint64) ([][]byte, error) {
+	if index >= t.LeavesLen() {
+		return nil, errLeafIndexOutOfRange
+	}
+
+	proof := [][]byte{}
+	for i := t.treeIndices[index]; i > 0; i = sortedPairParent(i) {
+		proof = append(proof, t.nodes[sortedPairSibling(i)])
+	}
+	return proof, nil
+}
+
+// MultiProof returns the multiproof of the leaves at the given indices for MerkleProof.multiProofVerify of
+// OpenZeppelin, the indices must be unique
+func (t SortedPairTree) MultiProof(indices []uint64) (SortedPairMultiProof, error) {
+	// the nodes are processed from the end of the array, that is from the first leaf of the bottom layer
+	stack := make([]uint64, len(indices))
+	for i, index := range indices {
+		if index >= t.LeavesLen() {
+			return SortedPairMultiProof{}, errLeafIndexOutOfRange
+		}
+		stack[i] = t.treeIndices[index]
+	}
+	sort.Slice(stack, func(i, j int) bool { return stack[i] > stack[j] })
+	for i := 1; i < len(stack); i++ {
+		if stack[i] == stack[i-1] {
+			return SortedPairMultiProof{}, errInvalidProofLeaves
+		}
+	}
+
+	leafIndices := make(map[uint64]uint64, len(indices))
+	for _, index := range indices {
+		leafIndices[t.treeIndices[index]] = index
+	}
+	multiProof := SortedPairMultiProof{
+		Leaves:     make(Leaves, len(stack)),
+		Proof:      [][]byte{},
+		ProofFlags: []bool{},
+	}
+	for i, treeIndex := range stack {
+		multiProof.Leaves[i] = types.Leaf{Index: leafIndices[treeIndex], Hash: t.nodes[treeIndex]}
+	}
+
+	for len(stack) > 0 && stack[0] > 0 {
+		j := stack[0]
+		stack = stack[1:]
+		sibling := sortedPairSibling(j)
+		if len(stack) > 0 && stack[0] == sibling {
+			// the sibling is known by the verifier, it's a proven leaf or a computed node
+			multiProof.ProofFlags = append(multiProof.ProofFlags, true)
+			stack = stack[1:]
+		} else {
+			multiProof.ProofFlags = append(multiProof.ProofFlags, false)
+			multiProof.Proof = append(multiProof.Proof, t.nodes[sibling])
+		}
+		stack = append(stack, sortedPairParent(j))
+	}
+
+	if len(indices) == 0 {
+		multiProof.Proof = append(multiProof.Proof, t.Root())
+	}
+	return multiProof, nil
+}
+
+// VerifySortedPairProof verifies the proof of the leaf hash against the root, like MerkleProof.verify of OpenZeppelin
+func VerifySortedPairProof(h types.Hasher, leafHash []byte, proof [][]byte, root []byte) (bool, error) {
+	computed := leafHash
+	for i := 0; i < len(proof); i++ {
+		var err error
+		if computed, err = hashSortedPair(h, computed, proof[i]); err != nil {
+			return false, err
+		}
+	}
+	return bytes.Equal(computed, root), nil
+}
+
+// VerifySortedPairMultiProof verifies the multiproof against the root, like MerkleProof.multiProofVerify of
+// OpenZeppelin
+func VerifySortedPairMultiProof(h types.Hasher, multiProof SortedPairMultiProof, root []byte) (bool, error) {
+	computed, err := multiProof.Root(h)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(computed, root), nil
+}
+
+// Root returns the root computed from the leaves and the proof, like MerkleProof.processMultiProof of OpenZeppelin.
+// Every flag tells if the second hash of the pair is the next proven leaf or computed hash, or the next proof hash.
+func (p SortedPairMultiProof) Root(h types.Hasher) ([]byte, error) {
+	leavesLen, proofLen, flagsLen := len(p.Leaves), len(p.Proof), len(p.ProofFlags)
+	if leavesLen+proofLen != flagsLen+1 {
+		return nil, errInvalidProofSize
+	}
+
+	hashes := make([][]byte, flagsLen)
+	var leafPos, hashPos, proofPos int
+	next := func() ([]byte, error) {
+		if leafPos < leavesLen {
+			leafPos++
+			return p.Leaves[leafPos-1].Hash, nil
+		}
+		// a computed hash is only available after it's computed
+		if hashPos >= len(hashes) || hashes[hashPos] == nil {
+			return nil, errInvalidProofSize
+		}
+		hashPos++
+		return hashes[hashPos-1], nil
+	}
+
+	for i := 0; i < flagsLen; i++ {
+		a, err := next()
+		if err != nil {
+			return nil, err
+		}
+		var b []byte
+		if p.ProofFlags[i] {
+			if b, err = next(); err != nil {
+				return nil, err
+			}
+		} else {
+			if proofPos >= proofLen {
+				return nil, errInvalidProofSize
+			}
+			b = p.Proof[proofPos]
+			proofPos++
+		}
+		if hashes[i], err = hashSortedPair(h, a, b); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case flagsLen > 0:
+		if proofPos != proofLen {
+			return nil, errInvalidProofSize
+		}
+		return hashes[flagsLen-1], nil
+	case leavesLen > 0:
+		return p.Leaves[0].Hash, nil
+	default:
+		return p.Proof[0], nil
+	}
+}
+
+// hashSortedPair hashes the concatenation of the two hashes in the ascending order
+func hashSortedPair(h types.Hasher, a, b []byte) ([]byte, error) {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	pair := make([]byte, 0, len(a)+len(b))
+	return h.Hash(append(append(pair, a...), b...))
+}
+
+// sortedPairParent returns the index of the parent of the node in the array layout of the sorted pair tree
+func sortedPairParent(i uint64) uint64 {
+	return (i - 1) / halfDivider
+}
+
+// sortedPairSibling returns the index of the sibling of the node in the array layout of the sorted pair tree
+func sortedPairSibling(i uint64) uint64 {
+	if isOdd(i) {
+		return i + 1
+	}
+	return i - 1
+}
@@ -0,0 +1,149 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandardMerkleTree(t *testing.T) {
+	// the example of the README of @openzeppelin/merkle-tree
+	first, _ := new(big.Int).SetString("5000000000000000000", 10)
+	second, _ := new(big.Int).SetString("2500000000000000000", 10)
+	values := [][]interface{}{
+		{common.HexToAddress("0x1111111111111111111111111111111111111111"), first},
+		{common.HexToAddress("0x2222222222222222222222222222222222222222"), second},
+	}
+
+	tree, err := NewStandardMerkleTree([]string{"address", "uint256"}, values)
+	require.NoError(t, err)
+	require.Equal(t, "d4dee0beab2d53f2cc83e567171bd2820e49898130a22622b10ead383e90bd77", tree.RootHex())
+
+	proof, err := tree.Proof(0)
+	require.NoError(t, err)
+	require.Len(t, proof, 1)
+	require.Equal(t, "b92c48e9d7abe27fd8dfd6b5dfdbfb1c9a463f80c712b66f3a5180a090cccafc", hex.EncodeToString(proof[0]))
+
+	leaf, err := StandardLeafHash([]string{"address", "uint256"}, values[0])
+	require.NoError(t, err)
+	verified, err := VerifySortedPairProof(hasher.Keccak256Hasher{}, leaf, proof, tree.Root())
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	_, err = StandardLeafHash([]string{"address", "uint256"}, values[0][:1])
+	require.Error(t, err)
+	_, err = StandardLeafHash([]string{"bool"}, values[0][1:])
+	require.Error(t, err)
+}
+
+func TestSortedPairTreeLayout(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	leaves := keccakLeaves(t, 3)
+
+	// the sorted leaves a, b and c are the nodes 4, 3 and 2, the node 1 is the parent of a and b
+	sorted, err := NewSortedPairTree(h, leaves, true)
+	require.NoError(t, err)
+	a, b, c := sorted.nodes[4], sorted.nodes[3], sorted.nodes[2]
+	require.Negative(t, bytes.Compare(a, b))
+	require.Negative(t, bytes.Compare(b, c))
+	ab, err := hashSortedPair(h, b, a)
+	require.NoError(t, err)
+	root, err := hashSortedPair(h, ab, c)
+	require.NoError(t, err)
+	require.Equal(t, root, sorted.Root())
+
+	// without sorting the first leaf is the last node
+	unsorted, err := NewSortedPairTree(h, leaves, false)
+	require.NoError(t, err)
+	require.Equal(t, leaves[0], unsorted.nodes[4])
+	require.Equal(t, leaves[2], unsorted.nodes[2])
+
+	// the hashes of a pair are sorted
+	ba, err := h.Hash(append(append([]byte{}, b...), a...))
+	require.NoError(t, err)
+	require.NotEqual(t, ba, ab)
+
+	_, err = NewSortedPairTree(h, nil, true)
+	require.ErrorIs(t, err, errEmptyTree)
+}
+
+func TestSortedPairProofs(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	for size := 1; size <= 9; size++ {
+		leaves := keccakLeaves(t, size)
+		tree, err := NewSortedPairTree(h, leaves, true)
+		require.NoError(t, err)
+
+		for i := range leaves {
+			proof, err := tree.Proof(uint64(i))
+			require.NoError(t, err)
+			verified, err := VerifySortedPairProof(h, leaves[i], proof, tree.Root())
+			require.NoError(t, err)
+			require.True(t, verified, "size %d leaf %d", size, i)
+
+			verified, err = VerifySortedPairProof(h, leaves[(i+1)%size], proof, tree.Root())
+			require.NoError(t, err)
+			require.Equal(t, size == 1, verified, "size %d leaf %d", size, i)
+		}
+
+		// every subset of the leaves, including the empty one
+		for subset := 0; subset < 1<<size; subset++ {
+			var indices []uint64
+			for i := size - 1; i >= 0; i-- {
+				if subset&(1<<i) != 0 {
+					indices = append(indices, uint64(i))
+				}
+			}
+
+			multiProof, err := tree.MultiProof(indices)
+			require.NoError(t, err)
+			require.Len(t, multiProof.Leaves, len(indices))
+			for _, leaf := range multiProof.Leaves {
+				require.Equal(t, leaves[leaf.Index], leaf.Hash)
+			}
+			verified, err := VerifySortedPairMultiProof(h, multiProof, tree.Root())
+			require.NoError(t, err)
+			require.True(t, verified, "size %d subset %b", size, subset)
+
+			if len(multiProof.Proof) > 0 {
+				multiProof.Proof[0] = leaves[0][1:]
+				verified, err = VerifySortedPairMultiProof(h, multiProof, tree.Root())
+				require.NoError(t, err)
+				require.False(t, verified, "size %d subset %b", size, subset)
+			}
+		}
+	}
+}
+
+func TestSortedPairInvalidProofs(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	leaves := keccakLeaves(t, 5)
+	tree, err := NewSortedPairTree(h, leaves, true)
+	require.NoError(t, err)
+
+	_, err = tree.Proof(5)
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+	_, err = tree.MultiProof([]uint64{1, 5})
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+	_, err = tree.MultiProof([]uint64{1, 1})
+	require.ErrorIs(t, err, errInvalidProofLeaves)
+
+	multiProof, err := tree.MultiProof([]uint64{0, 3})
+	require.NoError(t, err)
+
+	// the number of the flags doesn't match the leaves and the proof
+	invalid := multiProof
+	invalid.ProofFlags = append([]bool{false}, multiProof.ProofFlags...)
+	_, err = VerifySortedPairMultiProof(h, invalid, tree.Root())
+	require.ErrorIs(t, err, errInvalidProofSize)
+
+	// a flag that uses a hash which is not computed yet
+	invalid = SortedPairMultiProof{Leaves: multiProof.Leaves[:1], Proof: [][]byte{leaves[1]}, ProofFlags: []bool{true}}
+	_, err = VerifySortedPairMultiProof(h, invalid, tree.Root())
+	require.ErrorIs(t, err, errInvalidProofSize)
+}
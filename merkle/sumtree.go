@@ -0,0 +1,190 @@
+package merkle
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// Uint64SumSize is the size of the sums of the trees of uint64 balances in bytes
+	Uint64SumSize = 8
+	// Uint256SumSize is the size of the sums of the trees of uint256 balances in bytes
+	Uint256SumSize = 32
+
+	bitsInByte = 8
+)
+
+// SumNode is a node of a merkle sum tree, the hash of the node and the sum of the balances of the leaves of its
+// subtree. The hash of a leaf usually commits to the account, for example the hash of a user id and a nonce.
+type SumNode struct {
+	Hash []byte
+	Sum  *big.Int
+}
+
+// NewSumLeaf creates the leaf of the account hash and its uint64 balance
+func NewSumLeaf(hash []byte, balance uint64) SumNode {
+	return SumNode{Hash: hash, Sum: new(big.Int).SetUint64(balance)}
+}
+
+// SumTree is a merkle sum tree for the proofs of liabilities. The hash of an inner node is the hash of the hashes and
+// the sums of its children, and its sum is the sum of the children sums, so the root commits to the total of the
+// balances. The sums are encoded as big endian integers of the sum size and a sum that is negative or doesn't fit in
+// the sum size is rejected. The last node of an odd layer is promoted to the upper layer like in Tree.
+type SumTree struct {
+	hasher  types.Hasher
+	sumSize int
+	// layers are the nodes of the tree from the leaves to the root
+	layers [][]SumNode
+}
+
+// SumProof is the inclusion proof of a leaf of a merkle sum tree, the sibling nodes and their sums from the bottom to
+// the top of the tree
+type SumProof struct {
+	Index       uint64
+	LeavesCount uint64
+	Siblings    []SumNode
+}
+
+// NewSumTree creates a merkle sum tree of the leaves, the sum size is the size of the encoded sums in bytes
+func NewSumTree(h types.Hasher, sumSize int, leaves []SumNode) (*SumTree, error) {
+	if len(leaves) == 0 {
+		return nil, errEmptyTree
+	}
+	for _, leaf := range leaves {
+		if err := checkSum(leaf.Sum, sumSize); err != nil {
+			return nil, err
+		}
+	}
+
+	t := &SumTree{hasher: h, sumSize: sumSize, layers: [][]SumNode{leaves}}
+	for layer := leaves; len(layer) > 1; {
+		parents := make([]SumNode, 0, (len(layer)+1)/halfDivider)
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 == len(layer) {
+				parents = append(parents, layer[i])
+				continue
+			}
+			parent, err := hashSumPair(h, sumSize, layer[i], layer[i+1])
+			if err != nil {
+				return nil, err
+			}
+			parents = append(parents, parent)
+		}
+		t.layers = append(t.layers, parents)
+		layer = parents
+	}
+	return t, nil
+}
+
+// Root returns the root of the tree, its sum is the total of the balances
+func (t *SumTree) Root() SumNode {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// LeavesLen returns the number of the leaves
+func (t *SumTree) LeavesLen() uint64 {
+	return uint64(len(t.layers[0]))
+}
+
+// Proof returns the inclusion proof of the leaf of the index
+func (t *SumTree) Proof(index uint64) (SumProof, error) {
+	if index >= t.LeavesLen() {
+		return SumProof{}, errLeafIndexOutOfRange
+	}
+
+	proof := SumProof{Index: index, LeavesCount: t.LeavesLen()}
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		if sibling := siblingIndex(index); sibling < uint64(len(layer)) {
+			proof.Siblings = append(proof.Siblings, layer[sibling])
+		}
+		index /= halfDivider
+	}
+	return proof, nil
+}
+
+// Root calculates the root of the tree from the leaf and the siblings. It returns an error if any of the sums is
+// negative or doesn't fit in the sum size.
+func (p SumProof) Root(h types.Hasher, sumSize int, leaf SumNode) (SumNode, error) {
+	if p.Index >= p.LeavesCount {
+		return SumNode{}, errLeafIndexOutOfRange
+	}
+	if err := checkSum(leaf.Sum, sumSize); err != nil {
+		return SumNode{}, err
+	}
+
+	node, siblings := leaf, p.Siblings
+	for index, count := p.Index, p.LeavesCount; count > 1; index, count = index/halfDivider, (count+1)/halfDivider {
+		// the last node of an odd layer has no sibling
+		if isEvenIndex(index) && index+1 == count {
+			continue
+		}
+		if len(siblings) == 0 {
+			return SumNode{}, errInvalidProofSize
+		}
+
+		var err error
+		if isEvenIndex(index) {
+			node, err = hashSumPair(h, sumSize, node, siblings[0])
+		} else {
+			node, err = hashSumPair(h, sumSize, siblings[0], node)
+		}
+		if err != nil {
+			return SumNode{}, err
+		}
+		siblings = siblings[1:]
+	}
+
+	if len(siblings) != 0 {
+		return SumNode{}, errInvalidProofSize
+	}
+	return node, nil
+}
+
+// Verify verifies the inclusion of the leaf in the tree of the root, both the root hash and the total must match
+func (p SumProof) Verify(h types.Hasher, sumSize int, leaf, root SumNode) (bool, error) {
+	calculated, err := p.Root(h, sumSize, leaf)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(calculated.Hash, root.Hash) && root.Sum != nil && calculated.Sum.Cmp(root.Sum) == 0, nil
+}
+
+// hashSumPair returns the parent node of the left and the right nodes, the hash of their hashes and encoded sums and
+// the sum of their sums
+func hashSumPair(h types.Hasher, sumSize int, left, right SumNode) (SumNode, error) {
+	if err := checkSum(left.Sum, sumSize); err != nil {
+		return SumNode{}, err
+	}
+	if err := checkSum(right.Sum, sumSize); err != nil {
+		return SumNode{}, err
+	}
+	sum := new(big.Int).Add(left.Sum, right.Sum)
+	if err := checkSum(sum, sumSize); err != nil {
+		return SumNode{}, err
+	}
+
+	data := make([]byte, 0, len(left.Hash)+len(right.Hash)+2*sumSize)
+	data = append(append(data, left.Hash...), left.Sum.FillBytes(make([]byte, sumSize))...)
+	data = append(append(data, right.Hash...), right.Sum.FillBytes(make([]byte, sumSize))...)
+	hash, err := h.Hash(data)
+	if err != nil {
+		return SumNode{}, err
+	}
+	return SumNode{Hash: hash, Sum: sum}, nil
+}
+
+// checkSum returns an error if the sum is missing, negative or doesn't fit in the sum size
+func checkSum(sum *big.Int, sumSize int) error {
+	if sumSize <= 0 {
+		return errInvalidSumSize
+	}
+	if sum == nil || sum.Sign() < 0 {
+		return errNegativeSum
+	}
+	if sum.BitLen() > sumSize*bitsInByte {
+		return errSumOverflow
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package merkle
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+)
+
+func sumLeaves(b testing.TB, count int) []SumNode {
+	leaves := make([]SumNode, count)
+	for i, hash := range keccakLeaves(b, count) {
+		leaves[i] = NewSumLeaf(hash, uint64(i+1)*100)
+	}
+	return leaves
+}
+
+func TestSumTreeRoot(t *testing.T) {
+	h := hasher.Sha256Hasher{}
+	leaves := sumLeaves(t, 3)
+	tree, err := NewSumTree(h, Uint64SumSize, leaves)
+	require.NoError(t, err)
+
+	// the hash of a parent is the hash of the children hashes followed by their big endian uint64 sums
+	data := append(append([]byte{}, leaves[0].Hash...), 0, 0, 0, 0, 0, 0, 0, 100)
+	data = append(append(data, leaves[1].Hash...), 0, 0, 0, 0, 0, 0, 0, 200)
+	left, err := h.Hash(data)
+	require.NoError(t, err)
+	data = append(append([]byte{}, left...), 0, 0, 0, 0, 0, 0, 1, 44)
+	data = append(append(data, leaves[2].Hash...), 0, 0, 0, 0, 0, 0, 1, 44)
+	root, err := h.Hash(data)
+	require.NoError(t, err)
+
+	require.Equal(t, root, tree.Root().Hash)
+	require.Equal(t, big.NewInt(600), tree.Root().Sum)
+	require.Equal(t, uint64(3), tree.LeavesLen())
+
+	_, err = NewSumTree(h, Uint64SumSize, nil)
+	require.ErrorIs(t, err, errEmptyTree)
+	_, err = NewSumTree(h, 0, leaves)
+	require.ErrorIs(t, err, errInvalidSumSize)
+	_, err = NewSumTree(h, Uint64SumSize, []SumNode{leaves[0], {Hash: leaves[1].Hash, Sum: big.NewInt(-1)}})
+	require.ErrorIs(t, err, errNegativeSum)
+	_, err = NewSumTree(h, Uint64SumSize, []SumNode{leaves[0], {Hash: leaves[1].Hash}})
+	require.ErrorIs(t, err, errNegativeSum)
+}
+
+func TestSumTreeOverflow(t *testing.T) {
+	h := hasher.Sha256Hasher{}
+	leaves := sumLeaves(t, 2)
+	leaves[1] = NewSumLeaf(leaves[1].Hash, math.MaxUint64)
+
+	_, err := NewSumTree(h, Uint64SumSize, leaves)
+	require.ErrorIs(t, err, errSumOverflow)
+
+	tree, err := NewSumTree(h, Uint256SumSize, leaves)
+	require.NoError(t, err)
+	total := new(big.Int).Add(new(big.Int).SetUint64(math.MaxUint64), big.NewInt(100))
+	require.Equal(t, total, tree.Root().Sum)
+
+	// a proof can't inflate the total over the sum size
+	proof, err := tree.Proof(0)
+	require.NoError(t, err)
+	_, err = proof.Verify(h, Uint64SumSize, leaves[0], tree.Root())
+	require.ErrorIs(t, err, errSumOverflow)
+
+	proof.Siblings[0].Sum = big.NewInt(-100)
+	_, err = proof.Verify(h, Uint256SumSize, leaves[0], tree.Root())
+	require.ErrorIs(t, err, errNegativeSum)
+}
+
+func TestSumTreeProofs(t *testing.T) {
+	h := hasher.Keccak256Hasher{}
+	for count := 1; count <= 17; count++ {
+		leaves := sumLeaves(t, count)
+		tree, err := NewSumTree(h, Uint64SumSize, leaves)
+		require.NoError(t, err)
+		root := tree.Root()
+		require.Equal(t, big.NewInt(int64(count*(count+1)/2*100)), root.Sum)
+
+		for i := range leaves {
+			proof, err := tree.Proof(uint64(i))
+			require.NoError(t, err)
+			verified, err := proof.Verify(h, Uint64SumSize, leaves[i], root)
+			require.NoError(t, err)
+			require.True(t, verified, "leaf %d of %d", i, count)
+
+			// the balance of the leaf is committed by the root
+			tampered := NewSumLeaf(leaves[i].Hash, leaves[i].Sum.Uint64()-1)
+			verified, err = proof.Verify(h, Uint64SumSize, tampered, root)
+			require.NoError(t, err)
+			require.False(t, verified, "leaf %d of %d", i, count)
+
+			if len(proof.Siblings) == 0 {
+				continue
+			}
+			// moving a balance from a sibling to the leaf keeps the total but not the root hash
+			sibling := proof.Siblings[0]
+			proof.Siblings[0] = SumNode{Hash: sibling.Hash, Sum: new(big.Int).Sub(sibling.Sum, big.NewInt(1))}
+			tampered = NewSumLeaf(leaves[i].Hash, leaves[i].Sum.Uint64()+1)
+			verified, err = proof.Verify(h, Uint64SumSize, tampered, root)
+			require.NoError(t, err)
+			require.False(t, verified, "leaf %d of %d", i, count)
+
+			proof.Siblings = proof.Siblings[1:]
+			_, err = proof.Verify(h, Uint64SumSize, leaves[i], root)
+			require.ErrorIs(t, err, errInvalidProofSize)
+		}
+
+		_, err = tree.Proof(uint64(count))
+		require.ErrorIs(t, err, errLeafIndexOutOfRange)
+	}
+}
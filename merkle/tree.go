@@ -2,21 +2,30 @@
 package merkle
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
 	"math"
 	"sort"
 
+	"github.com/ComposableFi/go-merkle-trees/hasher"
 	"github.com/ComposableFi/go-merkle-trees/types"
 )
 
 // FromLeaves clones the leaves and builds the tree from them
 func (t Tree) FromLeaves(leaves [][]byte) (Tree, error) {
+	return t.FromLeavesContext(context.Background(), leaves)
+}
+
+// FromLeavesContext clones the leaves and builds the tree from them like FromLeaves. It stops building the tree and
+// returns the context error when the context is done.
+func (t Tree) FromLeavesContext(ctx context.Context, leaves [][]byte) (Tree, error) {
 
 	// populate initial tree leaves
-	t.append(leaves)
+	t.Append(leaves)
 
 	// create tree
-	err := t.commit()
+	err := t.CommitContext(ctx)
 	if err != nil {
 		return Tree{}, err
 	}
@@ -25,18 +34,21 @@ func (t Tree) FromLeaves(leaves [][]byte) (Tree, error) {
 	return t, nil
 }
 
+// FromData builds the tree from the leaf data hashed by hasher.HashLeaf with the tree hasher.
+func (t Tree) FromData(data [][]byte) (Tree, error) {
+	if err := t.AppendData(data); err != nil {
+		return Tree{}, err
+	}
+	return t.FromLeaves(nil)
+}
+
 // Root returns the tree root - the top hash of the tree. Used in the inclusion proof verification.
 func (t *Tree) Root() []byte {
 
-	layers := t.layers()
-	if len(layers) > 0 {
-
-		// get first node of last layer
-		lastLayer := layers[len(layers)-1]
-		firstNode := lastLayer[0]
+	if len(t.nodes) > 0 {
 
-		// return first node hash as root
-		return firstNode.Hash
+		// the root is the only node of the last layer
+		return t.nodes[len(t.nodes)-1][0]
 	}
 
 	return []byte{}
@@ -67,28 +79,25 @@ func (t *Tree) currentLayersWithSiblingsHashes(leafIndices []uint64) [][]byte {
 	return newSiblingAndExistingHashes
 }
 
-// currentLayersWithSiblings gets all sibling layers required to build a partial merkle tree for the given indices,
-// cloning all required hashes into the resulting slice.
+// currentLayersWithSiblings gets all sibling layers required to build a partial merkle tree for the given sorted
+// indices, cloning all required hashes into the resulting slice.
 func (t *Tree) currentLayersWithSiblings(leafIndices []uint64) Layers {
 
 	var layersNodesWithSiblings Layers
-	for _, layer := range t.layers() {
-		// get siblings of leaf indices and extract newly created indices
-		siblings := siblingIndecies(leafIndices)
+	for layerIndex := range t.nodes {
+		// get the siblings of leaf indices which are not leaf indices themselves
+		newSiblingIndices := proofNodeIndices(leafIndices)
 
-		// detect newly extracted siblings
-		newSiblingIndices := extractNewIndicesFromSiblings(siblings, leafIndices)
-
-		// get the exisitng leaves in the layer with sibling indecies
+		// get the exisitng nodes in the layer with sibling indecies
 		var existingLeavesInTree Leaves
 		for i := 0; i < len(newSiblingIndices); i++ {
 
 			leafIndex := newSiblingIndices[i]
-			leaf, found := leafAtIndex(layer, leafIndex)
+			hash, found := t.node(uint64(layerIndex), leafIndex)
 			if found {
 
-				// append new sibling index
-				existingLeavesInTree = append(existingLeavesInTree, leaf)
+				// append new sibling node
+				existingLeavesInTree = append(existingLeavesInTree, types.Leaf{Index: leafIndex, Hash: hash})
 			}
 		}
 
@@ -105,17 +114,16 @@ func (t *Tree) currentLayersWithSiblings(leafIndices []uint64) Layers {
 // Proof Returns the Merkle proof required to prove the inclusion of items in a data set.
 func (t *Tree) Proof(proofIndices []uint64) Proof {
 	leavesLen := t.leavesLen()
-	leaves := t.leaves()
+
+	// the layers of the proof are calculated from the sorted indices
+	proofIndices = sortedUniqueIndices(proofIndices)
 
 	// make proof leaves from proof indices
 	var proofLeaves Leaves
 	for i := 0; i < len(proofIndices); i++ {
-		for j := 0; j < len(leaves); j++ {
-			leaf := leaves[j]
-			if leaf.Index == proofIndices[i] {
-				proofLeaves = append(proofLeaves, leaf)
-				break
-			}
+		hash, found := t.node(0, proofIndices[i])
+		if found {
+			proofLeaves = append(proofLeaves, types.Leaf{Index: proofIndices[i], Hash: hash})
 		}
 	}
 
@@ -126,24 +134,77 @@ func (t *Tree) Proof(proofIndices []uint64) Proof {
 	return NewProof(proofLeaves, siblingProofHashes, leavesLen, t.hasher)
 }
 
-// insert inserts a new types.Leaf. Please note it won't modify the root just yet; For the changes
-// to be applied to the root, commit method should be called first. To get the
-// root of the new tree without applying the changes, you can use
-func (t *Tree) insert(leaf []byte) {
+// Insert inserts a new leaf. Please note it won't modify the root just yet; For the changes
+// to be applied to the root, Commit method should be called first. To get the
+// root of the new tree without applying the changes, you can use UncommittedRoot
+func (t *Tree) Insert(leaf []byte) {
 	t.UncommittedLeaves = append(t.UncommittedLeaves, leaf)
 }
 
-// append appends leaves to the tree.
-func (t *Tree) append(leaves [][]byte) {
+// Append appends leaves to the tree. Like Insert, the changes will be applied to
+// the root only after calling Commit.
+func (t *Tree) Append(leaves [][]byte) {
 	t.UncommittedLeaves = append(t.UncommittedLeaves, leaves...)
 }
 
-// commit commits the changes made by insert and append
-// and modifies the root.
-func (t *Tree) commit() error {
+// UpdateLeaf replaces the hash of the leaf of the index, which is either a committed leaf or an uncommitted one. Like
+// Insert, the changes will be applied to the root only after calling Commit, which recalculates only the paths of the
+// updated leaves.
+func (t *Tree) UpdateLeaf(index uint64, hash []byte) error {
+	return t.UpdateLeaves(Leaves{{Index: index, Hash: hash}})
+}
+
+// UpdateLeaves replaces the hashes of the leaves of their indices like UpdateLeaf. The later updates of the same
+// index replace the former ones, and none of the leaves is updated if any of the indices is out of the tree range.
+func (t *Tree) UpdateLeaves(leaves Leaves) error {
+	committedLeavesCount := t.leavesLen()
+	for i := 0; i < len(leaves); i++ {
+		if leaves[i].Index >= committedLeavesCount+uint64(len(t.UncommittedLeaves)) {
+			return errLeafIndexOutOfRange
+		}
+	}
+
+	if t.uncommittedUpdates == nil {
+		t.uncommittedUpdates = make(map[uint64][]byte, len(leaves))
+	}
+	for i := 0; i < len(leaves); i++ {
+		if leaves[i].Index < committedLeavesCount {
+			t.uncommittedUpdates[leaves[i].Index] = leaves[i].Hash
+		} else {
+			t.UncommittedLeaves[leaves[i].Index-committedLeavesCount] = leaves[i].Hash
+		}
+	}
+	return nil
+}
+
+// AppendData hashes the leaf data with the hashing scheme of the tree hasher like FromData and appends the leaf
+// hashes to the tree. Like Append, the changes will be applied to the root only after calling Commit.
+func (t *Tree) AppendData(data [][]byte) error {
+	leaves := make([][]byte, len(data))
+	for i := 0; i < len(data); i++ {
+		leaf, err := hasher.HashLeaf(t.hasher, data[i])
+		if err != nil {
+			return err
+		}
+		leaves[i] = leaf
+	}
+
+	t.Append(leaves)
+	return nil
+}
+
+// Commit commits the changes made by Insert, Append and UpdateLeaves
+// and modifies the root. Every commit is kept in the tree history, so it can be reverted by Rollback.
+func (t *Tree) Commit() error {
+	return t.CommitContext(context.Background())
+}
+
+// CommitContext commits the changes like Commit. When the context is done it stops hashing the nodes and returns
+// the context error, the tree and its uncommitted leaves are left unchanged then.
+func (t *Tree) CommitContext(ctx context.Context) error {
 
 	// get difference committed and not committed tree layers
-	diff, err := t.uncommittedDiff()
+	diff, err := t.uncommittedDiff(ctx)
 	if err != nil {
 		return err
 	}
@@ -151,32 +212,62 @@ func (t *Tree) commit() error {
 	// if there is new layers update the tree
 	if len(diff.layers) > 0 {
 
+		// store the replaced nodes in the history to be able to rollback to the previous state
+		t.pushHistory(newCommitUndo(t.nodes, diff))
+
 		// merge existing and newly created partial tree
-		t.currentWorkingTree.mergeUnverifiedLayers(diff)
+		t.nodes = mergeLayers(t.nodes, diff)
 
-		// free up the uncommitted leaves after storing the tree
+		// free up the uncommitted leaves and updates after storing the tree
 		t.UncommittedLeaves = [][]byte{}
+		t.uncommittedUpdates = nil
 	}
 
 	return nil
 }
 
-// uncommittedRoot calculates the root of the uncommitted changes as if they were committed.
+// Rollback reverts the latest commit and restores the previously committed state of the tree.
+// The layers are truncated to their previous sizes and the nodes replaced by the commit are restored in place,
+// so no hashes are recalculated. Uncommitted leaves and updates are not affected by the rollback.
+func (t *Tree) Rollback() error {
+
+	// nothing has been committed yet
+	if len(t.history) == 0 {
+		return errNoCommitsToRollback
+	}
+
+	// remove the most recent commit
+	last := len(t.history) - 1
+	undo := t.history[last]
+	t.history = t.history[:last]
+
+	// undo the most recent commit only
+	t.nodes = undo.restore(t.nodes)
+
+	return nil
+}
+
+// CommitsCount returns the number of commits that are kept in the tree history.
+func (t *Tree) CommitsCount() int {
+	return len(t.history)
+}
+
+// UncommittedRoot calculates the root of the uncommitted changes as if they were committed.
 // Will return the same hash as root of merkle tree after commit
-func (t *Tree) uncommittedRoot() ([]byte, error) {
-	uncommittedTree, err := t.uncommittedDiff()
+func (t *Tree) UncommittedRoot() ([]byte, error) {
+	uncommittedTree, err := t.uncommittedDiff(context.Background())
 	if err != nil {
 		return []byte{}, err
 	}
 	return uncommittedTree.Root(), nil
 }
 
-// uncommittedRootHex calculates the root of the uncommitted changes as if they were committed. Serializes
+// UncommittedRootHex calculates the root of the uncommitted changes as if they were committed. Serializes
 // the result as a hex string.
-func (t *Tree) uncommittedRootHex() (string, error) {
+func (t *Tree) UncommittedRootHex() (string, error) {
 
 	// get uncommitted root
-	root, err := t.uncommittedRoot()
+	root, err := t.UncommittedRoot()
 	if err != nil {
 		return "", err
 	}
@@ -188,18 +279,17 @@ func (t *Tree) uncommittedRootHex() (string, error) {
 // depth returns the tree depth. A tree depth is how many layers there is between the
 // leaves and the root
 func (t *Tree) depth() int {
-	return len(t.layers()) - 1
+	return len(t.nodes) - 1
 }
 
 // baseLeaves returns a copy of the tree leaves - the base level of the tree.
 func (t *Tree) baseLeaves() [][]byte {
 
-	// get all hashes of leaves of all layersLeavesHashes
-	layersLeavesHashes := t.layersNodesHashes()
-
 	// if leaves are available
-	if len(layersLeavesHashes) > 0 {
-		return layersLeavesHashes[0]
+	if len(t.nodes) > 0 {
+		leaves := make([][]byte, len(t.nodes[0]))
+		copy(leaves, t.nodes[0])
+		return leaves
 	}
 
 	return [][]byte{}
@@ -207,44 +297,58 @@ func (t *Tree) baseLeaves() [][]byte {
 
 // leavesLen returns the number of leaves in the tree.
 func (t *Tree) leavesLen() uint64 {
-	leaves := t.leaves()
-	return uint64(len(leaves))
+	if len(t.nodes) > 0 {
+		return uint64(len(t.nodes[0]))
+	}
+	return 0
 }
 
 // leaves returns leaves of the first layer that has the complete tree
 func (t *Tree) leaves() Leaves {
-	if len(t.layers()) > 0 {
-		return t.layers()[0]
+	leaves := make(Leaves, t.leavesLen())
+	for i := 0; i < len(leaves); i++ {
+		leaves[i] = types.Leaf{Index: uint64(i), Hash: t.nodes[0][i]}
 	}
-	return Leaves{}
+	return leaves
 }
 
 // layersNodesHashes returns the whole tree, where the first layer is leaves and
 // consequent layersNodesHashes are nodes.
 func (t *Tree) layersNodesHashes() [][][]byte {
-	return t.currentWorkingTree.layerNodesHashes()
+	return t.nodes
 }
 
-// layers returns leaves of the current working tree
-func (t *Tree) layers() Layers {
-	return t.currentWorkingTree.layers
+// node returns the hash of the node at the index of the layer of the committed tree
+func (t *Tree) node(layerIndex, index uint64) ([]byte, bool) {
+	if layerIndex >= uint64(len(t.nodes)) || index >= uint64(len(t.nodes[layerIndex])) {
+		return nil, false
+	}
+	return t.nodes[layerIndex][index], true
 }
 
 // uncommittedDiff creates a diff from a changes that weren't committed to the main tree yet. Can be used
 // to get uncommitted root or can be merged with the main tree
-func (t *Tree) uncommittedDiff() (PartialTree, error) {
+func (t *Tree) uncommittedDiff(ctx context.Context) (PartialTree, error) {
 
-	// if there is no uncommitted leaves, there is no more partial
-	if len(t.UncommittedLeaves) == 0 {
+	// if there is no uncommitted leaves and updates, there is no more partial
+	if len(t.UncommittedLeaves) == 0 && len(t.uncommittedUpdates) == 0 {
 		return PartialTree{}, nil
 	}
 
+	// the updated leaves may be out of the range after a rollback
+	for index := range t.uncommittedUpdates {
+		if index >= t.leavesLen() {
+			return PartialTree{}, errLeafIndexOutOfRange
+		}
+	}
+
 	// get uncommitted partial layer
 	partialTreeLayers, uncommittedTreeDepth := t.uncommittedPartialTreeLayers()
 
 	// build partial tree and return
 	tree := NewPartialTree(t.hasher)
-	return tree.build(partialTreeLayers, uncommittedTreeDepth)
+	tree.workers = t.workers
+	return tree.buildContext(ctx, partialTreeLayers, uncommittedTreeDepth)
 }
 
 // uncommittedPartialTreeLayers calculates reserved indices and leaves then returns uncommitted partial tree layers
@@ -269,71 +373,126 @@ func (t *Tree) uncommittedPartialTreeLayers() (Layers, uint64) {
 	return partialTreeLayers, uncommittedTreeDepth
 }
 
-// getUncommittedReservedIndecies returns uncommitted reserved indices of the uncommitted leaves
+// getUncommittedReservedIndecies returns uncommitted reserved indices of the updated and the uncommitted leaves,
+// sorted in the ascending order
 func (t *Tree) getUncommittedReservedIndecies() []uint64 {
 
-	// if there are no uncommitted leaves there is nothing to reserve
-	if len(t.UncommittedLeaves) == 0 {
-		return []uint64{}
+	// the updated leaves are committed, so they are before the uncommitted ones
+	reservedIndecies := make([]uint64, 0, len(t.uncommittedUpdates)+len(t.UncommittedLeaves))
+	for index := range t.uncommittedUpdates {
+		reservedIndecies = append(reservedIndecies, index)
 	}
-
-	commitedLeavesCount := t.leavesLen()
-	unCommitedLeavesCount := len(t.UncommittedLeaves)
+	sort.Slice(reservedIndecies, func(i, j int) bool { return reservedIndecies[i] < reservedIndecies[j] })
 
 	// populate uncommitted indices according to the last committed leaves indices
-	reservedIndecies := make([]uint64, unCommitedLeavesCount)
-	for i := 0; i < unCommitedLeavesCount; i++ {
-		reservedIndecies[i] = commitedLeavesCount + uint64(i)
+	commitedLeavesCount := t.leavesLen()
+	for i := 0; i < len(t.UncommittedLeaves); i++ {
+		reservedIndecies = append(reservedIndecies, commitedLeavesCount+uint64(i))
 	}
 
 	return reservedIndecies
 }
 
-// getUncommittedReservedLeaves returns uncommitted reserved leaves of the uncommitted leaves
+// getUncommittedReservedLeaves returns uncommitted reserved leaves of the updated and the uncommitted leaves
 func (t *Tree) getUncommittedReservedLeaves(reservedIndecies []uint64) Leaves {
 
-	// read uncommitted leaves hashes and set into reserved leaves by indices
+	// read updated and uncommitted leaves hashes and set into reserved leaves by indices
+	commitedLeavesCount := t.leavesLen()
 	indicesCount := len(reservedIndecies)
 	reservedLeaves := make(Leaves, indicesCount)
 	for i := 0; i < indicesCount; i++ {
-		reservedLeaves[i] = types.Leaf{Index: reservedIndecies[i], Hash: t.UncommittedLeaves[i]}
+		index := reservedIndecies[i]
+		if index < commitedLeavesCount {
+			reservedLeaves[i] = types.Leaf{Index: index, Hash: t.uncommittedUpdates[index]}
+		} else {
+			reservedLeaves[i] = types.Leaf{Index: index, Hash: t.UncommittedLeaves[index-commitedLeavesCount]}
+		}
 	}
 
 	return reservedLeaves
 }
 
-// leafAtIndex returns leaf object by index
-func leafAtIndex(leaves Leaves, index uint64) (types.Leaf, bool) {
+// mergeLayers merges the layers of the partial tree into the dense layers in place, replacing any conflicting nodes
+// with the nodes of the partial tree, and returns the merged layers. Doesn't rehash the nodes, so the integrity of the
+// result is not verified, it's used by Commit where the partial trees are built from the tree itself. Only the nodes of
+// the partial tree are written and the layers grow by appending, so a commit costs as much as its partial tree.
+func mergeLayers(nodes [][][]byte, other PartialTree) [][][]byte {
+	for layerIndex, otherLayer := range other.layers {
+		if layerIndex == len(nodes) {
+			nodes = append(nodes, [][]byte{})
+		}
 
-	// loop through leaves and return leaf at certain index
-	for i := 0; i < len(leaves); i++ {
-		l := leaves[i]
-		if l.Index == index {
-			return l, true
+		layer := nodes[layerIndex]
+		for i := 0; i < len(otherLayer); i++ {
+			for uint64(len(layer)) <= otherLayer[i].Index {
+				layer = append(layer, nil)
+			}
+			layer[otherLayer[i].Index] = otherLayer[i].Hash
 		}
+		nodes[layerIndex] = layer
 	}
 
-	return types.Leaf{}, false
+	return nodes
 }
 
-// layerAtIndex returns layer object by intex
-func layerAtIndex(layers Layers, index uint64) (Leaves, bool) {
+// pushHistory appends the record of a commit to the history and drops the oldest records over the history limit
+func (t *Tree) pushHistory(undo commitUndo) {
+	t.history = append(t.history, undo)
+	if t.historyLimit > 0 && len(t.history) > t.historyLimit {
+		t.history = t.history[len(t.history)-t.historyLimit:]
+	}
+}
 
-	if len(layers) > int(index) {
-		return layers[index], true
+// newCommitUndo returns the record that restores the dense layers after they are merged with the partial tree
+func newCommitUndo(nodes [][][]byte, diff PartialTree) commitUndo {
+	undo := commitUndo{layersSizes: make([]int, len(nodes)), replaced: make(Layers, len(nodes))}
+	for layerIndex := range nodes {
+		undo.layersSizes[layerIndex] = len(nodes[layerIndex])
+		if layerIndex >= len(diff.layers) {
+			continue
+		}
+
+		// the unchanged siblings of the partial tree are not recorded
+		for _, node := range diff.layers[layerIndex] {
+			if node.Index < uint64(len(nodes[layerIndex])) && !bytes.Equal(nodes[layerIndex][node.Index], node.Hash) {
+				undo.replaced[layerIndex] = append(undo.replaced[layerIndex],
+					types.Leaf{Index: node.Index, Hash: nodes[layerIndex][node.Index]})
+			}
+		}
 	}
+	return undo
+}
 
-	return Leaves{}, false
+// restore returns the dense layers before the commit of the record. The layers are truncated to their previous sizes
+// and the replaced nodes are written back in place.
+func (u commitUndo) restore(nodes [][][]byte) [][][]byte {
+	nodes = nodes[:len(u.layersSizes)]
+	for layerIndex, size := range u.layersSizes {
+		nodes[layerIndex] = nodes[layerIndex][:size]
+		for _, node := range u.replaced[layerIndex] {
+			nodes[layerIndex][node.Index] = node.Hash
+		}
+	}
+	return nodes
 }
 
 // sortLeavesAscending sorts leaves by their index
 func sortLeavesAscending(li Leaves) {
+	// the leaves are usually sorted already, which is checked in linear time
+	if sort.SliceIsSorted(li, func(i, j int) bool { return li[i].Index < li[j].Index }) {
+		return
+	}
 	sort.Slice(li, func(i, j int) bool { return li[i].Index < li[j].Index })
 }
 
 // treeDepth returns the depth of a tree
 func treeDepth(leavesCount uint64) uint64 {
 
+	// an empty tree has no layers to prove
+	if leavesCount == 0 {
+		return 0
+	}
+
 	if leavesCount == 1 {
 		return 1
 	}
@@ -353,15 +512,8 @@ func upsertUncommittedReservedLayers(partialTreeLayers Layers, reservedNodeLeave
 		// no partial layers available yet, so we ned to create one
 		partialTreeLayers = append(partialTreeLayers, reservedNodeLeaves)
 	} else {
-		// get first layer and append leaves
-		firstLayer := partialTreeLayers[0]
-		firstLayer = append(firstLayer, reservedNodeLeaves...)
-
-		// sort leaves after new nodes addition
-		sortLeavesAscending(firstLayer)
-
-		// update the first layer
-		partialTreeLayers[0] = firstLayer
+		// merge the sorted leaves of the first layer with the new leaves
+		partialTreeLayers[0] = mergeLeaves(partialTreeLayers[0], reservedNodeLeaves)
 	}
 
 	return partialTreeLayers
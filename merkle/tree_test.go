@@ -1,9 +1,12 @@
 package merkle
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
 	"github.com/stretchr/testify/require"
 )
 
@@ -100,9 +103,9 @@ func TestGetCorrectRootAfterCommit(t *testing.T) {
 	merkleTree2, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leafHashes)
 	require.NoError(t, err)
 
-	merkleTree.append(leafHashes)
+	merkleTree.Append(leafHashes)
 
-	root, err := merkleTree.uncommittedRootHex()
+	root, err := merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 
 	require.Equal(t, expectedRoot, merkleTree2.RootHex())
@@ -111,28 +114,28 @@ func TestGetCorrectRootAfterCommit(t *testing.T) {
 	expectedRoot = "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034"
 	leaf, err := hasher.Sha256Hasher{}.Hash([]byte("g"))
 	require.NoError(t, err)
-	merkleTree.insert(leaf)
+	merkleTree.Insert(leaf)
 
-	uncommittedRoot, err := merkleTree.uncommittedRootHex()
+	uncommittedRoot, err := merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 	require.Equal(t, expectedRoot, uncommittedRoot)
 
 	require.Equal(t, []byte{}, merkleTree.Root())
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	hashOfH, _ := hasher.Sha256Hasher{}.Hash([]byte("h"))
 	hashOfK, _ := hasher.Sha256Hasher{}.Hash([]byte("k"))
 	newLeaves := [][]byte{hashOfH, hashOfK}
 
-	merkleTree.append(newLeaves)
+	merkleTree.Append(newLeaves)
 
 	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", merkleTree.RootHex())
-	uncommittedRootHex, err := merkleTree.uncommittedRootHex()
+	uncommittedRootHex, err := merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 	require.Equal(t, "09b6890b23e32e607f0e5f670ab224e36af8f6599cbe88b468f4b0f761802dd6", uncommittedRootHex)
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	leaves := merkleTree.baseLeaves()
 	reconstructedTree, err := merkleTree.FromLeaves(leaves)
@@ -152,39 +155,39 @@ func TestChangeTheResultWenCalledTwice(t *testing.T) {
 	merkleTree := NewTree(hasher.Sha256Hasher{})
 
 	// Appending leaves to the tree without committing
-	merkleTree.append(leaves)
+	merkleTree.Append(leaves)
 
 	require.Equal(t, []byte{}, merkleTree.Root())
 
-	uncommittedRootHex, err := merkleTree.uncommittedRootHex()
+	uncommittedRootHex, err := merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 	require.Equal(t, "1f7379539707bcaea00564168d1d4d626b09b73f8a2a365234c62d763f854da2", uncommittedRootHex)
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	require.Equal(t, "1f7379539707bcaea00564168d1d4d626b09b73f8a2a365234c62d763f854da2", merkleTree.RootHex())
 
-	uncommittedRootHex, err = merkleTree.uncommittedRootHex()
+	uncommittedRootHex, err = merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 	require.Equal(t, "", uncommittedRootHex)
 
 	gHash, _ := hasher.Sha256Hasher{}.Hash([]byte("g"))
-	merkleTree.insert(gHash)
+	merkleTree.Insert(gHash)
 
-	uncommittedRootHex, err = merkleTree.uncommittedRootHex()
+	uncommittedRootHex, err = merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", uncommittedRootHex)
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", merkleTree.RootHex())
 
 	hashOfH, _ := hasher.Sha256Hasher{}.Hash([]byte("h"))
 	hashOfK, _ := hasher.Sha256Hasher{}.Hash([]byte("k"))
-	merkleTree.append([][]byte{hashOfH, hashOfK})
+	merkleTree.Append([][]byte{hashOfH, hashOfK})
 
-	merkleTree.commit()
-	merkleTree.commit()
+	merkleTree.Commit()
+	merkleTree.Commit()
 
 	require.Equal(t, "09b6890b23e32e607f0e5f670ab224e36af8f6599cbe88b468f4b0f761802dd6", merkleTree.RootHex())
 
@@ -199,40 +202,374 @@ func TestRollbackPreviousCommit(t *testing.T) {
 	}
 
 	merkleTree := NewTree(hasher.Sha256Hasher{})
-	merkleTree.append(leaves)
+	merkleTree.Append(leaves)
 
 	require.Equal(t, []byte{}, merkleTree.Root())
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	require.Equal(t, "1f7379539707bcaea00564168d1d4d626b09b73f8a2a365234c62d763f854da2", merkleTree.RootHex())
 
 	gHash, _ := hasher.Sha256Hasher{}.Hash([]byte("g"))
-	merkleTree.insert(gHash)
+	merkleTree.Insert(gHash)
 
-	uncommittedRootHex, err := merkleTree.uncommittedRootHex()
+	uncommittedRootHex, err := merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 
 	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", uncommittedRootHex)
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", merkleTree.RootHex())
 
 	hashOfH, _ := hasher.Sha256Hasher{}.Hash([]byte("h"))
 	hashOfK, _ := hasher.Sha256Hasher{}.Hash([]byte("k"))
-	merkleTree.append([][]byte{hashOfH, hashOfK})
+	merkleTree.Append([][]byte{hashOfH, hashOfK})
 
-	uncommittedRootHex, err = merkleTree.uncommittedRootHex()
+	uncommittedRootHex, err = merkleTree.UncommittedRootHex()
 	require.NoError(t, err)
 	require.Equal(t, "09b6890b23e32e607f0e5f670ab224e36af8f6599cbe88b468f4b0f761802dd6", uncommittedRootHex)
 
 	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", merkleTree.RootHex())
 
-	merkleTree.commit()
+	merkleTree.Commit()
 
 	require.Equal(t, "09b6890b23e32e607f0e5f670ab224e36af8f6599cbe88b468f4b0f761802dd6", merkleTree.RootHex())
+	require.Equal(t, 3, merkleTree.CommitsCount())
 
+	err = merkleTree.Rollback()
+	require.NoError(t, err)
+	require.Equal(t, "e2a80e0e872a6c6eaed37b4c1f220e1935004805585b5f99617e48e9c8fe4034", merkleTree.RootHex())
+	require.Equal(t, uint64(7), merkleTree.leavesLen())
+
+	err = merkleTree.Rollback()
+	require.NoError(t, err)
+	require.Equal(t, "1f7379539707bcaea00564168d1d4d626b09b73f8a2a365234c62d763f854da2", merkleTree.RootHex())
+
+	proof := merkleTree.Proof([]uint64{3, 5})
+	verified, err := proof.Verify(merkleTree.Root())
+	require.NoError(t, err)
+	require.True(t, verified)
+
+	err = merkleTree.Rollback()
+	require.NoError(t, err)
+	require.Equal(t, []byte{}, merkleTree.Root())
+
+	err = merkleTree.Rollback()
+	require.ErrorIs(t, err, errNoCommitsToRollback)
+}
+
+func TestCommitAfterRollback(t *testing.T) {
+	testData := setupTestData()
+
+	merkleTree := NewTree(hasher.Sha256Hasher{})
+	merkleTree.Append(testData.leafHashes[:4])
+	err := merkleTree.Commit()
+	require.NoError(t, err)
+
+	// commit a wrong batch and revert it
+	wrongLeaf, err := hasher.Sha256Hasher{}.Hash([]byte("wrong"))
+	require.NoError(t, err)
+	merkleTree.Insert(wrongLeaf)
+	err = merkleTree.Commit()
+	require.NoError(t, err)
+	err = merkleTree.Rollback()
+	require.NoError(t, err)
+
+	// commit the right batch on top of the restored state
+	merkleTree.Append(testData.leafHashes[4:])
+	root, err := merkleTree.UncommittedRootHex()
+	require.NoError(t, err)
+	require.Equal(t, testData.expectedRootHex, root)
+
+	err = merkleTree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, testData.expectedRootHex, merkleTree.RootHex())
+	require.Equal(t, 2, merkleTree.CommitsCount())
+}
+
+func TestIncrementalCommits(t *testing.T) {
+	var leaves [][]byte
+	for i := 0; i < 40; i++ {
+		h, err := hasher.Sha256Hasher{}.Hash([]byte{byte(i)})
+		require.NoError(t, err)
+		leaves = append(leaves, h)
+	}
+
+	for _, batchSize := range []int{1, 2, 3, 5, 8} {
+		merkleTree := NewTree(hasher.Sha256Hasher{})
+		for start := 0; start < len(leaves); start += batchSize {
+			end := start + batchSize
+			if end > len(leaves) {
+				end = len(leaves)
+			}
+			merkleTree.Append(leaves[start:end])
+			require.NoError(t, merkleTree.Commit())
+
+			expectedTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leaves[:end])
+			require.NoError(t, err)
+			require.Equal(t, expectedTree.layersNodesHashes(), merkleTree.layersNodesHashes())
+
+			proof := merkleTree.Proof([]uint64{0, uint64(end) / 2, uint64(end) - 1})
+			verified, err := proof.Verify(expectedTree.Root())
+			require.NoError(t, err)
+			require.True(t, verified)
+		}
+	}
+}
+
+func TestCommitDoesNotAffectClones(t *testing.T) {
+	testData := setupTestData()
+	merkleTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(testData.leafHashes[:5])
+	require.NoError(t, err)
+	root := merkleTree.RootHex()
+
+	treeCopy := merkleTree.Clone()
+	treeCopy.Insert(testData.leafHashes[5])
+	require.NoError(t, treeCopy.Commit())
+	require.Equal(t, testData.expectedRootHex, treeCopy.RootHex())
+	require.Equal(t, root, merkleTree.RootHex())
+	require.Equal(t, uint64(5), merkleTree.leavesLen())
+}
+
+func TestUpdateLeaves(t *testing.T) {
+	for count := 1; count <= 17; count++ {
+		leaves := keccakLeaves(t, count+2)
+		merkleTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leaves[:count])
+		require.NoError(t, err)
+
+		for _, indices := range [][]uint64{{0}, {uint64(count) - 1}, {uint64(count) / 2, 0, uint64(count) - 1}} {
+			expected := append([][]byte{}, merkleTree.baseLeaves()...)
+			var updates Leaves
+			for _, index := range indices {
+				hash, err := hasher.Sha256Hasher{}.Hash(append(expected[index], byte(count)))
+				require.NoError(t, err)
+				expected[index] = hash
+				updates = append(updates, types.Leaf{Index: index, Hash: hash})
+			}
+			expectedTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(expected)
+			require.NoError(t, err)
+
+			root := merkleTree.Root()
+			require.NoError(t, merkleTree.UpdateLeaves(updates))
+			require.Equal(t, root, merkleTree.Root())
+			uncommittedRoot, err := merkleTree.UncommittedRoot()
+			require.NoError(t, err)
+			require.Equal(t, expectedTree.Root(), uncommittedRoot)
+
+			require.NoError(t, merkleTree.Commit())
+			require.Equal(t, expectedTree.layersNodesHashes(), merkleTree.layersNodesHashes())
+			proof := merkleTree.Proof(indices)
+			verified, err := proof.Verify(expectedTree.Root())
+			require.NoError(t, err)
+			require.True(t, verified)
+		}
+
+		// the updates of the committed and the uncommitted leaves are committed with the appended leaves
+		expected := append(merkleTree.baseLeaves(), leaves[count+1], leaves[count])
+		merkleTree.Append(leaves[count:])
+		require.NoError(t, merkleTree.UpdateLeaf(uint64(count), leaves[count+1]))
+		require.NoError(t, merkleTree.UpdateLeaf(uint64(count)+1, leaves[count]))
+		require.NoError(t, merkleTree.UpdateLeaf(0, leaves[0]))
+		expected[0] = leaves[0]
+		require.NoError(t, merkleTree.Commit())
+		expectedTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(expected)
+		require.NoError(t, err)
+		require.Equal(t, expectedTree.layersNodesHashes(), merkleTree.layersNodesHashes())
+
+		require.ErrorIs(t, merkleTree.UpdateLeaf(uint64(count)+2, leaves[0]), errLeafIndexOutOfRange)
+	}
+}
+
+func TestUpdateLeafRecalculatesPath(t *testing.T) {
+	leaves := keccakLeaves(t, 1000)
+	merkleTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leaves)
+	require.NoError(t, err)
+	root := merkleTree.Root()
+
+	require.NoError(t, merkleTree.UpdateLeaf(517, leaves[0]))
+	require.NoError(t, merkleTree.Commit())
+	require.NotEqual(t, root, merkleTree.Root())
+
+	// the commit replaced only the nodes on the updated path
+	undo := merkleTree.history[len(merkleTree.history)-1]
+	require.Len(t, undo.replaced, merkleTree.depth()+1)
+	for _, layer := range undo.replaced {
+		require.Len(t, layer, 1)
+	}
+
+	// the updates of a clone don't affect the tree
+	treeCopy := merkleTree.Clone()
+	require.NoError(t, treeCopy.UpdateLeaf(1, leaves[0]))
+	require.NoError(t, merkleTree.Rollback())
+	require.Equal(t, root, merkleTree.Root())
+	uncommittedRoot, err := merkleTree.UncommittedRoot()
+	require.NoError(t, err)
+	require.Empty(t, uncommittedRoot)
+
+	// the updated leaf is out of the range after rolling back all of the commits
+	require.NoError(t, treeCopy.Rollback())
+	require.NoError(t, treeCopy.Rollback())
+	_, err = treeCopy.UncommittedRoot()
+	require.ErrorIs(t, err, errLeafIndexOutOfRange)
+}
+
+func TestRollbackRestoresEveryCommit(t *testing.T) {
+	leaves := keccakLeaves(t, 300)
+	merkleTree := NewTree(hasher.Sha256Hasher{})
+	var roots [][]byte
+	for i := 0; i < 30; i++ {
+		merkleTree.Append(leaves[i*10 : i*10+10])
+		if i%3 == 2 {
+			require.NoError(t, merkleTree.UpdateLeaves(Leaves{{Index: 0, Hash: leaves[i]}, {Index: uint64(i), Hash: leaves[299-i]}}))
+		}
+		require.NoError(t, merkleTree.Commit())
+		roots = append(roots, merkleTree.Root())
+	}
+
+	// every rollback restores the previously committed tree
+	for i := len(roots) - 1; i > 0; i-- {
+		treeCopy := merkleTree.Clone()
+		require.NoError(t, merkleTree.Rollback())
+		require.Equal(t, roots[i-1], merkleTree.Root())
+		require.Equal(t, roots[i], treeCopy.Root())
+
+		expected, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(merkleTree.nodes[0])
+		require.NoError(t, err)
+		require.Equal(t, expected.layersNodesHashes(), merkleTree.layersNodesHashes())
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	leaves := keccakLeaves(t, 10)
+	merkleTree := NewTree(hasher.Sha256Hasher{}).WithHistoryLimit(3)
+	var roots [][]byte
+	for _, leaf := range leaves {
+		merkleTree.Insert(leaf)
+		require.NoError(t, merkleTree.Commit())
+		roots = append(roots, merkleTree.Root())
+	}
+	require.Len(t, merkleTree.history, 3)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, merkleTree.Rollback())
+		require.Equal(t, roots[len(roots)-2-i], merkleTree.Root())
+	}
+	require.ErrorIs(t, merkleTree.Rollback(), errNoCommitsToRollback)
+}
+
+func TestProofOfUnsortedIndices(t *testing.T) {
+	testData := setupTestData()
+	merkleTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(testData.leafHashes)
+	require.NoError(t, err)
+
+	proof := merkleTree.Proof([]uint64{4, 1, 4})
+	require.Equal(t, merkleTree.Proof([]uint64{1, 4}), proof)
+	verified, err := proof.Verify(merkleTree.Root())
+	require.NoError(t, err)
+	require.True(t, verified)
+}
+
+func TestParallelBuild(t *testing.T) {
+	var leaves [][]byte
+	for i := 0; i < 9000; i++ {
+		h, err := hasher.Sha256Hasher{}.Hash([]byte{byte(i >> 8), byte(i)})
+		require.NoError(t, err)
+		leaves = append(leaves, h)
+	}
+
+	fullTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leaves)
+	require.NoError(t, err)
+
+	for _, count := range []int{1, 2, 2047, 2048, 4097, 9000} {
+		sequentialTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(leaves[:count])
+		require.NoError(t, err)
+		indices := []uint64{0, uint64(count) / 3, uint64(count) - 1}
+
+		for _, workers := range []int{0, 1, 2, 3, 16} {
+			parallelTree, err := NewTree(hasher.Sha256Hasher{}).WithWorkers(workers).FromLeaves(leaves[:count])
+			require.NoError(t, err)
+			require.Equal(t, sequentialTree.layersNodesHashes(), parallelTree.layersNodesHashes())
+			require.Equal(t, sequentialTree.Proof(indices), parallelTree.Proof(indices))
+
+			// commits on top of a parallel tree hash only the new nodes
+			parallelTree.Append(leaves[count:])
+			require.NoError(t, parallelTree.Commit())
+			require.Equal(t, fullTree.RootHex(), parallelTree.RootHex())
+		}
+	}
+}
+
+func TestParallelBuildCancellation(t *testing.T) {
+	testData := setupTestData()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewTree(hasher.Sha256Hasher{}).WithWorkers(4).FromLeavesContext(ctx, testData.leafHashes)
+	require.ErrorIs(t, err, context.Canceled)
+
+	merkleTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(testData.leafHashes[:4])
+	require.NoError(t, err)
+	root := merkleTree.RootHex()
+	merkleTree.Append(testData.leafHashes[4:])
+	require.ErrorIs(t, merkleTree.CommitContext(ctx), context.Canceled)
+	require.Equal(t, root, merkleTree.RootHex())
+	require.Equal(t, 1, merkleTree.CommitsCount())
+	require.Len(t, merkleTree.UncommittedLeaves, 2)
+
+	require.NoError(t, merkleTree.CommitContext(context.Background()))
+	require.Equal(t, testData.expectedRootHex, merkleTree.RootHex())
+}
+
+func TestLeafHashingSchemes(t *testing.T) {
+	testData := setupTestData()
+	var data [][]byte
+	for _, value := range testData.leafValues[:4] {
+		data = append(data, []byte(value))
+	}
+
+	// the legacy scheme keeps the roots of the trees built from leaf hashes
+	legacyHasher := hasher.NewLegacyHasher(hasher.Sha256Hasher{})
+	legacyTree, err := NewTree(legacyHasher).FromData(data)
+	require.NoError(t, err)
+	expectedTree, err := NewTree(hasher.Sha256Hasher{}).FromLeaves(testData.leafHashes[:4])
+	require.NoError(t, err)
+	require.Equal(t, expectedTree.RootHex(), legacyTree.RootHex())
+
+	prefixedHasher, err := hasher.NewPrefixedHasher(hasher.Sha256Hasher{}, []byte{0}, []byte{1})
+	require.NoError(t, err)
+	prefixedTree, err := NewTree(prefixedHasher).FromData(data)
+	require.NoError(t, err)
+	require.NotEqual(t, legacyTree.RootHex(), prefixedTree.RootHex())
+
+	for _, c := range []struct {
+		tree   Tree
+		hasher types.Hasher
+		forged bool
+	}{{legacyTree, legacyHasher, true}, {prefixedTree, prefixedHasher, false}} {
+		proof := c.tree.Proof([]uint64{2})
+		verified, err := proof.Verify(c.tree.Root())
+		require.NoError(t, err)
+		require.True(t, verified)
+		verified, err = proof.VerifyData(c.tree.Root(), data[2:3])
+		require.NoError(t, err)
+		require.True(t, verified)
+		verified, err = proof.VerifyData(c.tree.Root(), data[1:2])
+		require.NoError(t, err)
+		require.False(t, verified)
+		_, err = proof.VerifyData(c.tree.Root(), data[1:3])
+		require.ErrorIs(t, err, errInvalidLeavesData)
+
+		// the concatenated hashes of the first two leaves are passed off as a leaf of a tree of two leaves
+		innerNodes := c.tree.layersNodesHashes()[1]
+		forgedData := append(append([]byte{}, c.tree.baseLeaves()[0]...), c.tree.baseLeaves()[1]...)
+		forgedLeaf, err := hasher.HashLeaf(c.hasher, forgedData)
+		require.NoError(t, err)
+		forgedProof := NewProof(Leaves{{Index: 0, Hash: forgedLeaf}}, [][]byte{innerNodes[1]}, 2, c.hasher)
+		verified, err = forgedProof.Verify(c.tree.Root())
+		require.NoError(t, err)
+		require.Equal(t, c.forged, verified)
+	}
 }
 
 func sampleHashes() ([][]byte, error) {
@@ -264,6 +601,68 @@ func BenchmarkKeccak256Hasher(b *testing.B) {
 	}
 }
 
+// keccakLeaves returns the keccak256 hashes of count leaves
+func keccakLeaves(b testing.TB, count int) [][]byte {
+	leaves := make([][]byte, count)
+	for i := 0; i < count; i++ {
+		h, err := hasher.Keccak256Hasher{}.Hash([]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+		require.NoError(b, err)
+		leaves[i] = h
+	}
+	return leaves
+}
+
+func BenchmarkKeccak256FromLeaves(b *testing.B) {
+	for _, count := range []int{1 << 10, 1 << 16, 1 << 20} {
+		leaves := keccakLeaves(b, count)
+		b.Run(fmt.Sprintf("leaves=%d", count), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := NewTree(hasher.Keccak256Hasher{}).FromLeaves(leaves)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func BenchmarkKeccak256FromLeavesParallel(b *testing.B) {
+	leaves := keccakLeaves(b, 1<<20)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				_, err := NewTree(hasher.Keccak256Hasher{}).WithWorkers(workers).FromLeaves(leaves)
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
+func BenchmarkKeccak256Multiproof(b *testing.B) {
+	const count = 1 << 20
+	merkleTree, err := NewTree(hasher.Keccak256Hasher{}).FromLeaves(keccakLeaves(b, count))
+	require.NoError(b, err)
+
+	for _, indicesCount := range []int{1, 100, 5000} {
+		indices := make([]uint64, indicesCount)
+		for i := 0; i < indicesCount; i++ {
+			indices[i] = uint64(i * (count / indicesCount))
+		}
+
+		b.Run(fmt.Sprintf("indices=%d", indicesCount), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				merkleTree.Proof(indices)
+			}
+		})
+		b.Run(fmt.Sprintf("verify/indices=%d", indicesCount), func(b *testing.B) {
+			proof := merkleTree.Proof(indices)
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				_, err := proof.Verify(merkleTree.Root())
+				require.NoError(b, err)
+			}
+		})
+	}
+}
+
 func BenchmarkFromLeaves(b *testing.B) {
 	leaves, _ := sampleHashes()
 	for n := 0; n < b.N; n++ {
@@ -275,7 +674,7 @@ func BenchmarkFromLeaves(b *testing.B) {
 func BenchmarkUncommittedReservedIndecies(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	for n := 0; n < b.N; n++ {
 		mtree.getUncommittedReservedIndecies()
 	}
@@ -284,7 +683,7 @@ func BenchmarkUncommittedReservedIndecies(b *testing.B) {
 func BenchmarkUncommittedReservedLeaves(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	reservedIndices := mtree.getUncommittedReservedIndecies()
 	for n := 0; n < b.N; n++ {
 		mtree.getUncommittedReservedLeaves(reservedIndices)
@@ -294,7 +693,7 @@ func BenchmarkUncommittedReservedLeaves(b *testing.B) {
 func BenchmarkSiblingIndices(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	reservedIndices := mtree.getUncommittedReservedIndecies()
 	for n := 0; n < b.N; n++ {
 		siblingIndecies(reservedIndices)
@@ -304,7 +703,7 @@ func BenchmarkSiblingIndices(b *testing.B) {
 func BenchmarkParentIndices(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	reservedIndices := mtree.getUncommittedReservedIndecies()
 	for n := 0; n < b.N; n++ {
 		parentIndecies(reservedIndices)
@@ -314,9 +713,26 @@ func BenchmarkParentIndices(b *testing.B) {
 func BenchmarkCurrentLayersWithSiblings(b *testing.B) {
 	leaves, _ := sampleHashes()
 	mtree := NewTree(hasher.Sha256Hasher{})
-	mtree.append(leaves)
+	mtree.Append(leaves)
 	reservedIndices := mtree.getUncommittedReservedIndecies()
 	for n := 0; n < b.N; n++ {
 		mtree.currentLayersWithSiblings(reservedIndices)
 	}
 }
+
+func BenchmarkUpdateLeaf(b *testing.B) {
+	for _, count := range []int{1 << 10, 1 << 14, 1 << 18} {
+		leaves := make([][]byte, count)
+		for i := range leaves {
+			leaves[i], _ = hasher.Sha256Hasher{}.Hash([]byte(fmt.Sprint(i)))
+		}
+		merkleTree, _ := NewTree(hasher.Sha256Hasher{}).WithHistoryLimit(1).FromLeaves(leaves)
+		b.Run(fmt.Sprint(count), func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				_ = merkleTree.UpdateLeaf(uint64(n%count), leaves[(n+1)%count])
+				_ = merkleTree.Commit()
+			}
+		})
+	}
+}
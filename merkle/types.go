@@ -18,28 +18,89 @@ type Layers []Leaves
 // Advanced features include being able to make transactional changes to a tree with being able to
 // roll back to any previously committed state of the tree. This scenario is similar to Git and
 // can be found in databases and file systems.
+//
+// The committed nodes are updated in place, so the copies of a tree share them like the copies of a slice do.
+// Use Clone to get a tree that can be changed independently.
 type Tree struct {
-	currentWorkingTree PartialTree
-	UncommittedLeaves  [][]byte
+	// nodes are the dense layers of the committed tree, nodes[i][j] is the hash of the node j of the layer i.
+	// The first layer is the leaves and the last one is the root.
+	nodes [][][]byte
+	// history are the records of the commits that restore the previously committed trees, the latest is the last
+	history           []commitUndo
+	UncommittedLeaves [][]byte
+	// uncommittedUpdates are the new hashes of the committed leaves by the leaf index
+	uncommittedUpdates map[uint64][]byte
 	hasher             types.Hasher
+	// workers is the number of goroutines that hash the nodes of a layer
+	workers int
+	// historyLimit is the maximum number of the commits kept in the history, zero keeps all of them
+	historyLimit int
+}
+
+// commitUndo is the record of a commit that restores the previously committed tree. Only the sizes of the layers
+// and the nodes replaced by the commit are kept, so rolling back a commit costs as much as the commit itself.
+type commitUndo struct {
+	// layersSizes are the sizes of the layers before the commit
+	layersSizes []int
+	// replaced are the nodes of the layers before the commit that were replaced by the commit
+	replaced Layers
 }
 
 // NewTree creates a new instance of merkle tree. requires a hash algorithm to be specified.
 func NewTree(hasher types.Hasher) Tree {
 	return Tree{
-		currentWorkingTree: NewPartialTree(hasher),
-		UncommittedLeaves:  [][]byte{},
-		hasher:             hasher,
+		nodes:             [][][]byte{},
+		history:           []commitUndo{},
+		UncommittedLeaves: [][]byte{},
+		hasher:            hasher,
 	}
 }
 
-// PartialTree represents a part of the original tree that is enough to calculate the root.
+// WithWorkers returns the tree which hashes the nodes of every layer in the given number of goroutines when the tree
+// is built by Commit, it's useful for the trees of millions of leaves. The roots and proofs are identical to the ones
+// of a sequential build, which is used when the number of workers is less than 2. The hasher must be safe for
+// concurrent use, as all of the hashers of this library are.
+func (t Tree) WithWorkers(workers int) Tree {
+	t.workers = workers
+	return t
+}
+
+// Clone returns a copy of the tree that doesn't share the committed nodes, the history and the uncommitted changes
+// with the tree, so the commits and the rollbacks of each of them don't affect the other one
+func (t Tree) Clone() Tree {
+	nodes := make([][][]byte, len(t.nodes))
+	for i, layer := range t.nodes {
+		nodes[i] = append([][]byte{}, layer...)
+	}
+	t.nodes = nodes
+	t.history = append([]commitUndo{}, t.history...)
+	t.UncommittedLeaves = append([][]byte{}, t.UncommittedLeaves...)
+	if t.uncommittedUpdates != nil {
+		updates := make(map[uint64][]byte, len(t.uncommittedUpdates))
+		for index, hash := range t.uncommittedUpdates {
+			updates[index] = hash
+		}
+		t.uncommittedUpdates = updates
+	}
+	return t
+}
+
+// WithHistoryLimit returns the tree which keeps only the given number of the latest commits in its history, the older
+// commits can't be reverted by Rollback. A limit less than 1 keeps all of the commits, which is the default.
+func (t Tree) WithHistoryLimit(limit int) Tree {
+	t.historyLimit = limit
+	return t
+}
+
+// PartialTree represents a part of the original tree that is enough to calculate the root. The nodes of every layer
+// are kept sorted by their indices.
 // Used in to extract the root in a merkle proof, to apply diff to a tree or to merge
 // multiple trees into one.
 // It is a rare case when you need to use this struct on it's own. It's mostly used inside
 type PartialTree struct {
-	layers Layers
-	hasher types.Hasher
+	layers  Layers
+	hasher  types.Hasher
+	workers int
 }
 
 // NewPartialTree Takes hasher as an argument and build a Merkle Tree from them.
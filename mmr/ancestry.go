@@ -0,0 +1,143 @@
+package mmr
+
+import (
+	"bytes"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+// AncestryProof proves that an MMR of a previous size is a prefix of an MMR of a bigger size. The nodes of an MMR are
+// never updated when leaves are appended, so the peaks of the previous MMR are nodes of the current MMR and the proof
+// is a merkle proof of these nodes against the current root.
+type AncestryProof struct {
+	prevMMRSize uint64
+	prevPeaks   [][]byte
+	mmrSize     uint64
+	proofItems  [][]byte
+	Hasher      types.Hasher
+}
+
+// NewAncestryProof creates and returns new AncestryProof. It takes the previous mmr size with the hashes of its peaks,
+// the current mmr size with the proof items and any type that satisfies the Hasher interface.
+func NewAncestryProof(prevMMRSize uint64, prevPeaks [][]byte, mmrSize uint64, proofItems [][]byte, m types.Hasher) *AncestryProof {
+	return &AncestryProof{
+		prevMMRSize: prevMMRSize,
+		prevPeaks:   prevPeaks,
+		mmrSize:     mmrSize,
+		proofItems:  proofItems,
+		Hasher:      m,
+	}
+}
+
+// GenAncestryProof generates a proof that the MMR of size prevMMRSize is a prefix of the current MMR. The proof
+// contains the peaks of the previous MMR and a merkle proof of these peaks as the nodes of the current MMR.
+func (m *MMR) GenAncestryProof(prevMMRSize uint64) (*AncestryProof, error) {
+	if prevMMRSize > m.size || !isValidMMRSize(prevMMRSize) {
+		return nil, ErrGenAncestryProofForInvalidSize
+	}
+
+	prevPeaksPositions := GetPeaks(prevMMRSize)
+	prevPeaks := make([][]byte, len(prevPeaksPositions))
+	nodes := make([]peak, len(prevPeaksPositions))
+	for i := 0; i < len(prevPeaksPositions); i++ {
+		pos := prevPeaksPositions[i]
+		elem, err := m.batch.GetElem(pos)
+		if err != nil {
+			return nil, err
+		}
+		if elem == nil {
+			return nil, ErrInconsistentStore
+		}
+		prevPeaks[i] = elem
+		nodes[i] = peak{height: PosHeightInTree(pos), pos: pos}
+	}
+
+	proofItems, err := m.genNodesProof(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAncestryProof(prevMMRSize, prevPeaks, m.size, proofItems, m.hasher), nil
+}
+
+// PrevMMRSize returns the size of the previous mmr
+func (a *AncestryProof) PrevMMRSize() uint64 {
+	return a.prevMMRSize
+}
+
+// PrevPeaks returns the hashes of the peaks of the previous mmr, from left to right
+func (a *AncestryProof) PrevPeaks() [][]byte {
+	return a.prevPeaks
+}
+
+// MMRSize returns the size of the current mmr
+func (a *AncestryProof) MMRSize() uint64 {
+	return a.mmrSize
+}
+
+// ProofItems returns the proof items of the previous peaks in the current mmr
+func (a *AncestryProof) ProofItems() [][]byte {
+	return a.proofItems
+}
+
+// CalculatePrevRoot calculates the root of the previous mmr by bagging its peaks
+func (a *AncestryProof) CalculatePrevRoot() ([]byte, error) {
+	if err := a.validatePrevPeaks(); err != nil {
+		return nil, err
+	}
+	return bagPeaksHashes(a.Hasher, append([][]byte{}, a.prevPeaks...))
+}
+
+// CalculateRoot calculates the root of the current mmr using the previous peaks as its nodes and the proof items
+func (a *AncestryProof) CalculateRoot() ([]byte, error) {
+	if err := a.validatePrevPeaks(); err != nil {
+		return nil, err
+	}
+	if a.prevMMRSize > a.mmrSize || !isValidMMRSize(a.mmrSize) {
+		return nil, ErrCorruptedProof
+	}
+
+	prevPeaksPositions := GetPeaks(a.prevMMRSize)
+	nodes := make([]leafWithashOfH, len(prevPeaksPositions))
+	for i := 0; i < len(prevPeaksPositions); i++ {
+		pos := prevPeaksPositions[i]
+		nodes[i] = leafWithashOfH{pos, a.prevPeaks[i], PosHeightInTree(pos)}
+	}
+
+	proof := NewProof(a.mmrSize, a.proofItems, nil, a.Hasher)
+	peaksHashes, err := proof.calculateNodesPeaksHashes(nodes, a.mmrSize, proof.proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return bagPeaksHashes(a.Hasher, peaksHashes)
+}
+
+// Verify takes the root of the previous mmr and the root of the current mmr. It returns true if the previous peaks
+// bag into the previous root and the current root calculated from the previous peaks is equal to the given root.
+func (a *AncestryProof) Verify(prevRoot, root []byte) bool {
+	calculatedPrevRoot, err := a.CalculatePrevRoot()
+	if err != nil {
+		log.Errorf("previous root verification: %s \n", err.Error())
+		return false
+	}
+	if !bytes.Equal(calculatedPrevRoot, prevRoot) {
+		return false
+	}
+
+	calculatedRoot, err := a.CalculateRoot()
+	if err != nil {
+		log.Errorf("root verification: %s \n", err.Error())
+		return false
+	}
+
+	return bytes.Equal(calculatedRoot, root)
+}
+
+// validatePrevPeaks checks that the number of previous peaks matches the previous mmr size
+func (a *AncestryProof) validatePrevPeaks() error {
+	if !isValidMMRSize(a.prevMMRSize) || len(GetPeaks(a.prevMMRSize)) != len(a.prevPeaks) {
+		return ErrCorruptedProof
+	}
+	return nil
+}
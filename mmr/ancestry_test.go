@@ -0,0 +1,129 @@
+package mmr_test
+
+import (
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	merkleMmr "github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+func TestAncestryProof(t *testing.T) {
+	const maxLeaves = 40
+
+	var roots [][]byte
+	mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	for i := uint32(0); i < maxLeaves; i++ {
+		if _, err := mmrTree.Push(uint32ToHash(i)); err != nil {
+			t.Fatalf("push leaf %d: %s", i, err.Error())
+		}
+		root, err := mmrTree.Root()
+		if err != nil {
+			t.Fatalf("root of %d leaves: %s", i+1, err.Error())
+		}
+		roots = append(roots, root)
+	}
+
+	for size := uint64(1); size <= maxLeaves; size++ {
+		current := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+		pushLeaves(t, current, 0, uint32(size))
+
+		for prevSize := uint64(1); prevSize <= size; prevSize++ {
+			prevMMRSize := merkleMmr.LeafIndexToMMRSize(prevSize - 1)
+			proof, err := current.GenAncestryProof(prevMMRSize)
+			if err != nil {
+				t.Fatalf("gen ancestry proof %d -> %d: %s", prevSize, size, err.Error())
+			}
+
+			if len(proof.PrevPeaks()) != len(merkleMmr.GetPeaks(prevMMRSize)) {
+				t.Errorf("%d -> %d: want %d previous peaks got %d", prevSize, size,
+					len(merkleMmr.GetPeaks(prevMMRSize)), len(proof.PrevPeaks()))
+			}
+			if !proof.Verify(roots[prevSize-1], roots[size-1]) {
+				t.Errorf("%d -> %d: ancestry proof is not valid", prevSize, size)
+			}
+
+			// the proof can be verified more than once
+			if !proof.Verify(roots[prevSize-1], roots[size-1]) {
+				t.Errorf("%d -> %d: ancestry proof is not valid on the second verification", prevSize, size)
+			}
+
+			if prevSize > 1 && proof.Verify(roots[prevSize-2], roots[size-1]) {
+				t.Errorf("%d -> %d: ancestry proof is valid for a wrong previous root", prevSize, size)
+			}
+			if size < maxLeaves && proof.Verify(roots[prevSize-1], roots[size]) {
+				t.Errorf("%d -> %d: ancestry proof is valid for a wrong root", prevSize, size)
+			}
+		}
+	}
+}
+
+func TestAncestryProofTampered(t *testing.T) {
+	mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, mmrTree, 0, 7)
+	prevRoot, err := mmrTree.Root()
+	if err != nil {
+		t.Fatalf("previous root: %s", err.Error())
+	}
+	pushLeaves(t, mmrTree, 7, 19)
+	root, err := mmrTree.Root()
+	if err != nil {
+		t.Fatalf("root: %s", err.Error())
+	}
+
+	proof, err := mmrTree.GenAncestryProof(merkleMmr.LeafIndexToMMRSize(6))
+	if err != nil {
+		t.Fatalf("gen ancestry proof: %s", err.Error())
+	}
+	if !proof.Verify(prevRoot, root) {
+		t.Fatalf("ancestry proof is not valid")
+	}
+
+	// replace one of the previous peaks
+	prevPeaks := append([][]byte{}, proof.PrevPeaks()...)
+	prevPeaks[1] = uint32ToHash(100)
+	tampered := merkleMmr.NewAncestryProof(proof.PrevMMRSize(), prevPeaks, proof.MMRSize(), proof.ProofItems(), hasher.Keccak256Hasher{})
+	if tampered.Verify(prevRoot, root) {
+		t.Errorf("ancestry proof with a tampered previous peak is valid")
+	}
+
+	// drop one of the proof items
+	items := proof.ProofItems()
+	tampered = merkleMmr.NewAncestryProof(proof.PrevMMRSize(), proof.PrevPeaks(), proof.MMRSize(), items[:len(items)-1], hasher.Keccak256Hasher{})
+	if tampered.Verify(prevRoot, root) {
+		t.Errorf("ancestry proof with a missing proof item is valid")
+	}
+
+	// a previous peak is missing
+	tampered = merkleMmr.NewAncestryProof(proof.PrevMMRSize(), proof.PrevPeaks()[1:], proof.MMRSize(), items, hasher.Keccak256Hasher{})
+	if tampered.Verify(prevRoot, root) {
+		t.Errorf("ancestry proof with a missing previous peak is valid")
+	}
+}
+
+func TestGenAncestryProofForInvalidSize(t *testing.T) {
+	mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, mmrTree, 0, 11)
+
+	tests := map[string]uint64{
+		"empty mmr":         0,
+		"not an mmr size":   5,
+		"beyond mmr range":  merkleMmr.LeafIndexToMMRSize(11),
+		"beyond mmr range*": mmrTree.MMRSize() + 1,
+	}
+
+	for name, prevMMRSize := range tests {
+		if _, err := mmrTree.GenAncestryProof(prevMMRSize); err != merkleMmr.ErrGenAncestryProofForInvalidSize {
+			t.Errorf("%s: want %v got %v", name, merkleMmr.ErrGenAncestryProofForInvalidSize, err)
+		}
+	}
+}
+
+func TestMMRSizeToLeafCount(t *testing.T) {
+	for i := uint64(0); i < 100; i++ {
+		got := merkleMmr.MMRSizeToLeafCount(merkleMmr.LeafIndexToMMRSize(i))
+		if got != i+1 {
+			t.Errorf("leaf index %d: want %d got %d", i, i+1, got)
+		}
+	}
+}
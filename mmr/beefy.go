@@ -0,0 +1,114 @@
+package mmr
+
+import (
+	"encoding/binary"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	scaleU32Size = 4
+	// beefyAuthoritySetSize is the size of the SCALE encoded BeefyNextAuthoritySet
+	beefyAuthoritySetSize = scaleU64Size + scaleU32Size + scaleHashSize
+	// mmrLeafSize is the size of the SCALE encoded MmrLeaf without its extra data
+	mmrLeafSize = 1 + scaleU32Size + scaleHashSize + beefyAuthoritySetSize
+)
+
+// BeefyNextAuthoritySet is the BeefyNextAuthoritySet of Substrate, the details of the next BEEFY authority set. The
+// keyset commitment is the root of the merkle tree of the authority keys, it's called root in the older versions.
+type BeefyNextAuthoritySet struct {
+	ID               uint64
+	Len              uint32
+	KeysetCommitment []byte
+}
+
+// MmrLeaf is the MmrLeaf of the BEEFY mmr of Substrate, with u32 block numbers and H256 hashes. The leaf extra is the
+// SCALE encoded extra data of the chain, which is the 32 bytes root of the parachain heads on the Polkadot, Kusama and
+// Rococo relay chains.
+type MmrLeaf struct {
+	Version               uint8
+	ParentNumber          uint32
+	ParentHash            []byte
+	BeefyNextAuthoritySet BeefyNextAuthoritySet
+	LeafExtra             []byte
+}
+
+// Encode encodes the leaf into its SCALE layout:
+//
+//	version u8, parent_number u32, parent_hash H256,
+//	beefy_next_authority_set (id u64, len u32, keyset_commitment H256), leaf_extra
+func (l MmrLeaf) Encode() ([]byte, error) {
+	if len(l.ParentHash) != scaleHashSize || len(l.BeefyNextAuthoritySet.KeysetCommitment) != scaleHashSize {
+		return nil, ErrInvalidSCALEEncoding
+	}
+
+	data := make([]byte, 0, mmrLeafSize+len(l.LeafExtra))
+	data = append(data, l.Version)
+	data = appendU32(data, l.ParentNumber)
+	data = append(data, l.ParentHash...)
+	data = appendU64(data, l.BeefyNextAuthoritySet.ID)
+	data = appendU32(data, l.BeefyNextAuthoritySet.Len)
+	data = append(data, l.BeefyNextAuthoritySet.KeysetCommitment...)
+	return append(data, l.LeafExtra...), nil
+}
+
+// DecodeMmrLeaf decodes the SCALE encoded leaf, all of the data that follows the authority set is the leaf extra
+func DecodeMmrLeaf(data []byte) (MmrLeaf, error) {
+	if len(data) < mmrLeafSize {
+		return MmrLeaf{}, ErrInvalidSCALEEncoding
+	}
+	d := scaleDecoder{data: data}
+
+	version, _ := d.next(1)
+	parentNumber, _ := d.next(scaleU32Size)
+	parentHash, _ := d.next(scaleHashSize)
+	id, _ := d.u64()
+	authoritiesLen, _ := d.next(scaleU32Size)
+	keysetCommitment, _ := d.next(scaleHashSize)
+
+	return MmrLeaf{
+		Version:      version[0],
+		ParentNumber: binary.LittleEndian.Uint32(parentNumber),
+		ParentHash:   append([]byte{}, parentHash...),
+		BeefyNextAuthoritySet: BeefyNextAuthoritySet{
+			ID:               id,
+			Len:              binary.LittleEndian.Uint32(authoritiesLen),
+			KeysetCommitment: append([]byte{}, keysetCommitment...),
+		},
+		LeafExtra: append([]byte{}, d.data...),
+	}, nil
+}
+
+// Hash returns the hash of the leaf in the BEEFY mmr, the keccak256 hash of its SCALE encoding
+func (l MmrLeaf) Hash() ([]byte, error) {
+	data, err := l.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return hasher.Keccak256Hasher{}.Hash(data)
+}
+
+// VerifyLeaf verifies that the leaf is the proven leaf of the proof in the BEEFY mmr of the root. The proof must prove
+// a single leaf, for example a proof decoded by DecodeLeafProof, and the hash of the leaf is set on a copy of it, so
+// the proof is not modified.
+func VerifyLeaf(root []byte, leaf MmrLeaf, proof *Proof) (bool, error) {
+	if len(proof.Leaves) != 1 {
+		return false, ErrNotSingleLeafProof
+	}
+	hash, err := leaf.Hash()
+	if err != nil {
+		return false, err
+	}
+
+	leafProof := NewProof(proof.mmrSize, proof.ProofItems(), []types.Leaf{{Index: proof.Leaves[0].Index, Hash: hash}},
+		proof.Hasher)
+	return leafProof.Verify(root), nil
+}
+
+// appendU32 appends the little endian encoded number to the data
+func appendU32(data []byte, n uint32) []byte {
+	b := make([]byte, scaleU32Size)
+	binary.LittleEndian.PutUint32(b, n)
+	return append(data, b...)
+}
@@ -0,0 +1,206 @@
+package mmr_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	merkleMmr "github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func beefyLeaf(parentNumber uint32) merkleMmr.MmrLeaf {
+	return merkleMmr.MmrLeaf{
+		Version:      0,
+		ParentNumber: parentNumber,
+		ParentHash:   bytes.Repeat([]byte{byte(parentNumber)}, 32),
+		BeefyNextAuthoritySet: merkleMmr.BeefyNextAuthoritySet{
+			ID:               3,
+			Len:              17,
+			KeysetCommitment: bytes.Repeat([]byte{0xbb}, 32),
+		},
+		LeafExtra: bytes.Repeat([]byte{0xcc}, 32),
+	}
+}
+
+func TestMmrLeafSCALELayout(t *testing.T) {
+	leaf := beefyLeaf(0x0102)
+	leaf.Version = 1
+
+	data, err := leaf.Encode()
+	if err != nil {
+		t.Fatalf("encode leaf: %s", err.Error())
+	}
+	want := "01" + "02010000" + strings.Repeat("02", 32) + "0300000000000000" + "11000000" + strings.Repeat("bb", 32) +
+		strings.Repeat("cc", 32)
+	if hex.EncodeToString(data) != want {
+		t.Errorf("want %s got %x", want, data)
+	}
+
+	decoded, err := merkleMmr.DecodeMmrLeaf(data)
+	if err != nil {
+		t.Fatalf("decode leaf: %s", err.Error())
+	}
+	if !reflect.DeepEqual(leaf, decoded) {
+		t.Errorf("want %+v got %+v", leaf, decoded)
+	}
+
+	hash, err := leaf.Hash()
+	if err != nil {
+		t.Fatalf("hash leaf: %s", err.Error())
+	}
+	if want, _ := (hasher.Keccak256Hasher{}).Hash(data); !bytes.Equal(want, hash) {
+		t.Errorf("want the keccak256 hash of the encoded leaf %x got %x", want, hash)
+	}
+
+	if _, err := merkleMmr.DecodeMmrLeaf(data[:76]); !errors.Is(err, merkleMmr.ErrInvalidSCALEEncoding) {
+		t.Errorf("want ErrInvalidSCALEEncoding for a short leaf got %v", err)
+	}
+	leaf.ParentHash = leaf.ParentHash[1:]
+	if _, err := leaf.Encode(); !errors.Is(err, merkleMmr.ErrInvalidSCALEEncoding) {
+		t.Errorf("want ErrInvalidSCALEEncoding for a short parent hash got %v", err)
+	}
+}
+
+func TestVerifyLeaf(t *testing.T) {
+	mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	var positions []uint64
+	for i := uint32(0); i < 11; i++ {
+		hash, err := beefyLeaf(i).Hash()
+		if err != nil {
+			t.Fatalf("hash leaf %d: %s", i, err.Error())
+		}
+		position, err := mmrTree.Push(hash)
+		if err != nil {
+			t.Fatalf("push leaf %d: %s", i, err.Error())
+		}
+		positions = append(positions, position)
+	}
+	root, err := mmrTree.Root()
+	if err != nil {
+		t.Fatalf("root: %s", err.Error())
+	}
+
+	for i := uint32(0); i < 11; i++ {
+		proof, err := mmrTree.GenProof([]uint64{positions[i]})
+		if err != nil {
+			t.Fatalf("generate proof of leaf %d: %s", i, err.Error())
+		}
+		proof.LeavesToVerify([]types.Leaf{{Index: uint64(i)}})
+
+		// the proofs of the mmr_generateProof RPC are SCALE encoded without the leaf hash
+		data, err := proof.EncodeLeafProof()
+		if err != nil {
+			t.Fatalf("encode proof of leaf %d: %s", i, err.Error())
+		}
+		decoded, err := merkleMmr.DecodeLeafProof(data, hasher.Keccak256Hasher{})
+		if err != nil {
+			t.Fatalf("decode proof of leaf %d: %s", i, err.Error())
+		}
+
+		verified, err := merkleMmr.VerifyLeaf(root, beefyLeaf(i), decoded)
+		if err != nil || !verified {
+			t.Errorf("the proof of leaf %d is not verified: %v", i, err)
+		}
+		if decoded.Leaves[0].Hash != nil {
+			t.Errorf("the proof of leaf %d is modified", i)
+		}
+
+		verified, err = merkleMmr.VerifyLeaf(root, beefyLeaf(i+1), decoded)
+		if err != nil || verified {
+			t.Errorf("the proof of leaf %d verifies a different leaf: %v", i, err)
+		}
+	}
+
+	proof, err := mmrTree.GenProof(positions[:2])
+	if err != nil {
+		t.Fatalf("generate proof: %s", err.Error())
+	}
+	proof.LeavesToVerify([]types.Leaf{{Index: 0}, {Index: 1}})
+	if _, err := merkleMmr.VerifyLeaf(root, beefyLeaf(0), proof); !errors.Is(err, merkleMmr.ErrNotSingleLeafProof) {
+		t.Errorf("want ErrNotSingleLeafProof for a proof of two leaves got %v", err)
+	}
+}
+
+// beefyFixture is a leaf of the BEEFY mmr of a relay chain with its proof, captured from a node of the chain. The leaf
+// is the encoded MmrLeaf without the length prefix of the opaque leaf returned by mmr_generateProof, the proof is
+// the SCALE encoded proof of the same call and the root is returned by mmr_root at the block of the proof.
+type beefyFixture struct {
+	Chain       string        `json:"chain"`
+	BlockNumber uint64        `json:"blockNumber"`
+	BlockHash   hexutil.Bytes `json:"blockHash"`
+	LeafIndex   uint64        `json:"leafIndex"`
+	Leaf        hexutil.Bytes `json:"leaf"`
+	LeafHash    hexutil.Bytes `json:"leafHash"`
+	Proof       hexutil.Bytes `json:"proof"`
+	BatchProof  bool          `json:"batchProof"`
+	MmrRoot     hexutil.Bytes `json:"mmrRoot"`
+}
+
+// TestBeefyFixtures decodes, hashes and verifies the leaves of the fixtures in testdata/beefy against the mmr roots
+// of their chains. It fails without fixtures when the CI variable is set, so the leaves of a live chain are always
+// verified in CI.
+func TestBeefyFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "beefy", "*.json"))
+	if err != nil {
+		t.Fatalf("list fixtures: %s", err.Error())
+	}
+	if len(paths) == 0 {
+		if os.Getenv("CI") != "" {
+			t.Fatal("there are no BEEFY fixtures in testdata/beefy, they must be verified in CI")
+		}
+		t.Skip("there are no BEEFY fixtures in testdata/beefy")
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %s", path, err.Error())
+		}
+		var fixture beefyFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			t.Fatalf("unmarshal %s: %s", path, err.Error())
+		}
+
+		leaf, err := merkleMmr.DecodeMmrLeaf(fixture.Leaf)
+		if err != nil {
+			t.Fatalf("%s: decode leaf: %s", path, err.Error())
+		}
+		if encoded, err := leaf.Encode(); err != nil || !bytes.Equal(encoded, fixture.Leaf) {
+			t.Errorf("%s: the leaf is not encoded back to the fixture leaf: %x, %v", path, encoded, err)
+		}
+		if hash, err := leaf.Hash(); err != nil || !bytes.Equal(hash, fixture.LeafHash) {
+			t.Errorf("%s: leaf hash: want %x got %x, %v", path, []byte(fixture.LeafHash), hash, err)
+		}
+
+		decode := merkleMmr.DecodeLeafProof
+		if fixture.BatchProof {
+			decode = merkleMmr.DecodeLeafBatchProof
+		}
+		proof, err := decode(fixture.Proof, hasher.Keccak256Hasher{})
+		if err != nil {
+			t.Fatalf("%s: decode proof: %s", path, err.Error())
+		}
+		if len(proof.Leaves) != 1 || proof.Leaves[0].Index != fixture.LeafIndex {
+			t.Fatalf("%s: the proof doesn't prove the leaf %d", path, fixture.LeafIndex)
+		}
+
+		verified, err := merkleMmr.VerifyLeaf(fixture.MmrRoot, leaf, proof)
+		if err != nil || !verified {
+			t.Errorf("%s: the leaf of %s block %d is not verified against the mmr root: %v", path, fixture.Chain,
+				fixture.BlockNumber, err)
+		}
+		leaf.ParentNumber++
+		if verified, _ := merkleMmr.VerifyLeaf(fixture.MmrRoot, leaf, proof); verified {
+			t.Errorf("%s: a changed leaf is verified against the mmr root", path)
+		}
+	}
+}
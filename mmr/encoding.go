@@ -0,0 +1,63 @@
+package mmr
+
+import (
+	"encoding/json"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// proofJSON is the JSON representation of Proof
+type proofJSON struct {
+	MMRSize    uint64          `json:"mmrSize"`
+	Leaves     []types.Leaf    `json:"leaves"`
+	ProofItems []hexutil.Bytes `json:"proofItems"`
+}
+
+// MarshalJSON encodes the proof into JSON with 0x prefixed hex hashes and decimal leaf indices:
+//
+//	{"mmrSize": 11, "leaves": [{"index": 1, "hash": "0x..."}], "proofItems": ["0x..."]}
+func (m Proof) MarshalJSON() ([]byte, error) {
+	leaves := m.Leaves
+	if leaves == nil {
+		leaves = []types.Leaf{}
+	}
+	var proofItems [][]byte
+	if m.proof != nil {
+		proofItems = m.proof.Items
+	}
+
+	return json.Marshal(proofJSON{
+		MMRSize:    m.mmrSize,
+		Leaves:     leaves,
+		ProofItems: types.HexList(proofItems),
+	})
+}
+
+// UnmarshalJSON decodes the proof from the JSON format of MarshalJSON. The hasher of the proof is kept, so the proof
+// must be created with NewProof and the hasher of the mmr before it's decoded, otherwise ErrMissingHasher is returned.
+// It returns ErrInvalidProofEncoding if the mmr size is not a valid mmr size or a leaf index is beyond the mmr range.
+func (m *Proof) UnmarshalJSON(data []byte) error {
+	if m.Hasher == nil {
+		return ErrMissingHasher
+	}
+	var decoded proofJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	if !isValidMMRSize(decoded.MMRSize) {
+		return ErrInvalidProofEncoding
+	}
+	leavesCount := MMRSizeToLeafCount(decoded.MMRSize)
+	for i := 0; i < len(decoded.Leaves); i++ {
+		if decoded.Leaves[i].Index >= leavesCount {
+			return ErrInvalidProofEncoding
+		}
+	}
+
+	m.mmrSize = decoded.MMRSize
+	m.proof = &Iterator{Items: types.BytesList(decoded.ProofItems)}
+	m.Leaves = decoded.Leaves
+	return nil
+}
@@ -0,0 +1,110 @@
+package mmr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	merkleMmr "github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+func TestProofJSONRoundTrip(t *testing.T) {
+	leaves := []types.Leaf{{Index: 3, Hash: uint32ToHash(3)}, {Index: 5, Hash: uint32ToHash(5)}}
+	mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), leaves, hasher.Keccak256Hasher{})
+	positions := pushLeaves(t, mmrTree, 0, 11)
+	root, err := mmrTree.Root()
+	if err != nil {
+		t.Fatalf("root: %s", err.Error())
+	}
+
+	proof, err := mmrTree.GenProof([]uint64{positions[3], positions[5]})
+	if err != nil {
+		t.Fatalf("gen proof: %s", err.Error())
+	}
+
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("marshal proof: %s", err.Error())
+	}
+
+	decoded := merkleMmr.NewProof(0, nil, nil, hasher.Keccak256Hasher{})
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("unmarshal proof: %s", err.Error())
+	}
+	if decoded.MMRSize() != proof.MMRSize() {
+		t.Errorf("want mmr size %d got %d", proof.MMRSize(), decoded.MMRSize())
+	}
+	if !decoded.Verify(root) {
+		t.Errorf("decoded proof is not valid")
+	}
+
+	// the encoding is stable
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("marshal decoded proof: %s", err.Error())
+	}
+	if string(encoded) != string(data) {
+		t.Errorf("want %s got %s", data, encoded)
+	}
+}
+
+func TestProofJSONFormat(t *testing.T) {
+	proof := merkleMmr.NewProof(4, [][]byte{{0xab, 0xcd}}, []types.Leaf{{Index: 2, Hash: []byte{0x01}}},
+		hasher.Keccak256Hasher{})
+
+	data, err := json.Marshal(proof)
+	if err != nil {
+		t.Fatalf("marshal proof: %s", err.Error())
+	}
+	want := `{"mmrSize":4,"leaves":[{"index":2,"hash":"0x01"}],"proofItems":["0xabcd"]}`
+	if string(data) != want {
+		t.Errorf("want %s got %s", want, data)
+	}
+
+	// the proof values and the proofs embedded in other values are encoded in the same format
+	data, err = json.Marshal(struct{ Proof merkleMmr.Proof }{*proof})
+	if err != nil {
+		t.Fatalf("marshal proof value: %s", err.Error())
+	}
+	if want := `{"Proof":` + want + `}`; string(data) != want {
+		t.Errorf("want %s got %s", want, data)
+	}
+}
+
+func TestProofJSONInvalidInput(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		wantErr error
+	}{
+		"invalid mmr size":      {`{"mmrSize":2,"leaves":[],"proofItems":[]}`, merkleMmr.ErrInvalidProofEncoding},
+		"zero mmr size":         {`{"mmrSize":0,"leaves":[],"proofItems":[]}`, merkleMmr.ErrInvalidProofEncoding},
+		"leaf beyond mmr range": {`{"mmrSize":4,"leaves":[{"index":3,"hash":"0x01"}],"proofItems":[]}`, merkleMmr.ErrInvalidProofEncoding},
+		"missing leaf hash":     {`{"mmrSize":4,"leaves":[{"index":1}],"proofItems":[]}`, nil},
+		"hash without prefix":   {`{"mmrSize":4,"leaves":[],"proofItems":["abcd"]}`, nil},
+		"odd hex length":        {`{"mmrSize":4,"leaves":[],"proofItems":["0xabc"]}`, nil},
+		"negative index":        {`{"mmrSize":4,"leaves":[{"index":-1,"hash":"0x01"}],"proofItems":[]}`, nil},
+	}
+
+	for name, test := range tests {
+		proof := merkleMmr.NewProof(0, nil, nil, hasher.Keccak256Hasher{})
+		err := json.Unmarshal([]byte(test.data), proof)
+		if err == nil {
+			t.Errorf("%s: want an error", name)
+			continue
+		}
+		if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+			t.Errorf("%s: want %s got %s", name, test.wantErr.Error(), err.Error())
+		}
+	}
+}
+
+func TestProofJSONWithoutHasher(t *testing.T) {
+	// a zero proof has no hasher to calculate the root of the decoded proof with
+	var proof merkleMmr.Proof
+	data := `{"mmrSize":4,"leaves":[{"index":2,"hash":"0x01"}],"proofItems":["0xabcd"]}`
+	if err := json.Unmarshal([]byte(data), &proof); !errors.Is(err, merkleMmr.ErrMissingHasher) {
+		t.Errorf("want %v got %v", merkleMmr.ErrMissingHasher, err)
+	}
+}
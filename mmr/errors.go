@@ -15,3 +15,45 @@ var ErrInconsistentStore = errors.New("inconsistent store")
 
 // ErrGetRootOnEmpty is of the type error. It is returned when the MMR is empty
 var ErrGetRootOnEmpty = errors.New("get root on an empty MMR")
+
+// ErrInvalidNodeSize is of the type error. It is returned when a node doesn't match the node size of the store
+var ErrInvalidNodeSize = errors.New("invalid node size for the store")
+
+// ErrNonSequentialAppend is of the type error. It is returned when elements are appended to an append-only store
+// at a position other than the end of the store
+var ErrNonSequentialAppend = errors.New("elements must be appended at the end of the store")
+
+// ErrCorruptedStore is of the type error. It is returned when the persisted store files are damaged
+var ErrCorruptedStore = errors.New("corrupted store files")
+
+// ErrGenAncestryProofForInvalidSize is of the type error. It is returned when the previous mmr size is not a valid mmr
+// size or is bigger than the current mmr size
+var ErrGenAncestryProofForInvalidSize = errors.New("previous mmr size is invalid or beyond the mmr range")
+
+// ErrInvalidProofEncoding is of the type error. It is returned when a decoded proof has an invalid mmr size or its
+// leaves are beyond the mmr range
+var ErrInvalidProofEncoding = errors.New("invalid proof encoding: invalid mmr size or leaves beyond the mmr range")
+
+// ErrInvalidSCALEEncoding is of the type error. It is returned when a Substrate SCALE encoded proof is malformed, or a
+// proof can't be encoded into the Substrate layout
+var ErrInvalidSCALEEncoding = errors.New("invalid SCALE encoding of the Substrate mmr proof")
+
+// ErrNotSingleLeafProof is of the type error. It is returned when a proof of a single leaf is expected, but the proof
+// has no leaves or several leaves
+var ErrNotSingleLeafProof = errors.New("the proof doesn't prove a single leaf")
+
+// ErrInvalidProofUpdate is of the type error. It is returned when the previous mmr size of a proof update is invalid or
+// doesn't match the proof, or the update doesn't have the nodes needed to update the proof
+var ErrInvalidProofUpdate = errors.New("invalid proof update: mmr sizes don't match or the proof nodes are missing")
+
+// ErrMissingHasher is of the type error. It is returned when a proof without a hasher is decoded, since the decoded
+// proof couldn't be verified
+var ErrMissingHasher = errors.New("the proof has no hasher, it must be created with NewProof")
+
+// ErrInvalidLeavesData is of the type error. It is returned when the number of the leaves data doesn't match the leaves
+// of the proof
+var ErrInvalidLeavesData = errors.New("the leaves data doesn't match the proof leaves")
+
+// ErrHasherMismatch is of the type error. It is returned when a file store is opened with a hasher other than the
+// hasher recorded in the store, or the hasher name is too long to be recorded
+var ErrHasherMismatch = errors.New("the hasher doesn't match the hasher of the store")
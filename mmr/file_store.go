@@ -0,0 +1,291 @@
+package mmr
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// fileStoreNodesFile is the name of the flat file that keeps the fixed-size nodes
+	fileStoreNodesFile = "nodes"
+	// fileStoreIndexFile is the name of the file that keeps the number of committed nodes
+	fileStoreIndexFile = "index"
+
+	fileStoreIndexMagic = "MMRI"
+	// fileStoreIndexVersion is the version of the index files written by the store, the version 1 index files
+	// without the hasher name are still read
+	fileStoreIndexVersion = 2
+	// magic (4) + version (4) + node size (4) + nodes count (8)
+	fileStoreIndexHeaderSize = 20
+	// the version 1 header + crc32 checksum (4)
+	fileStoreIndexV1Size = 24
+	// fileStoreMaxHasherName is the maximum length of the hasher name, which is prefixed by a length byte
+	fileStoreMaxHasherName = 255
+	fileStoreFilePerm      = 0o600
+	fileStoreDirPerm   = 0o750
+)
+
+// FileStore is an append-only Store that keeps the MMR nodes in a flat file of fixed-size nodes. The number
+// of committed nodes is kept in a separate index file which is replaced atomically after the nodes are
+// synced to the disk, so a crash in the middle of an Append never exposes a partially written batch: the
+// nodes written after the last index update are discarded when the store is opened again.
+type FileStore struct {
+	mu       sync.RWMutex
+	dir      string
+	nodeSize int
+	// hasherName is the name of the hasher of the MMR recorded in the index file, it's empty when it's unknown
+	hasherName string
+	nodesFile  *os.File
+	size       uint64
+}
+
+// OpenFileStore opens the file store in the given directory, creating it when it does not exist. All of the
+// nodes of the store must be nodeSize bytes long, which is usually the output size of the hasher. To reopen an
+// MMR use the store size as the MMR size:
+//
+//	store, err := mmr.OpenFileStore(dir, 32)
+//	tree := mmr.NewMMR(store.Size(), store, leaves, hasher.Keccak256Hasher{})
+func OpenFileStore(dir string, nodeSize int) (*FileStore, error) {
+	return OpenFileStoreWithHasher(dir, nodeSize, "")
+}
+
+// OpenFileStoreWithHasher opens the file store like OpenFileStore and records the hasher name in the index file, so
+// the store can't be reopened with another hasher. An empty name, or a store without a recorded name, matches any
+// hasher.
+func OpenFileStoreWithHasher(dir string, nodeSize int, hasherName string) (*FileStore, error) {
+	if nodeSize <= 0 {
+		return nil, ErrInvalidNodeSize
+	}
+	if len(hasherName) > fileStoreMaxHasherName {
+		return nil, ErrHasherMismatch
+	}
+
+	if err := os.MkdirAll(dir, fileStoreDirPerm); err != nil {
+		return nil, err
+	}
+
+	size, storedHasherName, err := readFileStoreIndex(filepath.Join(dir, fileStoreIndexFile), nodeSize)
+	if err != nil {
+		return nil, err
+	}
+	if storedHasherName != "" {
+		if hasherName != "" && hasherName != storedHasherName {
+			return nil, ErrHasherMismatch
+		}
+		hasherName = storedHasherName
+	}
+
+	nodesFile, err := os.OpenFile(filepath.Join(dir, fileStoreNodesFile), os.O_RDWR|os.O_CREATE, fileStoreFilePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &FileStore{
+		dir:        dir,
+		nodeSize:   nodeSize,
+		hasherName: hasherName,
+		nodesFile:  nodesFile,
+		size:       size,
+	}
+
+	// drop the nodes that were written after the last committed index, if any
+	if err := s.recover(); err != nil {
+		nodesFile.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// recover truncates the nodes file to the size recorded in the index file
+func (s *FileStore) recover() error {
+	info, err := s.nodesFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	committedLen := int64(s.size) * int64(s.nodeSize)
+	switch {
+	case info.Size() < committedLen:
+		return ErrCorruptedStore
+	case info.Size() > committedLen:
+		if err := s.nodesFile.Truncate(committedLen); err != nil {
+			return err
+		}
+		return s.nodesFile.Sync()
+	default:
+		return nil
+	}
+}
+
+// HasherName returns the name of the hasher recorded in the store, it's empty when it's unknown
+func (s *FileStore) HasherName() string {
+	return s.hasherName
+}
+
+// Size returns the number of committed nodes, which is the size of the MMR kept in the store
+func (s *FileStore) Size() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.size
+}
+
+// GetElem returns the node at the given position. It returns nil if the position is not committed yet.
+func (s *FileStore) GetElem(pos uint64) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if pos >= s.size {
+		return nil, nil
+	}
+
+	elem := make([]byte, s.nodeSize)
+	if _, err := s.nodesFile.ReadAt(elem, int64(pos)*int64(s.nodeSize)); err != nil {
+		return nil, err
+	}
+
+	return elem, nil
+}
+
+// Append writes the elements to the end of the store. The position must be equal to the store size, since the
+// store is append-only. The elements are visible to the readers and survive a crash only after Append returns.
+func (s *FileStore) Append(pos uint64, elems [][]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pos != s.size {
+		return ErrNonSequentialAppend
+	}
+	if len(elems) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, 0, len(elems)*s.nodeSize)
+	for i := 0; i < len(elems); i++ {
+		if len(elems[i]) != s.nodeSize {
+			return ErrInvalidNodeSize
+		}
+		buf = append(buf, elems[i]...)
+	}
+
+	// write and sync the nodes before they are referenced by the index
+	if _, err := s.nodesFile.WriteAt(buf, int64(pos)*int64(s.nodeSize)); err != nil {
+		return err
+	}
+	if err := s.nodesFile.Sync(); err != nil {
+		return err
+	}
+
+	newSize := pos + uint64(len(elems))
+	if err := s.writeIndex(newSize); err != nil {
+		return err
+	}
+
+	s.size = newSize
+	return nil
+}
+
+// Close closes the underlying files of the store
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.nodesFile.Close()
+}
+
+// writeIndex replaces the index file atomically by writing a temporary file and renaming it
+func (s *FileStore) writeIndex(size uint64) error {
+	indexPath := filepath.Join(s.dir, fileStoreIndexFile)
+	tmpPath := indexPath + ".tmp"
+
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileStoreFilePerm)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(encodeFileStoreIndex(s.nodeSize, size, s.hasherName)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		return err
+	}
+
+	return syncDir(s.dir)
+}
+
+// encodeFileStoreIndex serializes the index file content
+func encodeFileStoreIndex(nodeSize int, size uint64, hasherName string) []byte {
+	b := make([]byte, fileStoreIndexHeaderSize, fileStoreIndexHeaderSize+1+len(hasherName)+4)
+	copy(b[0:4], fileStoreIndexMagic)
+	binary.BigEndian.PutUint32(b[4:8], fileStoreIndexVersion)
+	binary.BigEndian.PutUint32(b[8:12], uint32(nodeSize))
+	binary.BigEndian.PutUint64(b[12:20], size)
+	b = append(b, byte(len(hasherName)))
+	b = append(b, hasherName...)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(b))
+	return append(b, checksum...)
+}
+
+// readFileStoreIndex reads the number of committed nodes and the hasher name from the index file. A missing index
+// file means that the store is empty.
+func readFileStoreIndex(path string, nodeSize int) (uint64, string, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	if len(b) < fileStoreIndexV1Size || string(b[0:4]) != fileStoreIndexMagic {
+		return 0, "", ErrCorruptedStore
+	}
+
+	var hasherName string
+	switch binary.BigEndian.Uint32(b[4:8]) {
+	case 1:
+		if len(b) != fileStoreIndexV1Size {
+			return 0, "", ErrCorruptedStore
+		}
+	case fileStoreIndexVersion:
+		nameLen := int(b[fileStoreIndexHeaderSize])
+		if len(b) != fileStoreIndexHeaderSize+1+nameLen+4 {
+			return 0, "", ErrCorruptedStore
+		}
+		hasherName = string(b[fileStoreIndexHeaderSize+1 : fileStoreIndexHeaderSize+1+nameLen])
+	default:
+		return 0, "", ErrCorruptedStore
+	}
+
+	checksumAt := len(b) - 4
+	if binary.BigEndian.Uint32(b[checksumAt:]) != crc32.ChecksumIEEE(b[:checksumAt]) {
+		return 0, "", ErrCorruptedStore
+	}
+
+	if int(binary.BigEndian.Uint32(b[8:12])) != nodeSize {
+		return 0, "", ErrInvalidNodeSize
+	}
+
+	return binary.BigEndian.Uint64(b[12:20]), hasherName, nil
+}
+
+// syncDir syncs the directory entries, so the renamed index file survives a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,280 @@
+package mmr_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	merkleMmr "github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const testNodeSize = 32
+
+func pushLeaves(t *testing.T, mmrTree *merkleMmr.MMR, from, to uint32) []uint64 {
+	var positions []uint64
+	for i := from; i < to; i++ {
+		position, err := mmrTree.Push(uint32ToHash(i))
+		if err != nil {
+			t.Fatalf("push leaf %d: %s", i, err.Error())
+		}
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	memTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, memTree, 0, 20)
+	want, err := memTree.Root()
+	if err != nil {
+		t.Fatalf("mem store root: %s", err.Error())
+	}
+
+	store, err := merkleMmr.OpenFileStore(dir, testNodeSize)
+	if err != nil {
+		t.Fatalf("open file store: %s", err.Error())
+	}
+	fileTree := merkleMmr.NewMMR(store.Size(), store, []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, fileTree, 0, 11)
+	if err := fileTree.Commit(); err != nil {
+		t.Fatalf("commit: %s", err.Error())
+	}
+	// committing twice must not write the batch again
+	if err := fileTree.Commit(); err != nil {
+		t.Fatalf("second commit: %s", err.Error())
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("close: %s", err.Error())
+	}
+
+	store, err = merkleMmr.OpenFileStore(dir, testNodeSize)
+	if err != nil {
+		t.Fatalf("reopen file store: %s", err.Error())
+	}
+	defer store.Close()
+	if store.Size() != merkleMmr.LeafIndexToMMRSize(10) {
+		t.Fatalf("store size: want %d got %d", merkleMmr.LeafIndexToMMRSize(10), store.Size())
+	}
+
+	fileTree = merkleMmr.NewMMR(store.Size(), store, []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, fileTree, 11, 20)
+	if err := fileTree.Commit(); err != nil {
+		t.Fatalf("commit: %s", err.Error())
+	}
+
+	got, err := fileTree.Root()
+	if err != nil {
+		t.Fatalf("file store root: %s", err.Error())
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("root: want %x got %x", want, got)
+	}
+
+	proof, err := fileTree.GenProof([]uint64{merkleMmr.LeafIndexToPos(5)})
+	if err != nil {
+		t.Fatalf("gen proof: %s", err.Error())
+	}
+	proof.LeavesToVerify([]types.Leaf{{Index: 5, Hash: uint32ToHash(5)}})
+	if !proof.Verify(got) {
+		t.Errorf("proof from the reopened store is not valid")
+	}
+}
+
+func TestFileStoreDiscardsUncommittedNodes(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := merkleMmr.OpenFileStore(dir, testNodeSize)
+	if err != nil {
+		t.Fatalf("open file store: %s", err.Error())
+	}
+	fileTree := merkleMmr.NewMMR(0, store, []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, fileTree, 0, 7)
+	if err := fileTree.Commit(); err != nil {
+		t.Fatalf("commit: %s", err.Error())
+	}
+	size := store.Size()
+	store.Close()
+
+	// simulate a crash after the nodes were written but before the index was replaced
+	nodes, err := os.OpenFile(filepath.Join(dir, "nodes"), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatalf("open nodes file: %s", err.Error())
+	}
+	if _, err := nodes.Write(make([]byte, testNodeSize*3+5)); err != nil {
+		t.Fatalf("write nodes file: %s", err.Error())
+	}
+	nodes.Close()
+
+	store, err = merkleMmr.OpenFileStore(dir, testNodeSize)
+	if err != nil {
+		t.Fatalf("reopen file store: %s", err.Error())
+	}
+	defer store.Close()
+	if store.Size() != size {
+		t.Errorf("store size: want %d got %d", size, store.Size())
+	}
+
+	elem, err := store.GetElem(size)
+	if err != nil || elem != nil {
+		t.Errorf("uncommitted elem: want nil got %x, %v", elem, err)
+	}
+
+	if err := store.Append(size, [][]byte{uint32ToHash(100)}); err != nil {
+		t.Errorf("append after recovery: %s", err.Error())
+	}
+}
+
+// partialStore writes only a part of the elements of the first Append and fails
+type partialStore struct {
+	*merkleMmr.FileStore
+	failed bool
+}
+
+func (s *partialStore) Append(pos uint64, elems [][]byte) error {
+	if s.failed {
+		return s.FileStore.Append(pos, elems)
+	}
+	s.failed = true
+	if err := s.FileStore.Append(pos, elems[:len(elems)/2]); err != nil {
+		return err
+	}
+	return errors.New("disk full")
+}
+
+func TestFileStoreRetriesPartialCommit(t *testing.T) {
+	store, err := merkleMmr.OpenFileStore(t.TempDir(), testNodeSize)
+	if err != nil {
+		t.Fatalf("open file store: %s", err.Error())
+	}
+	defer store.Close()
+
+	memTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, memTree, 0, 9)
+	want, err := memTree.Root()
+	if err != nil {
+		t.Fatalf("mem store root: %s", err.Error())
+	}
+
+	fileTree := merkleMmr.NewMMR(0, &partialStore{FileStore: store}, []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, fileTree, 0, 9)
+	if err := fileTree.Commit(); err == nil {
+		t.Fatalf("want an error of the partial commit")
+	}
+	if store.Size() == 0 || store.Size() == fileTree.MMRSize() {
+		t.Fatalf("store size after the partial commit: %d", store.Size())
+	}
+
+	// the retry appends only the elements that were not written
+	if err := fileTree.Commit(); err != nil {
+		t.Fatalf("retry commit: %s", err.Error())
+	}
+	if store.Size() != fileTree.MMRSize() {
+		t.Errorf("store size: want %d got %d", fileTree.MMRSize(), store.Size())
+	}
+	reopened := merkleMmr.NewMMR(store.Size(), store, []types.Leaf{}, hasher.Keccak256Hasher{})
+	got, err := reopened.Root()
+	if err != nil {
+		t.Fatalf("file store root: %s", err.Error())
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("root: want %x got %x", want, got)
+	}
+}
+
+func TestFileStoreErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := merkleMmr.OpenFileStore(dir, 0); err != merkleMmr.ErrInvalidNodeSize {
+		t.Errorf("zero node size: want %v got %v", merkleMmr.ErrInvalidNodeSize, err)
+	}
+
+	store, err := merkleMmr.OpenFileStore(dir, testNodeSize)
+	if err != nil {
+		t.Fatalf("open file store: %s", err.Error())
+	}
+
+	if err := store.Append(1, [][]byte{uint32ToHash(1)}); err != merkleMmr.ErrNonSequentialAppend {
+		t.Errorf("append out of order: want %v got %v", merkleMmr.ErrNonSequentialAppend, err)
+	}
+	if err := store.Append(0, [][]byte{{1, 2, 3}}); err != merkleMmr.ErrInvalidNodeSize {
+		t.Errorf("append short node: want %v got %v", merkleMmr.ErrInvalidNodeSize, err)
+	}
+	if err := store.Append(0, [][]byte{uint32ToHash(1)}); err != nil {
+		t.Fatalf("append: %s", err.Error())
+	}
+	store.Close()
+
+	if _, err := merkleMmr.OpenFileStore(dir, testNodeSize*2); err != merkleMmr.ErrInvalidNodeSize {
+		t.Errorf("reopen with other node size: want %v got %v", merkleMmr.ErrInvalidNodeSize, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "index"), []byte("broken index"), 0o600); err != nil {
+		t.Fatalf("write index: %s", err.Error())
+	}
+	if _, err := merkleMmr.OpenFileStore(dir, testNodeSize); err != merkleMmr.ErrCorruptedStore {
+		t.Errorf("broken index: want %v got %v", merkleMmr.ErrCorruptedStore, err)
+	}
+}
+
+func TestFileStoreHasherName(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := merkleMmr.OpenFileStoreWithHasher(dir, testNodeSize, "keccak256")
+	if err != nil {
+		t.Fatalf("open file store: %s", err.Error())
+	}
+	if err := store.Append(0, [][]byte{uint32ToHash(1)}); err != nil {
+		t.Fatalf("append: %s", err.Error())
+	}
+	store.Close()
+
+	if _, err := merkleMmr.OpenFileStoreWithHasher(dir, testNodeSize, "sha256"); err != merkleMmr.ErrHasherMismatch {
+		t.Errorf("reopen with other hasher: want %v got %v", merkleMmr.ErrHasherMismatch, err)
+	}
+
+	// the store opened without a hasher name keeps the recorded name
+	store, err = merkleMmr.OpenFileStore(dir, testNodeSize)
+	if err != nil {
+		t.Fatalf("reopen file store: %s", err.Error())
+	}
+	if err := store.Append(1, [][]byte{uint32ToHash(2)}); err != nil {
+		t.Fatalf("append: %s", err.Error())
+	}
+	store.Close()
+
+	store, err = merkleMmr.OpenFileStoreWithHasher(dir, testNodeSize, "keccak256")
+	if err != nil {
+		t.Fatalf("reopen with the same hasher: %s", err.Error())
+	}
+	if store.Size() != 2 || store.HasherName() != "keccak256" {
+		t.Errorf("want 2 nodes of keccak256 got %d nodes of %q", store.Size(), store.HasherName())
+	}
+	store.Close()
+
+	// the version 1 index files without the hasher name are still read
+	index := make([]byte, 24)
+	copy(index, "MMRI")
+	binary.BigEndian.PutUint32(index[4:8], 1)
+	binary.BigEndian.PutUint32(index[8:12], testNodeSize)
+	binary.BigEndian.PutUint64(index[12:20], 1)
+	binary.BigEndian.PutUint32(index[20:24], crc32.ChecksumIEEE(index[:20]))
+	if err := os.WriteFile(filepath.Join(dir, "index"), index, 0o600); err != nil {
+		t.Fatalf("write index: %s", err.Error())
+	}
+	store, err = merkleMmr.OpenFileStoreWithHasher(dir, testNodeSize, "sha256")
+	if err != nil {
+		t.Fatalf("open version 1 index: %s", err.Error())
+	}
+	if store.Size() != 1 || store.HasherName() != "sha256" {
+		t.Errorf("want 1 node of sha256 got %d nodes of %q", store.Size(), store.HasherName())
+	}
+	store.Close()
+}
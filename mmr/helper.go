@@ -117,6 +117,30 @@ func LeafIndexToMMRSize(index uint64) uint64 {
 	return 2*leavesCount - uint64(peakCount)
 }
 
+// MMRSizeToLeafCount returns the number of leaves in an mmr tree of the given size. Each peak of height h holds
+// 2^h leaves.
+func MMRSizeToLeafCount(mmrSize uint64) uint64 {
+	if mmrSize == 0 {
+		return 0
+	}
+
+	var leavesCount uint64
+	peaks := GetPeaks(mmrSize)
+	for i := 0; i < len(peaks); i++ {
+		leavesCount += 1 << PosHeightInTree(peaks[i])
+	}
+	return leavesCount
+}
+
+// isValidMMRSize returns true if the size is the size of an mmr tree with at least one leaf. Adding a leaf to an
+// mmr tree adds the leaf and its parents at once, so not every size is reachable.
+func isValidMMRSize(mmrSize uint64) bool {
+	if mmrSize == 0 {
+		return false
+	}
+	return LeafIndexToMMRSize(MMRSizeToLeafCount(mmrSize)-1) == mmrSize
+}
+
 // pop removes the last item from a slice and returns it
 func pop(slice *[][]byte) []byte {
 	var sliceCopy = *slice
@@ -47,7 +47,10 @@ func (m *MMR) findElem(pos uint64, hashes [][]byte) ([]byte, error) {
 		return hashes[posOffset], nil
 	}
 
-	elem := m.batch.GetElem(pos)
+	elem, err := m.batch.GetElem(pos)
+	if err != nil {
+		return nil, err
+	}
 	if elem == nil {
 		return nil, ErrInconsistentStore
 	}
@@ -102,12 +105,24 @@ func (m *MMR) Push(elem []byte) (uint64, error) {
 	return elemPos, nil
 }
 
+// PushData pushes the leaf data hashed by hasher.HashLeaf with the MMR hasher
+func (m *MMR) PushData(data []byte) (uint64, error) {
+	leaf, err := hasher.HashLeaf(m.hasher, data)
+	if err != nil {
+		return 0, err
+	}
+	return m.Push(leaf)
+}
+
 // Root returns the root of the MMR tree
 func (m *MMR) Root() ([]byte, error) {
 	if m.size == 0 {
 		return nil, ErrGetRootOnEmpty
 	} else if m.size == 1 {
-		e := m.batch.GetElem(0)
+		e, err := m.batch.GetElem(0)
+		if err != nil {
+			return nil, err
+		}
 		if e == nil {
 			return nil, ErrInconsistentStore
 		}
@@ -117,7 +132,10 @@ func (m *MMR) Root() ([]byte, error) {
 	var peaks [][]byte
 	peakPositions := GetPeaks(m.size)
 	for i := 0; i < len(peakPositions); i++ {
-		elem := m.batch.GetElem(peakPositions[i])
+		elem, err := m.batch.GetElem(peakPositions[i])
+		if err != nil {
+			return nil, err
+		}
 		if elem == nil {
 			return nil, ErrInconsistentStore
 		}
@@ -171,18 +189,22 @@ func (m *MMR) bagRHSPeaks(rhsPeaks [][]byte) []byte {
 }
 
 // generate merkle proof for a peak
-// the pos_list must be sorted, otherwise the behaviour is undefined
+// the nodes must be sorted by position and none of them can be an ancestor of another one, otherwise the
+// behaviour is undefined
 //
 // 1. find a lower tree in peak that can generate a complete merkle proof for position
 // 2. find that tree by compare positions
 // 3. generate proof for each positions
-func (m *MMR) genProofForPeak(proof *Iterator, posList []uint64, peakPos uint64) error {
-	if len(posList) == 1 && reflect.DeepEqual(posList, []uint64{peakPos}) {
+func (m *MMR) genProofForPeak(proof *Iterator, nodes []peak, peakPos uint64) error {
+	if len(nodes) == 1 && nodes[0].pos == peakPos {
 		return nil
 	}
 	// take peak root from store if no positions need to be proof
-	if len(posList) == 0 {
-		elem := m.batch.GetElem(peakPos)
+	if len(nodes) == 0 {
+		elem, err := m.batch.GetElem(peakPos)
+		if err != nil {
+			return err
+		}
 		if elem == nil {
 			return ErrInconsistentStore
 		}
@@ -190,9 +212,10 @@ func (m *MMR) genProofForPeak(proof *Iterator, posList []uint64, peakPos uint64)
 		return nil
 	}
 
+	// nodes are processed from the bottom to the top, so the queue is kept sorted by height and position
 	var queue []peak
-	for i := 0; i < len(posList); i++ {
-		queue = append(queue, peak{pos: posList[i], height: 0})
+	for i := 0; i < len(nodes); i++ {
+		queue = pushNode(queue, nodes[i])
 	}
 
 	for len(queue) > 0 {
@@ -221,14 +244,17 @@ func (m *MMR) genProofForPeak(proof *Iterator, posList []uint64, peakPos uint64)
 			// drop sibling
 			queue = queue[1:]
 		} else {
-			p := m.batch.GetElem(sibPos)
+			p, err := m.batch.GetElem(sibPos)
+			if err != nil {
+				return err
+			}
 			if p == nil {
 				return ErrCorruptedProof
 			}
 			proof.push(p)
 		}
 		if parentPos < peakPos {
-			queue = append(queue, peak{height + 1, parentPos})
+			queue = pushNode(queue, peak{height + 1, parentPos})
 		}
 	}
 	return nil
@@ -244,15 +270,31 @@ func (m *MMR) GenProof(posList []uint64) (*Proof, error) {
 		return NewProof(m.size, [][]byte{}, m.leaves, m.hasher), nil
 	}
 
-	sort.Slice(posList, func(i, j int) bool {
-		return posList[i] < posList[j]
+	nodes := make([]peak, len(posList))
+	for i := 0; i < len(posList); i++ {
+		nodes[i] = peak{height: 0, pos: posList[i]}
+	}
+
+	proofItems, err := m.genNodesProof(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewProof(m.size, proofItems, m.leaves, m.hasher), nil
+}
+
+// genNodesProof generates the proof items for the nodes of the MMR. It pushes merkle proof by peak from left to
+// right, then it pushes bagged right hand side root.
+func (m *MMR) genNodesProof(nodes []peak) ([][]byte, error) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].pos < nodes[j].pos
 	})
 	var peaks = GetPeaks(m.size)
 	var proof = NewIterator()
 	// generate merkle proof for each peaks
 	var baggingTrack uint
 	for i := 0; i < len(peaks); i++ {
-		pl := filterLeavesByPosition(&posList, func(u uint64) bool {
+		pl := filterNodesByPosition(&nodes, func(u uint64) bool {
 			return u <= peaks[i]
 		})
 		if len(pl) == 0 {
@@ -268,7 +310,7 @@ func (m *MMR) GenProof(posList []uint64) (*Proof, error) {
 	}
 
 	// ensure there are no remaining positions
-	if len(posList) != 0 {
+	if len(nodes) != 0 {
 		return nil, ErrGenProofForInvalidLeaves
 	}
 
@@ -282,12 +324,12 @@ func (m *MMR) GenProof(posList []uint64) (*Proof, error) {
 		proof.push(p)
 	}
 
-	return NewProof(m.size, proof.Items, m.leaves, m.hasher), nil
+	return proof.Items, nil
 }
 
-// Commit calls the commit method on the batch property. It adds a batch element to the store
-func (m *MMR) Commit() {
-	m.batch.commit()
+// Commit calls the commit method on the batch property. It writes the batch elements to the store
+func (m *MMR) Commit() error {
+	return m.batch.commit()
 }
 
 // Proof is the mmr proof. It is constructed to verify an MMR leaf.
@@ -296,6 +338,8 @@ type Proof struct {
 	proof   *Iterator
 	Hasher  types.Hasher
 	Leaves  []types.Leaf
+	// nodes records the nodes calculated from the proof by their positions when it isn't nil
+	nodes MemStore
 }
 
 // NewProof creates and returns new Proof. It takes the mmrSize, proof which is of type *Iterator and any type
@@ -324,16 +368,16 @@ func (m *Proof) LeavesToVerify(leaves []types.Leaf) {
 	m.Leaves = leaves
 }
 
-// calculatePeakRoot calculates the peak root hash of a peak of position peakPos using its child leaves and the proofs.
-func (m *Proof) calculatePeakRoot(leaves []types.Leaf, peakPos uint64, proofs *Iterator) ([]byte, error) {
-	if len(leaves) == 0 {
+// calculatePeakRoot calculates the peak root hash of a peak of position peakPos using its child nodes and the proofs.
+func (m *Proof) calculatePeakRoot(nodes []leafWithashOfH, peakPos uint64, proofs *Iterator) ([]byte, error) {
+	if len(nodes) == 0 {
 		return nil, fmt.Errorf("leaves can't be empty")
 	}
 
+	// nodes are merged from the bottom to the top, so the queue is kept sorted by height and position
 	var queue []leafWithashOfH
-	for i := 0; i < len(leaves); i++ {
-		l := leaves[i]
-		queue = append(queue, leafWithashOfH{LeafIndexToPos(l.Index), l.Hash, 0})
+	for i := 0; i < len(nodes); i++ {
+		queue = pushNodeWithHash(queue, nodes[i])
 	}
 
 	// calculate tree root from each item
@@ -383,8 +427,12 @@ func (m *Proof) calculatePeakRoot(leaves []types.Leaf, peakPos uint64, proofs *I
 			parentItem = hash
 		}
 
+		if m.nodes != nil {
+			m.nodes[sibPos], m.nodes[parentPos] = siblingItem, parentItem
+		}
+
 		if parentPos < peakPos {
-			queue = append(queue, leafWithashOfH{parentPos, parentItem, height + 1})
+			queue = pushNodeWithHash(queue, leafWithashOfH{parentPos, parentItem, height + 1})
 		} else {
 			return parentItem, nil
 		}
@@ -394,6 +442,11 @@ func (m *Proof) calculatePeakRoot(leaves []types.Leaf, peakPos uint64, proofs *I
 }
 
 func (m *Proof) baggingPeaksHashes(peaksHashes [][]byte) ([]byte, error) {
+	return bagPeaksHashes(m.Hasher, peaksHashes)
+}
+
+// bagPeaksHashes merges the hashes of the peaks from the right to the left into a single root hash
+func bagPeaksHashes(h types.Hasher, peaksHashes [][]byte) ([]byte, error) {
 	var rightPeak, leftPeak []byte
 	for len(peaksHashes) > 1 {
 		if len(peaksHashes) == 0 {
@@ -407,7 +460,7 @@ func (m *Proof) baggingPeaksHashes(peaksHashes [][]byte) ([]byte, error) {
 		leftPeak = pop(&peaksHashes)
 
 		// when bagging the peaks of an MMR, hashes of the peaks are merged from right to left.
-		hash, err := hasher.MergeAndHash(m.Hasher, rightPeak, leftPeak)
+		hash, err := hasher.MergeAndHash(h, rightPeak, leftPeak)
 		if err != nil {
 			return nil, err
 		}
@@ -482,6 +535,30 @@ func (m *Proof) Verify(root []byte) bool {
 	return reflect.DeepEqual(calculatedRoot, root)
 }
 
+// VerifyData verifies the proof like Verify, the leaves hashes are the data of the leaves, in the order of Leaves,
+// hashed by hasher.HashLeaf with the proof hasher like PushData does
+func (m *Proof) VerifyData(root []byte, data [][]byte) bool {
+	if len(data) != len(m.Leaves) {
+		log.Errorf("root verification: %s \n", ErrInvalidLeavesData.Error())
+		return false
+	}
+	leaves := make([]types.Leaf, len(m.Leaves))
+	for i, leaf := range m.Leaves {
+		hash, err := hasher.HashLeaf(m.Hasher, data[i])
+		if err != nil {
+			log.Errorf("root verification: %s \n", err.Error())
+			return false
+		}
+		leaves[i] = types.Leaf{Index: leaf.Index, Hash: hash}
+	}
+
+	// the proof items are read from a new iterator, so the proof is left as it was
+	proof := *m
+	proof.proof = &Iterator{Items: m.proof.Items}
+	proof.Leaves = leaves
+	return proof.Verify(root)
+}
+
 func (m *Proof) calculatePeaksHashes(leaves []types.Leaf, mmrSize uint64, proofs *Iterator) ([][]byte, error) {
 	// special handle the only 1 Hash Proof
 	if mmrSize == 1 && len(leaves) == 1 && LeafIndexToPos(leaves[0].Index) == 0 {
@@ -492,24 +569,37 @@ func (m *Proof) calculatePeaksHashes(leaves []types.Leaf, mmrSize uint64, proofs
 		return items, nil
 	}
 
+	// leaves are the nodes with zero height
+	nodes := make([]leafWithashOfH, len(leaves))
+	for i := 0; i < len(leaves); i++ {
+		nodes[i] = leafWithashOfH{LeafIndexToPos(leaves[i].Index), leaves[i].Hash, 0}
+	}
+
+	return m.calculateNodesPeaksHashes(nodes, mmrSize, proofs)
+}
+
+// calculateNodesPeaksHashes calculates the hashes of the peaks using the nodes of the MMR and the proofs. The nodes
+// can be of any height, as long as none of them is an ancestor of another one.
+func (m *Proof) calculateNodesPeaksHashes(nodes []leafWithashOfH, mmrSize uint64, proofs *Iterator) ([][]byte, error) {
 	// sort items by position
-	sort.SliceStable(leaves, func(i, j int) bool {
-		return LeafIndexToPos(leaves[i].Index) < LeafIndexToPos(leaves[j].Index)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].pos < nodes[j].pos
 	})
 
 	peaks := GetPeaks(mmrSize)
 	var peaksHashes [][]byte
-	peaksLoop: for i := 0; i < len(peaks); i++ {
-		// filter out the leaf of position peakPos or leaves that are children of a peak with position peakPos
-		lvs := filterLeaves(&leaves, func(l types.Leaf) bool {
-			return LeafIndexToPos(l.Index) <= peaks[i]
+peaksLoop:
+	for i := 0; i < len(peaks); i++ {
+		// filter out the node of position peakPos or nodes that are children of a peak with position peakPos
+		lvs := filterNodes(&nodes, func(n leafWithashOfH) bool {
+			return n.pos <= peaks[i]
 		})
 
 		var peakRoot []byte
 		switch {
-		case len(lvs) == 1 && LeafIndexToPos(lvs[0].Index) == peaks[i]:
+		case len(lvs) == 1 && lvs[0].pos == peaks[i]:
 			// Hash is the peak
-			peakRoot = lvs[0].Hash
+			peakRoot = lvs[0].hash
 		case len(lvs) == 0:
 			// if empty, means the next proof is a peak root or rhs bagged root
 			if proof := proofs.Next(); proof != nil {
@@ -529,7 +619,7 @@ func (m *Proof) calculatePeaksHashes(leaves []types.Leaf, mmrSize uint64, proofs
 		peaksHashes = append(peaksHashes, peakRoot)
 	}
 	// ensure nothing left in leaves
-	if len(leaves) != 0 {
+	if len(nodes) != 0 {
 		return nil, ErrCorruptedProof
 	}
 
@@ -545,11 +635,11 @@ func (m *Proof) calculatePeaksHashes(leaves []types.Leaf, mmrSize uint64, proofs
 	return peaksHashes, nil
 }
 
-// filterLeaves takes a pointer to a slice of types.Leaf and closure as arguments. It will call this closure on each item.
+// filterNodes takes a pointer to a slice of nodes and closure as arguments. It will call this closure on each item.
 // If false is returned from calling the closure, it will ignore the remaining elements in the slice and set the slice
 // passed as an argument to a slice of those ignored items. It will then return a slice of items that returned true when
 // the closure was called on them.
-func filterLeaves(v *[]types.Leaf, p func(types.Leaf) bool) []types.Leaf {
+func filterNodes(v *[]leafWithashOfH, p func(leafWithashOfH) bool) []leafWithashOfH {
 	vCopy := *v
 	for i := 0; i < len(vCopy); i++ {
 		if !p(vCopy[i]) {
@@ -561,10 +651,10 @@ func filterLeaves(v *[]types.Leaf, p func(types.Leaf) bool) []types.Leaf {
 	return vCopy
 }
 
-func filterLeavesByPosition(v *[]uint64, p func(uint64) bool) []uint64 {
+func filterNodesByPosition(v *[]peak, p func(uint64) bool) []peak {
 	vCopy := *v
 	for i := 0; i < len(vCopy); i++ {
-		if !p(vCopy[i]) {
+		if !p(vCopy[i].pos) {
 			*v = vCopy[i:]
 			return vCopy[:i]
 		}
@@ -572,3 +662,25 @@ func filterLeavesByPosition(v *[]uint64, p func(uint64) bool) []uint64 {
 	*v = vCopy[:0]
 	return vCopy
 }
+
+// pushNode inserts the node into the queue that is sorted by height and position
+func pushNode(queue []peak, node peak) []peak {
+	i := sort.Search(len(queue), func(i int) bool {
+		return queue[i].height > node.height || (queue[i].height == node.height && queue[i].pos > node.pos)
+	})
+	queue = append(queue, peak{})
+	copy(queue[i+1:], queue[i:])
+	queue[i] = node
+	return queue
+}
+
+// pushNodeWithHash inserts the node into the queue that is sorted by height and position
+func pushNodeWithHash(queue []leafWithashOfH, node leafWithashOfH) []leafWithashOfH {
+	i := sort.Search(len(queue), func(i int) bool {
+		return queue[i].height > node.height || (queue[i].height == node.height && queue[i].pos > node.pos)
+	})
+	queue = append(queue, leafWithashOfH{})
+	copy(queue[i+1:], queue[i:])
+	queue[i] = node
+	return queue
+}
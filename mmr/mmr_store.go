@@ -1,9 +1,12 @@
 package mmr
 
-// Store defines the required method on any store passed to the Batch struct
+import "bytes"
+
+// Store defines the required method on any store passed to the Batch struct. GetElem should return a nil
+// element without an error if there is no element at the given position.
 type Store interface {
-	GetElem(pos uint64) []byte
-	append(pos uint64, elems [][]byte)
+	GetElem(pos uint64) ([]byte, error)
+	Append(pos uint64, elems [][]byte) error
 }
 
 // BatchElem holds the fields of data for a Batch Element
@@ -31,7 +34,7 @@ func (b *Batch) append(pos uint64, elems [][]byte) {
 }
 
 // GetElem returns an element in a store implementation using its position.
-func (b *Batch) GetElem(pos uint64) []byte {
+func (b *Batch) GetElem(pos uint64) ([]byte, error) {
 	i := len(b.memoryBatch)
 	batchLoop: for i > 0 {
 		mb := b.memoryBatch[i-1]
@@ -41,7 +44,7 @@ func (b *Batch) GetElem(pos uint64) []byte {
 			i -= 1
 			continue
 		case pos < startPos+uint64(len(elems)):
-			return elems[pos-startPos]
+			return elems[pos-startPos], nil
 		default:
 			break batchLoop
 		}
@@ -49,8 +52,57 @@ func (b *Batch) GetElem(pos uint64) []byte {
 	return b.store.GetElem(pos)
 }
 
-func (b *Batch) commit() {
+// commit writes the batch elements into the store. Contiguous elements are merged and written with a single
+// Append call, so stores that apply every Append atomically commit the whole batch at once. The elements that are
+// already in the store are skipped, so a commit that failed after a partial write is retried without appending
+// them again. The batch is cleared only after all of the elements have been written.
+func (b *Batch) commit() error {
+	var merged []BatchElem
 	for i := 0; i < len(b.memoryBatch); i++ {
-		b.store.append(b.memoryBatch[i].pos, b.memoryBatch[i].elems)
+		mb := b.memoryBatch[i]
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			if last.pos+uint64(len(last.elems)) == mb.pos {
+				last.elems = append(last.elems, mb.elems...)
+				continue
+			}
+		}
+		merged = append(merged, BatchElem{mb.pos, append([][]byte{}, mb.elems...)})
+	}
+
+	for i := 0; i < len(merged); i++ {
+		pos, elems, err := b.unstored(merged[i].pos, merged[i].elems)
+		if err != nil {
+			return err
+		}
+		if len(elems) == 0 {
+			continue
+		}
+		if err := b.store.Append(pos, elems); err != nil {
+			return err
+		}
+	}
+
+	b.memoryBatch = []BatchElem{}
+	return nil
+}
+
+// unstored skips the leading elements that are already in the store and returns the position and the elements that
+// are left to append. A stored element that differs from the batch element means the store was changed by another
+// writer, so ErrInconsistentStore is returned.
+func (b *Batch) unstored(pos uint64, elems [][]byte) (uint64, [][]byte, error) {
+	for len(elems) > 0 {
+		stored, err := b.store.GetElem(pos)
+		if err != nil {
+			return 0, nil, err
+		}
+		if stored == nil {
+			break
+		}
+		if !bytes.Equal(stored, elems[0]) {
+			return 0, nil, ErrInconsistentStore
+		}
+		pos, elems = pos+1, elems[1:]
 	}
+	return pos, elems, nil
 }
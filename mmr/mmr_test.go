@@ -60,7 +60,9 @@ func testMMR(count uint32, proofElem []uint32) error {
 		return err
 	}
 
-	mmrTree.Commit()
+	if err := mmrTree.Commit(); err != nil {
+		return err
+	}
 	result := proof.Verify(root)
 	if !result {
 		return errors.New("error verifying root")
@@ -141,7 +143,10 @@ func TestGenRootFromProof(t *testing.T) {
 		return
 	}
 
-	mmr.Commit()
+	if err := mmr.Commit(); err != nil {
+		t.Errorf("%s: %s", "merkleMmr commit", err.Error())
+		return
+	}
 	calculatedRoot, err := proof.CalculateRootWithNewLeaf(
 		leaves,
 		uint64(newElem),
@@ -810,11 +815,89 @@ func prepareMMR(count uint32) (uint64, merkleMmr.Store, []uint64) {
 	}
 
 	mmrSize := mmrTree.MMRSize()
-	mmrTree.Commit()
+	if err := mmrTree.Commit(); err != nil {
+		return 0, nil, nil
+	}
 
 	return mmrSize, store, positions
 }
 
+func TestLeafHashingSchemes(t *testing.T) {
+	data := [][]byte{[]byte("Hello"), []byte("Dorood"), []byte("Hi")}
+	legacyHasher := hasher.NewLegacyHasher(hasher.Keccak256Hasher{})
+	prefixedHasher, err := hasher.NewPrefixedHasher(hasher.Keccak256Hasher{}, []byte{0}, []byte{1})
+	if err != nil {
+		t.Fatalf("new prefixed hasher: %s", err.Error())
+	}
+
+	roots := make(map[string]bool)
+	for name, h := range map[string]types.Hasher{"legacy": legacyHasher, "prefixed": prefixedHasher} {
+		mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, h)
+		var leaves [][]byte
+		for _, d := range data[:2] {
+			if _, err := mmrTree.PushData(d); err != nil {
+				t.Fatalf("%s: push data: %s", name, err.Error())
+			}
+			leaf, err := hasher.HashLeaf(h, d)
+			if err != nil {
+				t.Fatalf("%s: hash leaf: %s", name, err.Error())
+			}
+			leaves = append(leaves, leaf)
+		}
+		root, err := mmrTree.Root()
+		if err != nil {
+			t.Fatalf("%s: root: %s", name, err.Error())
+		}
+		roots[hex.EncodeToString(root)] = true
+
+		proof, err := mmrTree.GenProof([]uint64{merkleMmr.LeafIndexToPos(1)})
+		if err != nil {
+			t.Fatalf("%s: gen proof: %s", name, err.Error())
+		}
+		proof.LeavesToVerify([]types.Leaf{{Index: 1, Hash: leaves[1]}})
+		if !proof.Verify(root) {
+			t.Errorf("%s: proof of the leaf 1 is not valid", name)
+		}
+		if !proof.VerifyData(root, data[1:2]) {
+			t.Errorf("%s: proof of the leaf 1 data is not valid", name)
+		}
+		if proof.VerifyData(root, data[:1]) || proof.VerifyData(root, data[:2]) {
+			t.Errorf("%s: proof of the leaf 1 is valid for other data", name)
+		}
+
+		// the concatenated leaf hashes of the single peak are passed off as the only leaf of an mmr
+		forgedLeaf, err := hasher.HashLeaf(h, append(append([]byte{}, leaves[0]...), leaves[1]...))
+		if err != nil {
+			t.Fatalf("%s: hash forged leaf: %s", name, err.Error())
+		}
+		forgedProof := merkleMmr.NewProof(1, nil, []types.Leaf{{Index: 0, Hash: forgedLeaf}}, h)
+		if forged := forgedProof.Verify(root); forged != (name == "legacy") {
+			t.Errorf("%s: want the forged proof verification %v got %v", name, name == "legacy", forged)
+		}
+	}
+	if len(roots) != 2 {
+		t.Errorf("the legacy and prefixed roots must differ")
+	}
+
+	// the legacy scheme keeps the roots of the mmrs built from leaf hashes
+	legacyTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, legacyHasher)
+	hashTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	for _, d := range data {
+		leaf, _ := hasher.Keccak256Hasher{}.Hash(d)
+		if _, err := hashTree.Push(leaf); err != nil {
+			t.Fatalf("push: %s", err.Error())
+		}
+		if _, err := legacyTree.PushData(d); err != nil {
+			t.Fatalf("push data: %s", err.Error())
+		}
+	}
+	legacyRoot, _ := legacyTree.Root()
+	hashRoot, _ := hashTree.Root()
+	if !reflect.DeepEqual(legacyRoot, hashRoot) {
+		t.Errorf("legacy root: want %x got %x", hashRoot, legacyRoot)
+	}
+}
+
 func BenchmarkMMRInsertion(b *testing.B) {
 	var table = []struct {
 		input uint32
@@ -856,7 +939,7 @@ func BenchmarkProof_Verify(b *testing.B) {
 		rand.Seed(time.Now().UnixNano())
 		randomIndex := rand.Int63n(int64(len(positions)))
 		pos := positions[randomIndex]
-		elem := store.GetElem(pos)
+		elem, _ := store.GetElem(pos)
 		proof, _ := mmrTree.GenProof([]uint64{pos})
 
 		proofs = append(proofs, struct {
@@ -0,0 +1,193 @@
+package mmr
+
+import (
+	"bytes"
+	"sort"
+)
+
+// ProofUpdate is the witness that updates the proofs of an MMR of a previous size to the MMR grown by the appended
+// leaves. The nodes of an MMR are never updated, so a proof of the previous MMR is the merkle path of the leaves to
+// the previous peaks. The update contains the nodes that extend the paths from the previous peaks to the current
+// peaks and the current peaks themselves.
+type ProofUpdate struct {
+	prevMMRSize uint64
+	mmrSize     uint64
+	positions   []uint64
+	items       [][]byte
+}
+
+// NewProofUpdate creates and returns new ProofUpdate. It takes the previous and the current mmr sizes and the nodes
+// of the current mmr with their positions.
+func NewProofUpdate(prevMMRSize, mmrSize uint64, positions []uint64, items [][]byte) *ProofUpdate {
+	return &ProofUpdate{
+		prevMMRSize: prevMMRSize,
+		mmrSize:     mmrSize,
+		positions:   positions,
+		items:       items,
+	}
+}
+
+// GenProofUpdate generates the update of the proofs made for the MMR of size prevMMRSize to the current MMR. The
+// update contains the siblings on the paths from the peaks of the previous MMR to the current peaks and the current
+// peaks, sorted by their positions.
+func (m *MMR) GenProofUpdate(prevMMRSize uint64) (*ProofUpdate, error) {
+	if prevMMRSize > m.size || !isValidMMRSize(prevMMRSize) {
+		return nil, ErrInvalidProofUpdate
+	}
+
+	peaks := GetPeaks(m.size)
+	isPeak := make(map[uint64]bool, len(peaks))
+	positions := make(map[uint64]bool)
+	for _, pos := range peaks {
+		isPeak[pos] = true
+		positions[pos] = true
+	}
+
+	// a previous peak is either a current peak or a node under one
+	for _, pos := range GetPeaks(prevMMRSize) {
+		for height := PosHeightInTree(pos); !isPeak[pos]; height++ {
+			if PosHeightInTree(pos+1) > height {
+				positions[pos-siblingOffset(height)] = true
+				pos++
+			} else {
+				positions[pos+siblingOffset(height)] = true
+				pos += parentOffset(height)
+			}
+		}
+	}
+
+	update := NewProofUpdate(prevMMRSize, m.size, make([]uint64, 0, len(positions)), make([][]byte, 0, len(positions)))
+	for pos := range positions {
+		update.positions = append(update.positions, pos)
+	}
+	sort.Slice(update.positions, func(i, j int) bool { return update.positions[i] < update.positions[j] })
+	for _, pos := range update.positions {
+		elem, err := m.batch.GetElem(pos)
+		if err != nil {
+			return nil, err
+		}
+		if elem == nil {
+			return nil, ErrInconsistentStore
+		}
+		update.items = append(update.items, elem)
+	}
+	return update, nil
+}
+
+// PrevMMRSize returns the size of the previous mmr
+func (u *ProofUpdate) PrevMMRSize() uint64 {
+	return u.prevMMRSize
+}
+
+// MMRSize returns the size of the current mmr
+func (u *ProofUpdate) MMRSize() uint64 {
+	return u.mmrSize
+}
+
+// Positions returns the positions of the nodes of the update in the current mmr
+func (u *ProofUpdate) Positions() []uint64 {
+	return u.positions
+}
+
+// Items returns the hashes of the nodes of the update
+func (u *ProofUpdate) Items() [][]byte {
+	return u.items
+}
+
+// Update returns the proof of the same leaves for the MMR grown to the size of the update. The proof must be made for
+// the previous mmr size of the update. The updated proof has the same layout as the proofs generated by the MMR, so
+// it's verified against the current root like any other proof.
+func (m *Proof) Update(update *ProofUpdate) (*Proof, error) {
+	if update.prevMMRSize != m.mmrSize || update.mmrSize < m.mmrSize || !isValidMMRSize(update.mmrSize) ||
+		len(update.positions) != len(update.items) {
+		return nil, ErrInvalidProofUpdate
+	}
+
+	nodes, _, err := m.knownNodes()
+	if err != nil {
+		return nil, err
+	}
+	for i, pos := range update.positions {
+		nodes[pos] = update.items[i]
+	}
+	return m.regenerate(NewMMR(update.mmrSize, nodes, m.Leaves, m.Hasher))
+}
+
+// UpdateWithLeaves returns the proof of the same leaves for the MMR grown by the appended leaves, together with the
+// peaks of the grown MMR. It takes the peaks of the MMR the proof was made for, which are the only nodes needed to
+// append the leaves, so a client that tracks the peaks and the appended leaves updates its proofs without the server.
+// The peaks are checked against the root calculated from the proof.
+func (m *Proof) UpdateWithLeaves(prevPeaks [][]byte, leaves [][]byte) (*Proof, [][]byte, error) {
+	prevPeaksPositions := GetPeaks(m.mmrSize)
+	if !isValidMMRSize(m.mmrSize) || len(prevPeaksPositions) != len(prevPeaks) {
+		return nil, nil, ErrInvalidProofUpdate
+	}
+
+	nodes, root, err := m.knownNodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	prevRoot, err := bagPeaksHashes(m.Hasher, append([][]byte{}, prevPeaks...))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(prevRoot, root) {
+		return nil, nil, ErrInvalidProofUpdate
+	}
+	for i, pos := range prevPeaksPositions {
+		nodes[pos] = prevPeaks[i]
+	}
+
+	mmr := NewMMR(m.mmrSize, nodes, m.Leaves, m.Hasher)
+	for _, leaf := range leaves {
+		if _, err := mmr.Push(leaf); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	peaksPositions := GetPeaks(mmr.size)
+	peaks := make([][]byte, len(peaksPositions))
+	for i, pos := range peaksPositions {
+		if peaks[i], err = mmr.batch.GetElem(pos); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	proof, err := m.regenerate(mmr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return proof, peaks, nil
+}
+
+// knownNodes returns the leaves of the proof and the nodes calculated from the proof by their positions, and the root
+// calculated from the proof. The bagged right hand side peaks are not nodes of the MMR, so they are not returned.
+func (m *Proof) knownNodes() (MemStore, []byte, error) {
+	nodes := NewMemStore()
+	for _, leaf := range m.Leaves {
+		nodes[LeafIndexToPos(leaf.Index)] = leaf.Hash
+	}
+
+	// the proof iterator is consumed by the calculation, so a copy of the proof is used
+	proof := NewProof(m.mmrSize, m.proof.Items, m.Leaves, m.Hasher)
+	proof.nodes = nodes
+	root, err := proof.CalculateRoot()
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodes, root, nil
+}
+
+// regenerate generates the proof of the leaves of the proof in the MMR whose store has the nodes of the proof
+func (m *Proof) regenerate(mmr *MMR) (*Proof, error) {
+	posList := make([]uint64, len(m.Leaves))
+	for i, leaf := range m.Leaves {
+		posList[i] = LeafIndexToPos(leaf.Index)
+	}
+
+	proof, err := mmr.GenProof(posList)
+	if err == ErrCorruptedProof || err == ErrInconsistentStore {
+		return nil, ErrInvalidProofUpdate
+	}
+	return proof, err
+}
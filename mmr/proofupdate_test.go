@@ -0,0 +1,139 @@
+package mmr_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	merkleMmr "github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+func TestProofUpdate(t *testing.T) {
+	const maxLeaves = 30
+
+	for prevSize := uint32(1); prevSize <= maxLeaves; prevSize++ {
+		prev := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+		pushLeaves(t, prev, 0, prevSize)
+		prevPeaks := peaksOf(t, prev)
+
+		for size := prevSize; size <= maxLeaves; size++ {
+			current := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+			pushLeaves(t, current, 0, size)
+			root, err := current.Root()
+			if err != nil {
+				t.Fatalf("root of %d leaves: %s", size, err.Error())
+			}
+			update, err := current.GenProofUpdate(prev.MMRSize())
+			if err != nil {
+				t.Fatalf("gen proof update %d -> %d: %s", prevSize, size, err.Error())
+			}
+			var appended [][]byte
+			for i := prevSize; i < size; i++ {
+				appended = append(appended, uint32ToHash(i))
+			}
+
+			for _, indices := range [][]uint32{{0}, {prevSize - 1}, {prevSize / 2}, {0, prevSize - 1}} {
+				leaves := make([]types.Leaf, len(indices))
+				positions := make([]uint64, len(indices))
+				for i, index := range indices {
+					leaves[i] = types.Leaf{Index: uint64(index), Hash: uint32ToHash(index)}
+					positions[i] = merkleMmr.LeafIndexToPos(uint64(index))
+				}
+				if len(indices) > 1 && indices[0] == indices[1] {
+					leaves, positions = leaves[:1], positions[:1]
+				}
+
+				prevProof := proofOf(t, prev, positions, leaves)
+				expected := proofOf(t, current, positions, leaves)
+
+				proof, err := prevProof.Update(update)
+				if err != nil {
+					t.Fatalf("update proof of %v %d -> %d: %s", indices, prevSize, size, err.Error())
+				}
+				if !reflect.DeepEqual(expected.ProofItems(), proof.ProofItems()) || proof.MMRSize() != current.MMRSize() {
+					t.Errorf("%v %d -> %d: the updated proof is not the proof of the current mmr", indices, prevSize, size)
+				}
+				if !proof.Verify(root) {
+					t.Errorf("%v %d -> %d: the updated proof is not valid", indices, prevSize, size)
+				}
+
+				proof, peaks, err := prevProof.UpdateWithLeaves(prevPeaks, appended)
+				if err != nil {
+					t.Fatalf("update proof of %v %d -> %d with leaves: %s", indices, prevSize, size, err.Error())
+				}
+				if !reflect.DeepEqual(expected.ProofItems(), proof.ProofItems()) {
+					t.Errorf("%v %d -> %d: the proof updated with leaves is not the proof of the current mmr", indices,
+						prevSize, size)
+				}
+				if !reflect.DeepEqual(peaksOf(t, current), peaks) {
+					t.Errorf("%v %d -> %d: wrong peaks of the current mmr", indices, prevSize, size)
+				}
+			}
+		}
+	}
+}
+
+func TestProofUpdateErrors(t *testing.T) {
+	prev := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, prev, 0, 7)
+	current := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	pushLeaves(t, current, 0, 13)
+	leaves := []types.Leaf{{Index: 2, Hash: uint32ToHash(2)}}
+	prevProof := proofOf(t, prev, []uint64{merkleMmr.LeafIndexToPos(2)}, leaves)
+
+	if _, err := prev.GenProofUpdate(current.MMRSize()); err != merkleMmr.ErrInvalidProofUpdate {
+		t.Errorf("want %v for a bigger mmr size got %v", merkleMmr.ErrInvalidProofUpdate, err)
+	}
+	if _, err := current.GenProofUpdate(prev.MMRSize() + 1); err != merkleMmr.ErrInvalidProofUpdate {
+		t.Errorf("want %v for an invalid mmr size got %v", merkleMmr.ErrInvalidProofUpdate, err)
+	}
+
+	update, err := current.GenProofUpdate(merkleMmr.LeafIndexToMMRSize(5))
+	if err != nil {
+		t.Fatalf("gen proof update: %s", err.Error())
+	}
+	if _, err := prevProof.Update(update); err != merkleMmr.ErrInvalidProofUpdate {
+		t.Errorf("want %v for another previous mmr size got %v", merkleMmr.ErrInvalidProofUpdate, err)
+	}
+
+	// the update doesn't have the last peak of the current mmr
+	update, err = current.GenProofUpdate(prev.MMRSize())
+	if err != nil {
+		t.Fatalf("gen proof update: %s", err.Error())
+	}
+	positions, items := update.Positions(), update.Items()
+	partial := merkleMmr.NewProofUpdate(update.PrevMMRSize(), update.MMRSize(), positions[:len(positions)-1],
+		items[:len(items)-1])
+	if _, err := prevProof.Update(partial); err != merkleMmr.ErrInvalidProofUpdate {
+		t.Errorf("want %v for a partial update got %v", merkleMmr.ErrInvalidProofUpdate, err)
+	}
+
+	prevPeaks := peaksOf(t, prev)
+	prevPeaks[0], prevPeaks[1] = prevPeaks[1], prevPeaks[0]
+	if _, _, err := prevProof.UpdateWithLeaves(prevPeaks, [][]byte{uint32ToHash(7)}); err != merkleMmr.ErrInvalidProofUpdate {
+		t.Errorf("want %v for the wrong previous peaks got %v", merkleMmr.ErrInvalidProofUpdate, err)
+	}
+	if _, _, err := prevProof.UpdateWithLeaves(prevPeaks[1:], [][]byte{uint32ToHash(7)}); err != merkleMmr.ErrInvalidProofUpdate {
+		t.Errorf("want %v for missing previous peaks got %v", merkleMmr.ErrInvalidProofUpdate, err)
+	}
+}
+
+// proofOf generates the proof of the leaves at the positions
+func proofOf(t *testing.T, mmrTree *merkleMmr.MMR, positions []uint64, leaves []types.Leaf) *merkleMmr.Proof {
+	proof, err := mmrTree.GenProof(positions)
+	if err != nil {
+		t.Fatalf("gen proof of %v: %s", positions, err.Error())
+	}
+	proof.LeavesToVerify(leaves)
+	return proof
+}
+
+// peaksOf returns the hashes of the peaks of the mmr from left to right, the peaks of the ancestry proof of itself
+func peaksOf(t *testing.T, mmrTree *merkleMmr.MMR) [][]byte {
+	proof, err := mmrTree.GenAncestryProof(mmrTree.MMRSize())
+	if err != nil {
+		t.Fatalf("peaks of %d: %s", mmrTree.MMRSize(), err.Error())
+	}
+	return proof.PrevPeaks()
+}
@@ -0,0 +1,241 @@
+package mmr
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// scaleHashSize is the size of the H256 proof items of Substrate
+	scaleHashSize = 32
+	scaleU64Size  = 8
+
+	compactModeMask       = 0b11
+	compactSingleByteMode = 0b00
+	compactTwoBytesMode   = 0b01
+	compactFourBytesMode  = 0b10
+	compactBigIntMode     = 0b11
+	compactModeBits       = 2
+
+	compactSingleByteLimit = 1 << 6
+	compactTwoBytesLimit   = 1 << 14
+	compactFourBytesLimit  = 1 << 30
+	compactBigIntMinSize   = 4
+)
+
+// EncodeLeafProof encodes a single leaf proof into the SCALE layout of Substrate's pallet-mmr LeafProof:
+//
+//	leaf_index u64, leaf_count u64, items Vec<H256>
+//
+// The proof must have exactly one leaf and 32 bytes proof items.
+func (m *Proof) EncodeLeafProof() ([]byte, error) {
+	if len(m.Leaves) != 1 {
+		return nil, ErrInvalidSCALEEncoding
+	}
+
+	data := appendU64(nil, m.Leaves[0].Index)
+	return m.appendLeafCountAndItems(data)
+}
+
+// EncodeLeafBatchProof encodes the proof into the SCALE layout of Substrate's pallet-mmr LeafBatchProof, which is
+// called LeafProof in the newer Substrate versions:
+//
+//	leaf_indices Vec<u64>, leaf_count u64, items Vec<H256>
+func (m *Proof) EncodeLeafBatchProof() ([]byte, error) {
+	data := appendCompact(nil, uint64(len(m.Leaves)))
+	for i := 0; i < len(m.Leaves); i++ {
+		data = appendU64(data, m.Leaves[i].Index)
+	}
+	return m.appendLeafCountAndItems(data)
+}
+
+// DecodeLeafProof decodes a Substrate LeafProof into a Proof of the given hasher. Substrate proofs don't include the
+// leaves, so the decoded proof has the leaf index without the hash. The leaf hash must be set before the proof is
+// verified, for example with LeavesToVerify.
+func DecodeLeafProof(data []byte, h types.Hasher) (*Proof, error) {
+	d := scaleDecoder{data: data}
+
+	leafIndex, err := d.u64()
+	if err != nil {
+		return nil, err
+	}
+	return d.proof([]uint64{leafIndex}, h)
+}
+
+// DecodeLeafBatchProof decodes a Substrate LeafBatchProof into a Proof of the given hasher. Like DecodeLeafProof, the
+// decoded proof has the leaf indices without the hashes.
+func DecodeLeafBatchProof(data []byte, h types.Hasher) (*Proof, error) {
+	d := scaleDecoder{data: data}
+
+	leavesCount, err := d.length(scaleU64Size)
+	if err != nil {
+		return nil, err
+	}
+	leafIndices := make([]uint64, leavesCount)
+	for i := 0; i < leavesCount; i++ {
+		if leafIndices[i], err = d.u64(); err != nil {
+			return nil, err
+		}
+	}
+	return d.proof(leafIndices, h)
+}
+
+// appendLeafCountAndItems appends the leaf count of the mmr size and the proof items to the encoded proof
+func (m *Proof) appendLeafCountAndItems(data []byte) ([]byte, error) {
+	if !isValidMMRSize(m.mmrSize) {
+		return nil, ErrInvalidSCALEEncoding
+	}
+	data = appendU64(data, MMRSizeToLeafCount(m.mmrSize))
+
+	items := m.ProofItems()
+	data = appendCompact(data, uint64(len(items)))
+	for i := 0; i < len(items); i++ {
+		if len(items[i]) != scaleHashSize {
+			return nil, ErrInvalidSCALEEncoding
+		}
+		data = append(data, items[i]...)
+	}
+	return data, nil
+}
+
+// scaleDecoder reads the SCALE encoded values and returns ErrInvalidSCALEEncoding when the data is malformed
+type scaleDecoder struct {
+	data []byte
+}
+
+// proof reads the leaf count and the proof items that follow the leaf indices in both Substrate proof layouts
+func (d *scaleDecoder) proof(leafIndices []uint64, h types.Hasher) (*Proof, error) {
+	leafCount, err := d.u64()
+	if err != nil {
+		return nil, err
+	}
+	if leafCount == 0 {
+		return nil, ErrInvalidSCALEEncoding
+	}
+
+	itemsCount, err := d.length(scaleHashSize)
+	if err != nil {
+		return nil, err
+	}
+	items := make([][]byte, itemsCount)
+	for i := 0; i < itemsCount; i++ {
+		item, err := d.next(scaleHashSize)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = append([]byte{}, item...)
+	}
+
+	if len(d.data) != 0 {
+		return nil, ErrInvalidSCALEEncoding
+	}
+
+	leaves := make([]types.Leaf, len(leafIndices))
+	for i := 0; i < len(leafIndices); i++ {
+		if leafIndices[i] >= leafCount {
+			return nil, ErrInvalidSCALEEncoding
+		}
+		leaves[i] = types.Leaf{Index: leafIndices[i]}
+	}
+
+	return NewProof(LeafIndexToMMRSize(leafCount-1), items, leaves, h), nil
+}
+
+func (d *scaleDecoder) next(size int) ([]byte, error) {
+	if len(d.data) < size {
+		return nil, ErrInvalidSCALEEncoding
+	}
+	b := d.data[:size]
+	d.data = d.data[size:]
+	return b, nil
+}
+
+func (d *scaleDecoder) u64() (uint64, error) {
+	b, err := d.next(scaleU64Size)
+	if err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(b), nil
+}
+
+// compact reads a SCALE compact encoded integer of up to 64 bits
+func (d *scaleDecoder) compact() (uint64, error) {
+	first, err := d.next(1)
+	if err != nil {
+		return 0, err
+	}
+
+	switch first[0] & compactModeMask {
+	case compactSingleByteMode:
+		return uint64(first[0] >> compactModeBits), nil
+	case compactTwoBytesMode:
+		rest, err := d.next(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint16([]byte{first[0], rest[0]}) >> compactModeBits), nil
+	case compactFourBytesMode:
+		rest, err := d.next(3)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.LittleEndian.Uint32(append([]byte{first[0]}, rest...)) >> compactModeBits), nil
+	}
+
+	// the upper six bits of the big integer mode are the number of the following bytes minus four
+	size := int(first[0]>>compactModeBits) + compactBigIntMinSize
+	if size > scaleU64Size {
+		return 0, ErrInvalidSCALEEncoding
+	}
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var value [scaleU64Size]byte
+	copy(value[:], b)
+	return binary.LittleEndian.Uint64(value[:]), nil
+}
+
+// length reads the compact length of a vector, it fails if the remaining data can't hold that many items of the
+// given size, so a corrupted length never causes a huge allocation
+func (d *scaleDecoder) length(itemSize int) (int, error) {
+	length, err := d.compact()
+	if err != nil {
+		return 0, err
+	}
+	if length > uint64(len(d.data)/itemSize) {
+		return 0, ErrInvalidSCALEEncoding
+	}
+	return int(length), nil
+}
+
+// appendU64 appends the little endian encoded number to the data
+func appendU64(data []byte, n uint64) []byte {
+	b := make([]byte, scaleU64Size)
+	binary.LittleEndian.PutUint64(b, n)
+	return append(data, b...)
+}
+
+// appendCompact appends the SCALE compact encoded number to the data
+func appendCompact(data []byte, n uint64) []byte {
+	switch {
+	case n < compactSingleByteLimit:
+		return append(data, byte(n<<compactModeBits)|compactSingleByteMode)
+	case n < compactTwoBytesLimit:
+		b := make([]byte, 2)
+		binary.LittleEndian.PutUint16(b, uint16(n<<compactModeBits)|compactTwoBytesMode)
+		return append(data, b...)
+	case n < compactFourBytesLimit:
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32(n<<compactModeBits)|compactFourBytesMode)
+		return append(data, b...)
+	}
+
+	size := (bits.Len64(n) + 7) / 8
+	b := make([]byte, scaleU64Size)
+	binary.LittleEndian.PutUint64(b, n)
+	data = append(data, byte((size-compactBigIntMinSize)<<compactModeBits)|compactBigIntMode)
+	return append(data, b[:size]...)
+}
@@ -0,0 +1,161 @@
+package mmr_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	merkleMmr "github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+func TestLeafProofSCALELayout(t *testing.T) {
+	item := bytes.Repeat([]byte{0xaa}, 32)
+	proof := merkleMmr.NewProof(merkleMmr.LeafIndexToMMRSize(6), [][]byte{item},
+		[]types.Leaf{{Index: 5}}, hasher.Keccak256Hasher{})
+
+	data, err := proof.EncodeLeafProof()
+	if err != nil {
+		t.Fatalf("encode leaf proof: %s", err.Error())
+	}
+	want := "0500000000000000" + "0700000000000000" + "04" + strings.Repeat("aa", 32)
+	if hex.EncodeToString(data) != want {
+		t.Errorf("want %s got %x", want, data)
+	}
+
+	data, err = proof.EncodeLeafBatchProof()
+	if err != nil {
+		t.Fatalf("encode leaf batch proof: %s", err.Error())
+	}
+	want = "04" + "0500000000000000" + "0700000000000000" + "04" + strings.Repeat("aa", 32)
+	if hex.EncodeToString(data) != want {
+		t.Errorf("want %s got %x", want, data)
+	}
+}
+
+func TestLeafProofSCALECompactLength(t *testing.T) {
+	var leaves []types.Leaf
+	var items [][]byte
+	for i := 0; i < 64; i++ {
+		leaves = append(leaves, types.Leaf{Index: uint64(i)})
+		items = append(items, bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	proof := merkleMmr.NewProof(merkleMmr.LeafIndexToMMRSize(99), items, leaves, hasher.Keccak256Hasher{})
+
+	data, err := proof.EncodeLeafBatchProof()
+	if err != nil {
+		t.Fatalf("encode leaf batch proof: %s", err.Error())
+	}
+	// 64 doesn't fit the single byte mode, so it's encoded into two bytes
+	if data[0] != 0x01 || data[1] != 0x01 {
+		t.Errorf("want the length prefix 0x0101 got %x", data[:2])
+	}
+
+	decoded, err := merkleMmr.DecodeLeafBatchProof(data, hasher.Keccak256Hasher{})
+	if err != nil {
+		t.Fatalf("decode leaf batch proof: %s", err.Error())
+	}
+	if len(decoded.Leaves) != 64 || decoded.Leaves[63].Index != 63 {
+		t.Errorf("unexpected decoded leaves %v", decoded.Leaves)
+	}
+	if len(decoded.ProofItems()) != 64 || !bytes.Equal(decoded.ProofItems()[63], items[63]) {
+		t.Errorf("unexpected decoded proof items")
+	}
+	if decoded.MMRSize() != merkleMmr.LeafIndexToMMRSize(99) {
+		t.Errorf("want mmr size %d got %d", merkleMmr.LeafIndexToMMRSize(99), decoded.MMRSize())
+	}
+}
+
+func TestLeafProofSCALEVerify(t *testing.T) {
+	mmrTree := merkleMmr.NewMMR(0, merkleMmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+	positions := pushLeaves(t, mmrTree, 0, 27)
+	root, err := mmrTree.Root()
+	if err != nil {
+		t.Fatalf("root: %s", err.Error())
+	}
+
+	for _, leafIndices := range [][]uint64{{0}, {13}, {26}, {3, 9}, {0, 20, 26}} {
+		var posList []uint64
+		var leaves []types.Leaf
+		for _, i := range leafIndices {
+			posList = append(posList, positions[i])
+			leaves = append(leaves, types.Leaf{Index: i, Hash: uint32ToHash(uint32(i))})
+		}
+		proof, err := mmrTree.GenProof(posList)
+		if err != nil {
+			t.Fatalf("gen proof %v: %s", leafIndices, err.Error())
+		}
+		proof.LeavesToVerify(leaves)
+
+		data, err := proof.EncodeLeafBatchProof()
+		if err != nil {
+			t.Fatalf("encode leaf batch proof %v: %s", leafIndices, err.Error())
+		}
+		decoded, err := merkleMmr.DecodeLeafBatchProof(data, hasher.Keccak256Hasher{})
+		if err != nil {
+			t.Fatalf("decode leaf batch proof %v: %s", leafIndices, err.Error())
+		}
+		decoded.LeavesToVerify(leaves)
+		if !decoded.Verify(root) {
+			t.Errorf("decoded leaf batch proof %v is not valid", leafIndices)
+		}
+
+		if len(leafIndices) != 1 {
+			if _, err := proof.EncodeLeafProof(); !errors.Is(err, merkleMmr.ErrInvalidSCALEEncoding) {
+				t.Errorf("want ErrInvalidSCALEEncoding for a leaf proof of %v leaves", leafIndices)
+			}
+			continue
+		}
+
+		data, err = proof.EncodeLeafProof()
+		if err != nil {
+			t.Fatalf("encode leaf proof %v: %s", leafIndices, err.Error())
+		}
+		decoded, err = merkleMmr.DecodeLeafProof(data, hasher.Keccak256Hasher{})
+		if err != nil {
+			t.Fatalf("decode leaf proof %v: %s", leafIndices, err.Error())
+		}
+		decoded.LeavesToVerify(leaves)
+		if !decoded.Verify(root) {
+			t.Errorf("decoded leaf proof %v is not valid", leafIndices)
+		}
+	}
+}
+
+func TestLeafProofSCALEInvalidInput(t *testing.T) {
+	item := strings.Repeat("aa", 32)
+	tests := map[string]string{
+		"empty":                    "",
+		"truncated leaf index":     "05000000",
+		"zero leaf count":          "0500000000000000" + "0000000000000000" + "00",
+		"leaf beyond leaf count":   "0700000000000000" + "0700000000000000" + "00",
+		"truncated items":          "0500000000000000" + "0700000000000000" + "08" + item,
+		"huge items length":        "0500000000000000" + "0700000000000000" + "feffffff",
+		"oversized compact length": "0500000000000000" + "0700000000000000" + "17" + strings.Repeat("ff", 9),
+		"trailing data":            "0500000000000000" + "0700000000000000" + "04" + item + "00",
+	}
+
+	for name, encoded := range tests {
+		data, err := hex.DecodeString(encoded)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err.Error())
+		}
+		if _, err := merkleMmr.DecodeLeafProof(data, hasher.Keccak256Hasher{}); !errors.Is(err, merkleMmr.ErrInvalidSCALEEncoding) {
+			t.Errorf("%s: want ErrInvalidSCALEEncoding got %v", name, err)
+		}
+	}
+
+	invalidItem := merkleMmr.NewProof(merkleMmr.LeafIndexToMMRSize(6), [][]byte{{0xaa}},
+		[]types.Leaf{{Index: 5}}, hasher.Keccak256Hasher{})
+	if _, err := invalidItem.EncodeLeafProof(); !errors.Is(err, merkleMmr.ErrInvalidSCALEEncoding) {
+		t.Errorf("want ErrInvalidSCALEEncoding for a proof item of 1 byte got %v", err)
+	}
+
+	invalidSize := merkleMmr.NewProof(2, nil, []types.Leaf{{Index: 0}}, hasher.Keccak256Hasher{})
+	if _, err := invalidSize.EncodeLeafBatchProof(); !errors.Is(err, merkleMmr.ErrInvalidSCALEEncoding) {
+		t.Errorf("want ErrInvalidSCALEEncoding for an invalid mmr size got %v", err)
+	}
+}
@@ -8,12 +8,15 @@ func NewMemStore() MemStore {
 	return make(MemStore)
 }
 
-func (m MemStore) append(pos uint64, elem [][]byte) {
+// Append stores the elements starting from the given position
+func (m MemStore) Append(pos uint64, elem [][]byte) error {
 	for i := 0; i < len(elem); i++ {
 		m[pos+uint64(i)] = elem[i]
 	}
+	return nil
 }
 
-func (m MemStore) GetElem(pos uint64) []byte {
-	return m[pos]
+// GetElem returns the element stored at the given position
+func (m MemStore) GetElem(pos uint64) ([]byte, error) {
+	return m[pos], nil
 }
@@ -0,0 +1,156 @@
+package mpt
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// StorageKeySize is the size of the storage slots and values of the Ethereum accounts
+const StorageKeySize = 32
+
+// Account is the state of an Ethereum account, the value of its address in the state trie
+type Account struct {
+	Nonce       uint64
+	Balance     *big.Int
+	StorageRoot []byte
+	CodeHash    []byte
+}
+
+// AccountResult is the result of the eth_getProof RPC defined in EIP-1186
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     hexutil.Bytes   `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  hexutil.Bytes   `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the proof of a storage slot in the result of the eth_getProof RPC
+type StorageResult struct {
+	Key   string          `json:"key"`
+	Value *hexutil.Big    `json:"value"`
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// VerifyAccountProof verifies the proof of the account of the address in the state trie of the state root and
+// returns the account. It returns a nil account if the proof proves that the account doesn't exist. The key of the
+// account is the hash of the address.
+func VerifyAccountProof(h types.Hasher, stateRoot, address []byte, proof [][]byte) (*Account, error) {
+	key, err := h.Hash(address)
+	if err != nil {
+		return nil, err
+	}
+	value, err := VerifyProof(h, stateRoot, key, proof)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	var account Account
+	if err := rlp.DecodeBytes(value, &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// VerifyStorageProof verifies the proof of the 32 bytes storage slot in the storage trie of the storage root and
+// returns the 32 bytes value of the slot. The value of a slot that is not in the trie is zero. The key of the slot is
+// the hash of the slot and the value is the RLP encoded big endian integer without the leading zeros.
+func VerifyStorageProof(h types.Hasher, storageRoot, slot []byte, proof [][]byte) ([]byte, error) {
+	if len(slot) != StorageKeySize {
+		return nil, errInvalidKeySize
+	}
+	key, err := h.Hash(slot)
+	if err != nil {
+		return nil, err
+	}
+	value, err := VerifyProof(h, storageRoot, key, proof)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return make([]byte, StorageKeySize), nil
+	}
+
+	var word []byte
+	if err := rlp.DecodeBytes(value, &word); err != nil {
+		return nil, err
+	}
+	if len(word) > StorageKeySize {
+		return nil, errInvalidNode
+	}
+	return common.LeftPadBytes(word, StorageKeySize), nil
+}
+
+// Verify verifies the account proof and the storage proofs of the result against the state root, and checks that
+// the fields of the result are the proven ones. A missing account must have zero nonce and balance, the empty storage
+// root and the empty or zero code hash, and all of its storage values must be zero.
+func (r AccountResult) Verify(h types.Hasher, stateRoot []byte) error {
+	account, err := VerifyAccountProof(h, stateRoot, r.Address.Bytes(), bytesList(r.AccountProof))
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		if account, err = emptyAccount(h, r.CodeHash); err != nil {
+			return err
+		}
+	}
+
+	balance := (*big.Int)(r.Balance)
+	if balance == nil {
+		balance = new(big.Int)
+	}
+	if uint64(r.Nonce) != account.Nonce || balance.Cmp(account.Balance) != 0 ||
+		!bytes.Equal(r.StorageHash, account.StorageRoot) || !bytes.Equal(r.CodeHash, account.CodeHash) {
+		return errAccountMismatch
+	}
+
+	for _, storage := range r.StorageProof {
+		slot := common.HexToHash(storage.Key)
+		value, err := VerifyStorageProof(h, account.StorageRoot, slot.Bytes(), bytesList(storage.Proof))
+		if err != nil {
+			return err
+		}
+
+		expected := (*big.Int)(storage.Value)
+		if expected == nil {
+			expected = new(big.Int)
+		}
+		if new(big.Int).SetBytes(value).Cmp(expected) != 0 {
+			return errStorageMismatch
+		}
+	}
+	return nil
+}
+
+// emptyAccount returns the fields of a missing account, the nodes return either the hash of the empty code or a
+// zero hash as its code hash
+func emptyAccount(h types.Hasher, codeHash []byte) (*Account, error) {
+	storageRoot, err := h.Hash(rlp.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	emptyCodeHash, err := h.Hash(nil)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(codeHash, emptyCodeHash) {
+		emptyCodeHash = make([]byte, len(emptyCodeHash))
+	}
+	return &Account{Balance: new(big.Int), StorageRoot: storageRoot, CodeHash: emptyCodeHash}, nil
+}
+
+// bytesList converts the hex bytes of the RPC result to byte slices
+func bytesList(list []hexutil.Bytes) [][]byte {
+	result := make([][]byte, len(list))
+	for i := range list {
+		result[i] = list[i]
+	}
+	return result
+}
@@ -0,0 +1,11 @@
+package mpt
+
+import "errors"
+
+var (
+	errMissingNode     = errors.New("the proof doesn't have a node of the key path")
+	errInvalidNode     = errors.New("the proof has an invalid trie node")
+	errInvalidKeySize  = errors.New("the storage key size is not 32 bytes")
+	errAccountMismatch = errors.New("the account doesn't match the proven account")
+	errStorageMismatch = errors.New("the storage value doesn't match the proven value")
+)
@@ -0,0 +1,176 @@
+// Package mpt verifies the inclusion and exclusion proofs of the Ethereum Merkle-Patricia tries, such as the account
+// and storage proofs returned by the eth_getProof RPC
+package mpt
+
+import (
+	"bytes"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// branchNodeSize is the number of the items of a branch node, a child for every nibble and a value
+	branchNodeSize = 17
+	// shortNodeSize is the number of the items of an extension or a leaf node, the path and the child or the value
+	shortNodeSize = 2
+
+	nibbleBits   = 4
+	nibbleMask   = 0x0f
+	leafFlag     = 2
+	oddPathFlag  = 1
+	maxPathFlags = 3
+)
+
+// VerifyProof verifies the proof of the key in the trie of the root and returns the value of the key. It returns a nil
+// value if the proof proves that the key is not in the trie. The proof is the list of the RLP encoded nodes of the key
+// path, the nodes are referenced by their hashes of the given hasher, hasher.Keccak256Hasher for the Ethereum tries.
+func VerifyProof(h types.Hasher, root, key []byte, proof [][]byte) ([]byte, error) {
+	// the root of the empty trie is the hash of the empty string, it has no keys
+	emptyRoot, err := h.Hash(rlp.EmptyString)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(root, emptyRoot) {
+		return nil, nil
+	}
+
+	nodes := make(map[string][]byte, len(proof))
+	for _, node := range proof {
+		hash, err := h.Hash(node)
+		if err != nil {
+			return nil, err
+		}
+		nodes[string(hash)] = node
+	}
+
+	path := keyNibbles(key)
+	hash := root
+	// every node of the path is used once, so a proof with a cycle of nodes fails
+	for i := 0; i <= len(proof); i++ {
+		node, ok := nodes[string(hash)]
+		if !ok {
+			return nil, errMissingNode
+		}
+
+		var value []byte
+		path, hash, value, err = walk(node, path, len(root))
+		if err != nil || hash == nil {
+			return value, err
+		}
+	}
+	return nil, errInvalidNode
+}
+
+// walk follows the path from the RLP encoded node through its embedded nodes. It returns the rest of the path and the
+// hash of the next node when the path continues in a node referenced by its hash, otherwise it returns the value of
+// the path, which is nil when the path is not in the trie.
+func walk(node []byte, path []byte, hashSize int) (rest, hash, value []byte, err error) {
+	items, _, err := rlp.SplitList(node)
+	if err != nil {
+		return nil, nil, nil, errInvalidNode
+	}
+	count, err := rlp.CountValues(items)
+	if err != nil {
+		return nil, nil, nil, errInvalidNode
+	}
+
+	var child []byte
+	switch count {
+	case shortNodeSize:
+		compact, childItem, err := rlp.SplitString(items)
+		if err != nil {
+			return nil, nil, nil, errInvalidNode
+		}
+		nodePath, leaf, err := compactNibbles(compact)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if leaf {
+			if !bytes.Equal(path, nodePath) {
+				return nil, nil, nil, nil
+			}
+			value, _, err := rlp.SplitString(childItem)
+			if err != nil {
+				return nil, nil, nil, errInvalidNode
+			}
+			return nil, nil, value, nil
+		}
+
+		if !bytes.HasPrefix(path, nodePath) {
+			return nil, nil, nil, nil
+		}
+		path, child = path[len(nodePath):], childItem
+	case branchNodeSize:
+		var skipped int
+		if len(path) == 0 {
+			skipped = branchNodeSize - 1
+		} else {
+			skipped, path = int(path[0]), path[1:]
+		}
+		for i := 0; i < skipped; i++ {
+			if _, _, items, err = rlp.Split(items); err != nil {
+				return nil, nil, nil, errInvalidNode
+			}
+		}
+
+		if skipped == branchNodeSize-1 {
+			value, _, err := rlp.SplitString(items)
+			if err != nil {
+				return nil, nil, nil, errInvalidNode
+			}
+			if len(value) == 0 {
+				return nil, nil, nil, nil
+			}
+			return nil, nil, value, nil
+		}
+		child = items
+	default:
+		return nil, nil, nil, errInvalidNode
+	}
+
+	// the child is a hash, an embedded node of less than 32 bytes or empty
+	kind, content, tail, err := rlp.Split(child)
+	switch {
+	case err != nil:
+		return nil, nil, nil, errInvalidNode
+	case kind == rlp.List:
+		return walk(child[:len(child)-len(tail)], path, hashSize)
+	case len(content) == 0:
+		return nil, nil, nil, nil
+	case len(content) != hashSize:
+		return nil, nil, nil, errInvalidNode
+	}
+	return path, content, nil, nil
+}
+
+// keyNibbles splits the key bytes into the nibbles of the trie path
+func keyNibbles(key []byte) []byte {
+	nibbles := make([]byte, 0, len(key)*2)
+	for _, b := range key {
+		nibbles = append(nibbles, b>>nibbleBits, b&nibbleMask)
+	}
+	return nibbles
+}
+
+// compactNibbles decodes the hex prefix encoded path of an extension or a leaf node, the high nibble of the first byte
+// tells if the node is a leaf and if the path has an odd length, in which case the low nibble is the first one
+func compactNibbles(compact []byte) ([]byte, bool, error) {
+	if len(compact) == 0 {
+		return nil, false, errInvalidNode
+	}
+	flags := compact[0] >> nibbleBits
+	if flags > maxPathFlags {
+		return nil, false, errInvalidNode
+	}
+
+	nibbles := keyNibbles(compact)[1:]
+	if flags&oddPathFlag == 0 {
+		if nibbles[0] != 0 {
+			return nil, false, errInvalidNode
+		}
+		nibbles = nibbles[1:]
+	}
+	return nibbles, flags&leafFlag != 0, nil
+}
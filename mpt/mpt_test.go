@@ -0,0 +1,242 @@
+package mpt
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/require"
+)
+
+// proofList collects the nodes of the proofs of go-ethereum
+type proofList [][]byte
+
+func (l *proofList) Put(key []byte, value []byte) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func (l *proofList) Delete(key []byte) error {
+	return nil
+}
+
+func TestVerifyProof(t *testing.T) {
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	require.NoError(t, err)
+
+	// short keys and values make embedded nodes, and the keys that are prefixes of other keys are in the branch values
+	entries := map[string]string{
+		"do":    "verb",
+		"dog":   "puppy",
+		"doge":  "coin",
+		"horse": "stallion",
+		"d":     "letter",
+	}
+	for i := 0; i < 50; i++ {
+		key := crypto.Keccak256([]byte{byte(i)})
+		entries[string(key)] = string(crypto.Keccak256(key))
+	}
+	for key, value := range entries {
+		tr.Update([]byte(key), []byte(value))
+	}
+	root := tr.Hash()
+
+	keys := []string{"dogs", "ca", "", "horses", string(crypto.Keccak256([]byte("missing")))}
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		var proof proofList
+		require.NoError(t, tr.Prove([]byte(key), 0, &proof))
+
+		value, err := VerifyProof(hasher.Keccak256Hasher{}, root.Bytes(), []byte(key), proof)
+		require.NoError(t, err, "key %x", key)
+		if expected, ok := entries[key]; ok {
+			require.Equal(t, []byte(expected), value, "key %x", key)
+		} else {
+			require.Nil(t, value, "key %x", key)
+		}
+
+		// a proof of a different root
+		_, err = VerifyProof(hasher.Keccak256Hasher{}, crypto.Keccak256([]byte(key)), []byte(key), proof)
+		require.ErrorIs(t, err, errMissingNode)
+
+		// a modified node is not referenced by its parent anymore
+		last := len(proof) - 1
+		proof[last] = append(append([]byte{}, proof[last]...), 0)
+		_, err = VerifyProof(hasher.Keccak256Hasher{}, root.Bytes(), []byte(key), proof)
+		require.Error(t, err, "key %x", key)
+	}
+
+	// the empty trie has no keys
+	emptyRoot := crypto.Keccak256([]byte{0x80})
+	value, err := VerifyProof(hasher.Keccak256Hasher{}, emptyRoot, []byte("dog"), nil)
+	require.NoError(t, err)
+	require.Nil(t, value)
+}
+
+func TestCompactNibbles(t *testing.T) {
+	cases := []struct {
+		compact []byte
+		nibbles []byte
+		leaf    bool
+	}{
+		{[]byte{0x00, 0x12}, []byte{1, 2}, false},
+		{[]byte{0x11, 0x23}, []byte{1, 2, 3}, false},
+		{[]byte{0x20}, []byte{}, true},
+		{[]byte{0x3f, 0x1c}, []byte{0xf, 1, 0xc}, true},
+	}
+	for _, c := range cases {
+		nibbles, leaf, err := compactNibbles(c.compact)
+		require.NoError(t, err)
+		require.Equal(t, c.nibbles, nibbles)
+		require.Equal(t, c.leaf, leaf)
+	}
+
+	for _, invalid := range [][]byte{nil, {0x40}, {0x01}} {
+		_, _, err := compactNibbles(invalid)
+		require.ErrorIs(t, err, errInvalidNode)
+	}
+}
+
+// newTestState returns a state with a contract account of two storage slots and an externally owned account
+func newTestState(t *testing.T) (*state.StateDB, common.Hash) {
+	db, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	require.NoError(t, err)
+
+	contract := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	db.SetNonce(contract, 1)
+	db.SetBalance(contract, big.NewInt(1000))
+	db.SetCode(contract, []byte{0x60, 0x00})
+	db.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x2a"))
+	db.SetState(contract, common.HexToHash("0x02"), common.HexToHash("0xffffffffffffffffffffffffffffffff"))
+	for i := 0; i < 20; i++ {
+		db.SetBalance(common.BytesToAddress([]byte{byte(i), 2}), big.NewInt(int64(i+1)))
+	}
+
+	root, err := db.Commit(false)
+	require.NoError(t, err)
+	return db, root
+}
+
+// accountResult builds the eth_getProof result of the account like the RPC of go-ethereum
+func accountResult(t *testing.T, db *state.StateDB, address common.Address, keys ...string) AccountResult {
+	accountProof, err := db.GetProof(address)
+	require.NoError(t, err)
+
+	storageHash := crypto.Keccak256([]byte{0x80})
+	if storageTrie := db.StorageTrie(address); storageTrie != nil {
+		storageHash = storageTrie.Hash().Bytes()
+	}
+	result := AccountResult{
+		Address:      address,
+		AccountProof: hexBytesList(accountProof),
+		Balance:      (*hexutil.Big)(db.GetBalance(address)),
+		CodeHash:     db.GetCodeHash(address).Bytes(),
+		Nonce:        hexutil.Uint64(db.GetNonce(address)),
+		StorageHash:  storageHash,
+	}
+	for _, key := range keys {
+		// the storage proofs of an account without storage are empty
+		var storageProof [][]byte
+		if db.StorageTrie(address) != nil {
+			storageProof, err = db.GetStorageProof(address, common.HexToHash(key))
+			require.NoError(t, err)
+		}
+		result.StorageProof = append(result.StorageProof, StorageResult{
+			Key:   key,
+			Value: (*hexutil.Big)(db.GetState(address, common.HexToHash(key)).Big()),
+			Proof: hexBytesList(storageProof),
+		})
+	}
+	return result
+}
+
+func hexBytesList(list [][]byte) []hexutil.Bytes {
+	result := make([]hexutil.Bytes, len(list))
+	for i := range list {
+		result[i] = list[i]
+	}
+	return result
+}
+
+func TestAccountProof(t *testing.T) {
+	db, root := newTestState(t)
+	h := hasher.Keccak256Hasher{}
+	contract := common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	account, err := VerifyAccountProof(h, root.Bytes(), contract.Bytes(), mustProof(t, db, contract))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), account.Nonce)
+	require.Equal(t, big.NewInt(1000), account.Balance)
+	require.Equal(t, crypto.Keccak256([]byte{0x60, 0x00}), account.CodeHash)
+
+	storageProof, err := db.GetStorageProof(contract, common.HexToHash("0x01"))
+	require.NoError(t, err)
+	value, err := VerifyStorageProof(h, account.StorageRoot, common.HexToHash("0x01").Bytes(), storageProof)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToHash("0x2a").Bytes(), value)
+
+	// a missing slot is zero
+	storageProof, err = db.GetStorageProof(contract, common.HexToHash("0x03"))
+	require.NoError(t, err)
+	value, err = VerifyStorageProof(h, account.StorageRoot, common.HexToHash("0x03").Bytes(), storageProof)
+	require.NoError(t, err)
+	require.Equal(t, make([]byte, StorageKeySize), value)
+	_, err = VerifyStorageProof(h, account.StorageRoot, []byte{3}, storageProof)
+	require.ErrorIs(t, err, errInvalidKeySize)
+
+	// a missing account
+	missing := common.HexToAddress("0x3000000000000000000000000000000000000003")
+	account, err = VerifyAccountProof(h, root.Bytes(), missing.Bytes(), mustProof(t, db, missing))
+	require.NoError(t, err)
+	require.Nil(t, account)
+}
+
+func TestAccountResult(t *testing.T) {
+	db, root := newTestState(t)
+	h := hasher.Keccak256Hasher{}
+	contract := common.HexToAddress("0x1000000000000000000000000000000000000001")
+
+	result := accountResult(t, db, contract, "0x01", "0x0000000000000000000000000000000000000000000000000000000000000002", "0x03")
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	var decoded AccountResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.NoError(t, decoded.Verify(h, root.Bytes()))
+
+	eoa := accountResult(t, db, common.BytesToAddress([]byte{3, 2}), "0x01")
+	require.NoError(t, eoa.Verify(h, root.Bytes()))
+	missing := accountResult(t, db, common.HexToAddress("0x3000000000000000000000000000000000000003"), "0x01")
+	require.NoError(t, missing.Verify(h, root.Bytes()))
+
+	invalid := accountResult(t, db, contract, "0x01")
+	invalid.Balance = (*hexutil.Big)(big.NewInt(1001))
+	require.ErrorIs(t, invalid.Verify(h, root.Bytes()), errAccountMismatch)
+
+	invalid = accountResult(t, db, contract, "0x01")
+	invalid.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(43))
+	require.ErrorIs(t, invalid.Verify(h, root.Bytes()), errStorageMismatch)
+
+	invalid = accountResult(t, db, contract, "0x01")
+	invalid.StorageProof[0].Key = "0x02"
+	require.Error(t, invalid.Verify(h, root.Bytes()))
+
+	invalid = accountResult(t, db, common.HexToAddress("0x3000000000000000000000000000000000000003"))
+	invalid.Nonce = 1
+	require.ErrorIs(t, invalid.Verify(h, root.Bytes()), errAccountMismatch)
+}
+
+func mustProof(t *testing.T, db *state.StateDB, address common.Address) [][]byte {
+	proof, err := db.GetProof(address)
+	require.NoError(t, err)
+	return proof
+}
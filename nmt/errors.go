@@ -0,0 +1,14 @@
+package nmt
+
+import "errors"
+
+var (
+	errInvalidNamespaceSize = errors.New("the namespace size must be between 1 and 255 bytes")
+	errInvalidLeafSize      = errors.New("the leaf is shorter than its namespace")
+	errInvalidNodeSize      = errors.New("the node is shorter than its minimum and maximum namespaces")
+	errUnorderedNamespace   = errors.New("the leaf namespace is smaller than the namespace of the last leaf")
+	errNamespaceMismatch    = errors.New("the leaf namespace doesn't match the proven namespace")
+	errInvalidRange         = errors.New("the proof range is not a valid range of leaves")
+	errInvalidProofSize     = errors.New("the number of the proof nodes doesn't match the tree")
+	errInvalidLeavesCount   = errors.New("the number of the leaves doesn't match the proof range")
+)
@@ -0,0 +1,125 @@
+package nmt
+
+import (
+	"bytes"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// LeafPrefix is the prefix of the hashed namespaced data of the leaves
+	LeafPrefix = 0x00
+	// NodePrefix is the prefix of the hashed children of the inner nodes
+	NodePrefix = 0x01
+
+	maxNamespaceSize = 255
+	// namespacesCount is the number of the namespaces a node starts with, the minimum and the maximum
+	namespacesCount = 2
+)
+
+// NamespacedHasher hashes the leaves and the inner nodes of a namespaced merkle tree. Every node is its minimum
+// namespace, its maximum namespace and the hash of the prefixed leaf data or children nodes.
+//
+// When the maximum namespace is ignored, the namespace of all 0xFF bytes, which holds the parity data of the
+// erasure coded blocks, is left out of the maximum namespace of the nodes that have other namespaces too.
+type NamespacedHasher struct {
+	hasher             types.Hasher
+	namespaceSize      int
+	ignoreMaxNamespace bool
+	maxNamespace       []byte
+}
+
+// NewNamespacedHasher creates the namespaced hashing scheme of the given hasher and namespace size
+func NewNamespacedHasher(h types.Hasher, namespaceSize int, ignoreMaxNamespace bool) (NamespacedHasher, error) {
+	if namespaceSize < 1 || namespaceSize > maxNamespaceSize {
+		return NamespacedHasher{}, errInvalidNamespaceSize
+	}
+
+	return NamespacedHasher{
+		hasher:             h,
+		namespaceSize:      namespaceSize,
+		ignoreMaxNamespace: ignoreMaxNamespace,
+		maxNamespace:       bytes.Repeat([]byte{0xff}, namespaceSize),
+	}, nil
+}
+
+// NamespaceSize returns the size of the namespaces in bytes
+func (nh NamespacedHasher) NamespaceSize() int {
+	return nh.namespaceSize
+}
+
+// EmptyRoot returns the root of the tree without leaves, the zero minimum and maximum namespaces and the hash of no
+// data
+func (nh NamespacedHasher) EmptyRoot() ([]byte, error) {
+	hash, err := nh.hasher.Hash([]byte{})
+	if err != nil {
+		return nil, err
+	}
+
+	root := make([]byte, namespacesCount*nh.namespaceSize, namespacesCount*nh.namespaceSize+len(hash))
+	return append(root, hash...), nil
+}
+
+// HashLeaf returns the node of the leaf data, which starts with the namespace of the leaf
+func (nh NamespacedHasher) HashLeaf(data []byte) ([]byte, error) {
+	if len(data) < nh.namespaceSize {
+		return nil, errInvalidLeafSize
+	}
+
+	hash, err := nh.hasher.Hash(append([]byte{LeafPrefix}, data...))
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := data[:nh.namespaceSize]
+	node := make([]byte, 0, namespacesCount*nh.namespaceSize+len(hash))
+	node = append(append(append(node, namespace...), namespace...), hash...)
+	return node, nil
+}
+
+// HashNode returns the parent node of the left and the right nodes
+func (nh NamespacedHasher) HashNode(left, right []byte) ([]byte, error) {
+	if len(left) < namespacesCount*nh.namespaceSize || len(right) < namespacesCount*nh.namespaceSize {
+		return nil, errInvalidNodeSize
+	}
+
+	leftMin, leftMax := nh.MinNamespace(left), nh.MaxNamespace(left)
+	rightMin, rightMax := nh.MinNamespace(right), nh.MaxNamespace(right)
+
+	minNamespace := leftMin
+	if bytes.Compare(rightMin, leftMin) < 0 {
+		minNamespace = rightMin
+	}
+	var maxNamespace []byte
+	switch {
+	case nh.ignoreMaxNamespace && bytes.Equal(leftMin, nh.maxNamespace):
+		maxNamespace = nh.maxNamespace
+	case nh.ignoreMaxNamespace && bytes.Equal(rightMin, nh.maxNamespace):
+		maxNamespace = leftMax
+	case bytes.Compare(leftMax, rightMax) > 0:
+		maxNamespace = leftMax
+	default:
+		maxNamespace = rightMax
+	}
+
+	data := make([]byte, 0, 1+len(left)+len(right))
+	data = append(append(append(data, NodePrefix), left...), right...)
+	hash, err := nh.hasher.Hash(data)
+	if err != nil {
+		return nil, err
+	}
+
+	node := make([]byte, 0, namespacesCount*nh.namespaceSize+len(hash))
+	node = append(append(append(node, minNamespace...), maxNamespace...), hash...)
+	return node, nil
+}
+
+// MinNamespace returns the minimum namespace of the node
+func (nh NamespacedHasher) MinNamespace(node []byte) []byte {
+	return node[:nh.namespaceSize]
+}
+
+// MaxNamespace returns the maximum namespace of the node
+func (nh NamespacedHasher) MaxNamespace(node []byte) []byte {
+	return node[nh.namespaceSize : namespacesCount*nh.namespaceSize]
+}
@@ -0,0 +1,154 @@
+// Package nmt is responsible for creating the namespaced merkle trees of the data availability layers, their namespace
+// range and absence proofs and the verification
+package nmt
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ComposableFi/go-merkle-trees/merkle"
+)
+
+// Tree is a namespaced merkle tree. Its leaves are pushed in the order of their namespaces and every node commits to
+// the minimum and the maximum namespace of its subtree, so a proof of a range of leaves can also prove that no other
+// leaf of the tree has their namespace. The tree has the RFC 6962 shape of the merkle package.
+type Tree struct {
+	hasher NamespacedHasher
+	leaves [][]byte
+	nodes  [][]byte
+}
+
+// NewTree creates a new empty namespaced merkle tree of the hashing scheme
+func NewTree(hasher NamespacedHasher) *Tree {
+	return &Tree{hasher: hasher}
+}
+
+// Hasher returns the hashing scheme of the tree
+func (t *Tree) Hasher() NamespacedHasher {
+	return t.hasher
+}
+
+// Push appends the namespaced leaf data, which starts with the namespace of the leaf. The namespace must not be
+// smaller than the namespace of the last leaf.
+func (t *Tree) Push(data []byte) error {
+	node, err := t.hasher.HashLeaf(data)
+	if err != nil {
+		return err
+	}
+	if len(t.nodes) > 0 && bytes.Compare(t.namespace(len(t.nodes)-1), t.hasher.MinNamespace(node)) > 0 {
+		return errUnorderedNamespace
+	}
+
+	t.leaves = append(t.leaves, append([]byte{}, data...))
+	t.nodes = append(t.nodes, node)
+	return nil
+}
+
+// LeavesLen returns the number of the leaves
+func (t *Tree) LeavesLen() int {
+	return len(t.leaves)
+}
+
+// Leaves returns the namespaced data of the leaves in the range
+func (t *Tree) Leaves(start, end int) [][]byte {
+	return t.leaves[start:end]
+}
+
+// Root returns the root of the tree, its minimum and maximum namespaces followed by the root hash
+func (t *Tree) Root() ([]byte, error) {
+	if len(t.nodes) == 0 {
+		return t.hasher.EmptyRoot()
+	}
+	return t.subtreeNode(0, len(t.nodes))
+}
+
+// ProveRange returns the proof of the leaves in the range from start to end, end excluded
+func (t *Tree) ProveRange(start, end int) (Proof, error) {
+	if start < 0 || start >= end || end > len(t.nodes) {
+		return Proof{}, errInvalidRange
+	}
+
+	nodes, err := t.rangeProof(0, len(t.nodes), start, end)
+	if err != nil {
+		return Proof{}, err
+	}
+	return Proof{Start: start, End: end, Nodes: nodes}, nil
+}
+
+// ProveNamespace returns the proof of all of the leaves of the namespace. If the tree has no leaf of the namespace,
+// it's an absence proof of the leaf which would follow the leaves of the namespace, or an empty proof when the
+// namespace is out of the range of the tree namespaces.
+func (t *Tree) ProveNamespace(namespace []byte) (Proof, error) {
+	if len(namespace) != t.hasher.namespaceSize {
+		return Proof{}, errInvalidNamespaceSize
+	}
+
+	start := sort.Search(len(t.nodes), func(i int) bool {
+		return bytes.Compare(t.namespace(i), namespace) >= 0
+	})
+	end := sort.Search(len(t.nodes), func(i int) bool {
+		return bytes.Compare(t.namespace(i), namespace) > 0
+	})
+	if start < end {
+		return t.ProveRange(start, end)
+	}
+	if start == 0 || start == len(t.nodes) {
+		return Proof{}, nil
+	}
+
+	proof, err := t.ProveRange(start, start+1)
+	if err != nil {
+		return Proof{}, err
+	}
+	proof.LeafHash = t.nodes[start]
+	return proof, nil
+}
+
+// rangeProof returns the nodes of the subtree from start to end which are out of the proven range, from the left to
+// the right
+func (t *Tree) rangeProof(start, end, proofStart, proofEnd int) ([][]byte, error) {
+	if end <= proofStart || start >= proofEnd {
+		node, err := t.subtreeNode(start, end)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{node}, nil
+	}
+	if start >= proofStart && end <= proofEnd {
+		return nil, nil
+	}
+
+	k := start + int(merkle.SplitPoint(uint64(end-start)))
+	left, err := t.rangeProof(start, k, proofStart, proofEnd)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.rangeProof(k, end, proofStart, proofEnd)
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
+
+// subtreeNode returns the root node of the subtree of the leaves from start to end
+func (t *Tree) subtreeNode(start, end int) ([]byte, error) {
+	if end-start == 1 {
+		return t.nodes[start], nil
+	}
+
+	k := start + int(merkle.SplitPoint(uint64(end-start)))
+	left, err := t.subtreeNode(start, k)
+	if err != nil {
+		return nil, err
+	}
+	right, err := t.subtreeNode(k, end)
+	if err != nil {
+		return nil, err
+	}
+	return t.hasher.HashNode(left, right)
+}
+
+// namespace returns the namespace of the leaf
+func (t *Tree) namespace(index int) []byte {
+	return t.hasher.MinNamespace(t.nodes[index])
+}
@@ -0,0 +1,278 @@
+package nmt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+)
+
+func sum(data ...[]byte) []byte {
+	hash := sha256.Sum256(bytes.Join(data, nil))
+	return hash[:]
+}
+
+func newHasher(t *testing.T, namespaceSize int, ignoreMaxNamespace bool) NamespacedHasher {
+	h, err := NewNamespacedHasher(hasher.Sha256Hasher{}, namespaceSize, ignoreMaxNamespace)
+	require.NoError(t, err)
+	return h
+}
+
+// newTree creates a tree of one byte namespaces with a leaf for every namespace
+func newTree(t *testing.T, namespaces ...byte) *Tree {
+	tree := NewTree(newHasher(t, 1, true))
+	for i, namespace := range namespaces {
+		require.NoError(t, tree.Push([]byte{namespace, byte(i), 'd', 'a', 't', 'a'}))
+	}
+	return tree
+}
+
+func TestHasher(t *testing.T) {
+	h := newHasher(t, 1, true)
+	node, err := h.HashLeaf([]byte{0})
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{0, 0}, sum([]byte{LeafPrefix}, []byte{0})...), node)
+	node, err = h.HashLeaf([]byte("\x01leaf_data"))
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{1, 1}, sum([]byte{LeafPrefix}, []byte("\x01leaf_data"))...), node)
+	_, err = h.HashLeaf(nil)
+	require.ErrorIs(t, err, errInvalidLeafSize)
+
+	emptyRoot, err := h.EmptyRoot()
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{0, 0}, sum()...), emptyRoot)
+
+	h = newHasher(t, 2, true)
+	for _, test := range []struct {
+		name        string
+		left, right []byte
+		namespaces  []byte
+	}{
+		{"leftmin<rightmin && leftmax<rightmax", []byte{0, 0, 0, 0}, []byte{1, 1, 1, 1}, []byte{0, 0, 1, 1}},
+		{"leftmin==rightmin && leftmax<rightmax", []byte{0, 0, 0, 0}, []byte{0, 0, 1, 1}, []byte{0, 0, 1, 1}},
+		{"leftmin>rightmin && leftmax<rightmax", []byte{0, 1, 0, 1}, []byte{0, 0, 1, 1}, []byte{0, 0, 1, 1}},
+		{"leftmin<rightmin && leftmax>rightmax", []byte{0, 0, 1, 1}, []byte{0, 1, 0, 1}, []byte{0, 0, 1, 1}},
+		{"right is the max namespace", []byte{0, 0, 1, 1}, []byte{0xff, 0xff, 0xff, 0xff}, []byte{0, 0, 1, 1}},
+		{"left and right are the max namespace", []byte{0xff, 0xff, 0xff, 0xff}, []byte{0xff, 0xff, 0xff, 0xff},
+			[]byte{0xff, 0xff, 0xff, 0xff}},
+	} {
+		node, err := h.HashNode(test.left, test.right)
+		require.NoError(t, err, test.name)
+		require.Equal(t, append(test.namespaces, sum([]byte{NodePrefix}, test.left, test.right)...), node, test.name)
+	}
+
+	h = newHasher(t, 2, false)
+	node, err = h.HashNode([]byte{0, 0, 1, 1}, []byte{0xff, 0xff, 0xff, 0xff})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0xff, 0xff}, node[:4])
+	_, err = h.HashNode([]byte{0, 0, 1}, []byte{0xff, 0xff, 0xff, 0xff})
+	require.ErrorIs(t, err, errInvalidNodeSize)
+
+	_, err = NewNamespacedHasher(hasher.Sha256Hasher{}, 0, true)
+	require.ErrorIs(t, err, errInvalidNamespaceSize)
+}
+
+func TestRoot(t *testing.T) {
+	h := newHasher(t, 1, true)
+	tree := newTree(t, 0, 0, 1, 0xff)
+	leaves := make([][]byte, 4)
+	for i := range leaves {
+		var err error
+		leaves[i], err = h.HashLeaf(tree.Leaves(i, i+1)[0])
+		require.NoError(t, err)
+	}
+
+	left, err := h.HashNode(leaves[0], leaves[1])
+	require.NoError(t, err)
+	right, err := h.HashNode(leaves[2], leaves[3])
+	require.NoError(t, err)
+	expected, err := h.HashNode(left, right)
+	require.NoError(t, err)
+
+	root, err := tree.Root()
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+	// the parity namespace is ignored in the maximum namespace of the root
+	require.Equal(t, []byte{0, 1}, root[:2])
+	// the roots of celestiaorg/nmt v0.22.2 for the same leaves, NamespaceIDSize(1) and IgnoreMaxNamespace(true)
+	require.Equal(t, "00012f2d6c79e2b9176c864a2ee7d20fac13badf0736e46770f96249a9639556d322", hex.EncodeToString(root))
+
+	// the tree has the RFC 6962 shape, the odd leaf is promoted to the upper layer
+	require.NoError(t, tree.Push([]byte{0xff}))
+	last, err := h.HashLeaf([]byte{0xff})
+	require.NoError(t, err)
+	expected, err = h.HashNode(expected, last)
+	require.NoError(t, err)
+	root, err = tree.Root()
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+	require.Equal(t, "00015ac7b87983a55b75082f5924d1bbfa850054136876e65bff813f62928ab7e8bb", hex.EncodeToString(root))
+
+	require.ErrorIs(t, tree.Push([]byte{1}), errUnorderedNamespace)
+	require.Equal(t, 5, tree.LeavesLen())
+
+	// the roots of 8 bytes namespaces computed by celestiaorg/nmt v0.22.2 with sha256.New(), NamespaceIDSize(8) and
+	// IgnoreMaxNamespace of the hasher
+	h = newHasher(t, 8, true)
+	tree = NewTree(h)
+	emptyRoot, err := tree.Root()
+	require.NoError(t, err)
+	require.Equal(t, "00000000000000000000000000000000e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		hex.EncodeToString(emptyRoot))
+	for i, namespace := range []uint64{1, 2, 2, 3} {
+		leaf := make([]byte, 8)
+		binary.BigEndian.PutUint64(leaf, namespace)
+		require.NoError(t, tree.Push(append(leaf, []byte(fmt.Sprintf("leaf-%d", i))...)))
+	}
+	root, err = tree.Root()
+	require.NoError(t, err)
+	require.Equal(t, "000000000000000100000000000000039ba5ac48c2cd892c4590d9ef43a085abf6011c17a570f7d73752bad320ba96de",
+		hex.EncodeToString(root))
+	require.NoError(t, tree.Push(append(bytes.Repeat([]byte{0xff}, 8), []byte("leaf-4")...)))
+	root, err = tree.Root()
+	require.NoError(t, err)
+	require.Equal(t, "00000000000000010000000000000003b7f1a7b647e11e4fe88782ca047a7ad2935b147a051ae105eacc39172808d64b",
+		hex.EncodeToString(root))
+
+	h = newHasher(t, 8, false)
+	tree = NewTree(h)
+	for i, namespace := range []uint64{1, 2, 2, 3, math.MaxUint64} {
+		leaf := make([]byte, 8)
+		binary.BigEndian.PutUint64(leaf, namespace)
+		require.NoError(t, tree.Push(append(leaf, []byte(fmt.Sprintf("leaf-%d", i))...)))
+	}
+	root, err = tree.Root()
+	require.NoError(t, err)
+	require.Equal(t, "0000000000000001ffffffffffffffffb7f1a7b647e11e4fe88782ca047a7ad2935b147a051ae105eacc39172808d64b",
+		hex.EncodeToString(root))
+}
+
+func TestProveNamespace(t *testing.T) {
+	for _, namespaces := range [][]byte{
+		{2},
+		{2, 2},
+		{2, 4},
+		{0, 0, 2, 2, 2, 4, 6},
+		{0, 2, 2, 4, 4, 4, 4, 6, 8, 8, 0xff, 0xff},
+		{2, 2, 2, 2, 2, 2, 2, 2, 2, 4, 4, 0xff},
+	} {
+		tree := newTree(t, namespaces...)
+		root, err := tree.Root()
+		require.NoError(t, err)
+
+		for namespace := 0; namespace <= 0xff; namespace++ {
+			proof, err := tree.ProveNamespace([]byte{byte(namespace)})
+			require.NoError(t, err)
+
+			var leaves [][]byte
+			if !proof.IsOfAbsence() {
+				leaves = tree.Leaves(proof.Start, proof.End)
+			}
+			require.Equal(t, bytes.Count(namespaces, []byte{byte(namespace)}), len(leaves))
+			require.Equal(t, proof.IsEmpty(), namespace < int(namespaces[0]) || namespace > int(namespaces[len(namespaces)-1]))
+
+			verified, err := proof.VerifyNamespace(tree.Hasher(), []byte{byte(namespace)}, leaves, root)
+			require.NoError(t, err)
+			require.True(t, verified, "namespace %d of %v", namespace, namespaces)
+
+			if len(leaves) > 0 {
+				// a proof of a part of the namespace leaves proves their inclusion but not the namespace
+				if len(leaves) > 1 {
+					partial, err := tree.ProveRange(proof.Start+1, proof.End)
+					require.NoError(t, err)
+					verified, err = partial.VerifyNamespace(tree.Hasher(), []byte{byte(namespace)}, leaves[1:], root)
+					require.NoError(t, err)
+					require.False(t, verified, "namespace %d of %v", namespace, namespaces)
+					verified, err = partial.VerifyRange(tree.Hasher(), leaves[1:], root)
+					require.NoError(t, err)
+					require.True(t, verified, "namespace %d of %v", namespace, namespaces)
+				}
+
+				tampered := append([][]byte{}, leaves...)
+				tampered[0] = append(append([]byte{}, leaves[0]...), 0)
+				verified, err = proof.VerifyNamespace(tree.Hasher(), []byte{byte(namespace)}, tampered, root)
+				require.NoError(t, err)
+				require.False(t, verified, "namespace %d of %v", namespace, namespaces)
+
+				_, err = proof.VerifyNamespace(tree.Hasher(), []byte{byte(namespace)}, leaves[1:], root)
+				require.ErrorIs(t, err, errInvalidLeavesCount)
+				_, err = proof.VerifyNamespace(tree.Hasher(), []byte{byte(namespace + 1)}, leaves, root)
+				require.ErrorIs(t, err, errNamespaceMismatch)
+			}
+
+			if proof.IsOfAbsence() {
+				// the absence proof of the namespace doesn't prove the absence of the namespace of its leaf
+				verified, err = proof.VerifyNamespace(tree.Hasher(), proof.LeafHash[:1], nil, root)
+				require.NoError(t, err)
+				require.False(t, verified, "namespace %d of %v", namespace, namespaces)
+			}
+		}
+	}
+
+	// a nil node is not a missing subtree
+	tree := newTree(t, 1, 1, 1)
+	root, err := tree.Root()
+	require.NoError(t, err)
+	proof, err := tree.ProveNamespace([]byte{1})
+	require.NoError(t, err)
+	proof.Nodes = append(proof.Nodes, nil)
+	_, err = proof.VerifyNamespace(tree.Hasher(), []byte{1}, tree.Leaves(0, 3), root)
+	require.ErrorIs(t, err, errInvalidNodeSize)
+	proof.Nodes[len(proof.Nodes)-1] = []byte{1}
+	_, err = proof.VerifyRange(tree.Hasher(), tree.Leaves(0, 3), root)
+	require.ErrorIs(t, err, errInvalidNodeSize)
+
+	tree = newTree(t)
+	root, err = tree.Root()
+	require.NoError(t, err)
+	proof, err = tree.ProveNamespace([]byte{1})
+	require.NoError(t, err)
+	require.True(t, proof.IsEmpty())
+	verified, err := proof.VerifyNamespace(tree.Hasher(), []byte{1}, nil, root)
+	require.NoError(t, err)
+	require.True(t, verified)
+	_, err = tree.ProveNamespace([]byte{1, 1})
+	require.ErrorIs(t, err, errInvalidNamespaceSize)
+}
+
+func TestProveRange(t *testing.T) {
+	for count := 1; count <= 13; count++ {
+		namespaces := make([]byte, count)
+		for i := range namespaces {
+			namespaces[i] = byte(i)
+		}
+		tree := newTree(t, namespaces...)
+		root, err := tree.Root()
+		require.NoError(t, err)
+
+		for start := 0; start < count; start++ {
+			for end := start + 1; end <= count; end++ {
+				proof, err := tree.ProveRange(start, end)
+				require.NoError(t, err)
+				verified, err := proof.VerifyRange(tree.Hasher(), tree.Leaves(start, end), root)
+				require.NoError(t, err)
+				require.True(t, verified, "range %d-%d of %d", start, end, count)
+
+				if len(proof.Nodes) > 0 {
+					proof.Nodes = proof.Nodes[1:]
+					verified, err = proof.VerifyRange(tree.Hasher(), tree.Leaves(start, end), root)
+					if err == nil {
+						require.False(t, verified, "range %d-%d of %d", start, end, count)
+					}
+				}
+			}
+		}
+
+		_, err = tree.ProveRange(0, count+1)
+		require.ErrorIs(t, err, errInvalidRange)
+		_, err = tree.ProveRange(1, 1)
+		require.ErrorIs(t, err, errInvalidRange)
+	}
+}
@@ -0,0 +1,221 @@
+package nmt
+
+import (
+	"bytes"
+
+	"github.com/ComposableFi/go-merkle-trees/merkle"
+)
+
+// Proof is a proof of the range of the leaves from Start to End, End excluded. The nodes are the roots of the
+// subtrees out of the range, from the left to the right.
+//
+// A proof with the hash of a leaf is an absence proof of a namespace: the leaf is the first leaf of a greater
+// namespace and the nodes prove that all of the leaves before it have smaller namespaces. An empty proof proves the
+// absence of a namespace out of the range of the root namespaces.
+type Proof struct {
+	Start    int
+	End      int
+	Nodes    [][]byte
+	LeafHash []byte
+}
+
+// IsEmpty returns true if the proof has no range, no nodes and no leaf hash
+func (p Proof) IsEmpty() bool {
+	return p.Start == p.End && len(p.Nodes) == 0 && p.LeafHash == nil
+}
+
+// IsOfAbsence returns true if the proof is an absence proof of the leaf hash
+func (p Proof) IsOfAbsence() bool {
+	return p.LeafHash != nil
+}
+
+// VerifyNamespace verifies that the namespaced leaves are all of the leaves of the namespace in the tree of the root.
+// The leaves must be empty for an absence proof.
+func (p Proof) VerifyNamespace(h NamespacedHasher, namespace []byte, leaves [][]byte, root []byte) (bool, error) {
+	if len(namespace) != h.namespaceSize {
+		return false, errInvalidNamespaceSize
+	}
+	if len(root) < namespacesCount*h.namespaceSize {
+		return false, errInvalidNodeSize
+	}
+
+	if p.IsEmpty() {
+		if len(leaves) != 0 {
+			return false, errInvalidLeavesCount
+		}
+		emptyRoot, err := h.EmptyRoot()
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(root, emptyRoot) || bytes.Compare(namespace, h.MinNamespace(root)) < 0 ||
+			bytes.Compare(namespace, h.MaxNamespace(root)) > 0, nil
+	}
+
+	var leafHashes [][]byte
+	if p.IsOfAbsence() {
+		if len(leaves) != 0 || p.End-p.Start != 1 {
+			return false, errInvalidLeavesCount
+		}
+		if len(p.LeafHash) < namespacesCount*h.namespaceSize {
+			return false, errInvalidNodeSize
+		}
+		if bytes.Compare(h.MinNamespace(p.LeafHash), namespace) <= 0 {
+			return false, nil
+		}
+		leafHashes = [][]byte{p.LeafHash}
+	} else {
+		for _, leaf := range leaves {
+			if len(leaf) < h.namespaceSize {
+				return false, errInvalidLeafSize
+			}
+			if !bytes.Equal(leaf[:h.namespaceSize], namespace) {
+				return false, errNamespaceMismatch
+			}
+		}
+		var err error
+		if leafHashes, err = hashLeaves(h, leaves); err != nil {
+			return false, err
+		}
+	}
+
+	calculated, err := p.calculateRoot(h, leafHashes)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.Equal(calculated.root, root) {
+		return false, nil
+	}
+
+	// the subtrees out of the range must not have any leaf of the namespace
+	for _, node := range calculated.leftNodes {
+		if bytes.Compare(h.MaxNamespace(node), namespace) >= 0 {
+			return false, nil
+		}
+	}
+	for _, node := range calculated.rightNodes {
+		if bytes.Compare(h.MinNamespace(node), namespace) <= 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// VerifyRange verifies that the namespaced leaves are the leaves of the proof range in the tree of the root, without
+// checking that the range has all of the leaves of their namespace
+func (p Proof) VerifyRange(h NamespacedHasher, leaves [][]byte, root []byte) (bool, error) {
+	if p.IsOfAbsence() {
+		return false, errInvalidLeavesCount
+	}
+	leafHashes, err := hashLeaves(h, leaves)
+	if err != nil {
+		return false, err
+	}
+
+	calculated, err := p.calculateRoot(h, leafHashes)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(calculated.root, root), nil
+}
+
+// calculatedRoot is the root calculated from a proof and the proof nodes on the left and the right of the range
+type calculatedRoot struct {
+	root       []byte
+	leftNodes  [][]byte
+	rightNodes [][]byte
+}
+
+// calculateRoot calculates the root from the hashes of the leaves in the proof range and the proof nodes. It starts
+// from the smallest perfect subtree that contains the range and merges the remaining nodes on its right, so the number
+// of the tree leaves isn't needed.
+func (p Proof) calculateRoot(h NamespacedHasher, leafHashes [][]byte) (calculatedRoot, error) {
+	if p.Start < 0 || p.Start >= p.End {
+		return calculatedRoot{}, errInvalidRange
+	}
+	if len(leafHashes) != p.End-p.Start {
+		return calculatedRoot{}, errInvalidLeavesCount
+	}
+
+	// every proof node has the namespaces of its subtree, a nil node is never a missing subtree
+	for _, node := range p.Nodes {
+		if len(node) < namespacesCount*h.namespaceSize {
+			return calculatedRoot{}, errInvalidNodeSize
+		}
+	}
+
+	var result calculatedRoot
+	nodes := p.Nodes
+	popNode := func(left bool) ([]byte, bool) {
+		if len(nodes) == 0 {
+			return nil, false
+		}
+		node := nodes[0]
+		nodes = nodes[1:]
+		if left {
+			result.leftNodes = append(result.leftNodes, node)
+		} else {
+			result.rightNodes = append(result.rightNodes, node)
+		}
+		return node, true
+	}
+
+	// subtreeRoot returns false when the subtree is out of the tree, which happens only on the right of the range
+	var subtreeRoot func(start, end int) ([]byte, bool, error)
+	subtreeRoot = func(start, end int) ([]byte, bool, error) {
+		if end <= p.Start || start >= p.End {
+			node, found := popNode(end <= p.Start)
+			return node, found, nil
+		}
+		if end-start == 1 {
+			leafHash := leafHashes[0]
+			leafHashes = leafHashes[1:]
+			return leafHash, true, nil
+		}
+
+		k := start + int(merkle.SplitPoint(uint64(end-start)))
+		left, found, err := subtreeRoot(start, k)
+		if err != nil {
+			return nil, false, err
+		}
+		// only the right subtree can be missing, when the range is at the end of the tree
+		if !found {
+			return nil, false, errInvalidProofSize
+		}
+		right, found, err := subtreeRoot(k, end)
+		if err != nil || !found {
+			return left, true, err
+		}
+		node, err := h.HashNode(left, right)
+		return node, true, err
+	}
+
+	size := 1
+	if p.End > 1 {
+		size = 2 * int(merkle.SplitPoint(uint64(p.End)))
+	}
+	root, _, err := subtreeRoot(0, size)
+	if err != nil {
+		return calculatedRoot{}, err
+	}
+	for node, found := popNode(false); found; node, found = popNode(false) {
+		if root, err = h.HashNode(root, node); err != nil {
+			return calculatedRoot{}, err
+		}
+	}
+
+	result.root = root
+	return result, nil
+}
+
+// hashLeaves returns the hashes of the namespaced leaves
+func hashLeaves(h NamespacedHasher, leaves [][]byte) ([][]byte, error) {
+	hashes := make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		hash, err := h.HashLeaf(leaf)
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
@@ -0,0 +1,9 @@
+package smt
+
+import "errors"
+
+var (
+	errInvalidKeySize   = errors.New("the key size is not 32 bytes")
+	errInvalidProofSize = errors.New("the proof doesn't have a side node for every layer of the tree")
+	errInvalidBitmask   = errors.New("the bitmask doesn't match the number of the proof side nodes")
+)
@@ -0,0 +1,167 @@
+package smt
+
+import (
+	"bytes"
+	"encoding/hex"
+)
+
+// Key returns the key of the proof
+func (p Proof) Key() []byte {
+	return p.key
+}
+
+// Value returns the value of the key, it is nil for a non-membership proof
+func (p Proof) Value() []byte {
+	return p.value
+}
+
+// IsMembership returns true if the proof proves that the key is set, otherwise it proves that the key is not set
+func (p Proof) IsMembership() bool {
+	return len(p.value) != 0
+}
+
+// SideNodes returns the sibling hashes of the key path sorted from the bottom to the top of the tree
+func (p Proof) SideNodes() [][]byte {
+	return p.sideNodes
+}
+
+// SideNodesHex returns the hex encoded side nodes
+func (p Proof) SideNodesHex() []string {
+	var hexList []string
+	for _, node := range p.sideNodes {
+		hexList = append(hexList, hex.EncodeToString(node))
+	}
+	return hexList
+}
+
+// CalculateRoot calculates the tree root from the value of the key and the side nodes
+func (p Proof) CalculateRoot() ([]byte, error) {
+	if len(p.key) != KeySize {
+		return nil, errInvalidKeySize
+	}
+	if len(p.sideNodes) != Depth {
+		return nil, errInvalidProofSize
+	}
+
+	var current []byte
+	if p.IsMembership() {
+		leafHash, err := p.hasher.Hash(p.value)
+		if err != nil {
+			return nil, err
+		}
+		current = leafHash
+	} else {
+		emptyHash, err := p.hasher.Hash([]byte{})
+		if err != nil {
+			return nil, err
+		}
+		current = make([]byte, len(emptyHash))
+	}
+
+	for i, sibling := range p.sideNodes {
+		parent, err := hashChildren(p.hasher, p.key, Depth-i, current, sibling)
+		if err != nil {
+			return nil, err
+		}
+		current = parent
+	}
+
+	return current, nil
+}
+
+// Verify calculates the root from the proof and compares it with the given root. A membership proof proves that
+// the key holds the proof value, a non-membership proof proves that the key is not set.
+func (p Proof) Verify(root []byte) (bool, error) {
+	calculatedRoot, err := p.CalculateRoot()
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(calculatedRoot, root), nil
+}
+
+// Compact removes the side nodes that are default hashes from the proof and marks them in the bitmask
+func (p Proof) Compact() (CompactProof, error) {
+	defaultHashes, err := calculateDefaultHashes(p.hasher)
+	if err != nil {
+		return CompactProof{}, err
+	}
+	return p.compact(defaultHashes)
+}
+
+func (p Proof) compact(defaultHashes [][]byte) (CompactProof, error) {
+	if len(p.sideNodes) != Depth {
+		return CompactProof{}, errInvalidProofSize
+	}
+
+	bitmask := make([]byte, Depth/bitsInByte)
+	var sideNodes [][]byte
+	for i, node := range p.sideNodes {
+		if bytes.Equal(node, defaultHashes[Depth-i]) {
+			bitmask[i/bitsInByte] |= 1 << (i % bitsInByte)
+			continue
+		}
+		sideNodes = append(sideNodes, node)
+	}
+
+	return NewCompactProof(p.key, p.value, bitmask, sideNodes, p.hasher), nil
+}
+
+// Key returns the key of the proof
+func (p CompactProof) Key() []byte {
+	return p.key
+}
+
+// Value returns the value of the key, it is nil for a non-membership proof
+func (p CompactProof) Value() []byte {
+	return p.value
+}
+
+// Bitmask returns the bitmask of the side nodes that are default hashes, the bit i is the bit i%8 of the byte i/8
+func (p CompactProof) Bitmask() []byte {
+	return p.bitmask
+}
+
+// SideNodes returns the side nodes that are not default hashes sorted from the bottom to the top of the tree
+func (p CompactProof) SideNodes() [][]byte {
+	return p.sideNodes
+}
+
+// Decompact restores the full proof by inserting the default hashes that are marked in the bitmask
+func (p CompactProof) Decompact() (Proof, error) {
+	if len(p.bitmask) != Depth/bitsInByte {
+		return Proof{}, errInvalidBitmask
+	}
+
+	defaultHashes, err := calculateDefaultHashes(p.hasher)
+	if err != nil {
+		return Proof{}, err
+	}
+
+	sideNodes := make([][]byte, Depth)
+	next := 0
+	for i := 0; i < Depth; i++ {
+		if p.bitmask[i/bitsInByte]&(1<<(i%bitsInByte)) != 0 {
+			sideNodes[i] = defaultHashes[Depth-i]
+			continue
+		}
+		if next >= len(p.sideNodes) {
+			return Proof{}, errInvalidBitmask
+		}
+		sideNodes[i] = p.sideNodes[next]
+		next++
+	}
+	if next != len(p.sideNodes) {
+		return Proof{}, errInvalidBitmask
+	}
+
+	return NewProof(p.key, p.value, sideNodes, p.hasher), nil
+}
+
+// Verify restores the full proof and verifies it against the given root
+func (p CompactProof) Verify(root []byte) (bool, error) {
+	proof, err := p.Decompact()
+	if err != nil {
+		return false, err
+	}
+	return proof.Verify(root)
+}
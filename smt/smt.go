@@ -0,0 +1,220 @@
+// Package smt is responsible for creating the sparse merkle tree, its membership and non-membership proofs and
+// the verification
+package smt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+// NewTree creates a new empty sparse merkle tree. It requires a hash algorithm to be specified.
+func NewTree(hasher types.Hasher) (*Tree, error) {
+	defaultHashes, err := calculateDefaultHashes(hasher)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tree{
+		hasher:        hasher,
+		defaultHashes: defaultHashes,
+		nodes:         make(map[string][]byte),
+		values:        make(map[string][]byte),
+	}, nil
+}
+
+// Root returns the tree root. The root of an empty tree is the default hash of the top layer.
+func (t *Tree) Root() []byte {
+	return t.node(0, nil)
+}
+
+// RootHex returns a hex encoded string of the tree root
+func (t *Tree) RootHex() string {
+	return hex.EncodeToString(t.Root())
+}
+
+// Get returns the value of the key. It returns nil if the key is not set.
+func (t *Tree) Get(key []byte) ([]byte, error) {
+	if len(key) != KeySize {
+		return nil, errInvalidKeySize
+	}
+	return t.values[string(key)], nil
+}
+
+// Update sets the value of the key and updates the hashes of the path from its leaf to the root. An empty value
+// deletes the key.
+func (t *Tree) Update(key, value []byte) error {
+	if len(key) != KeySize {
+		return errInvalidKeySize
+	}
+	if len(value) == 0 {
+		return t.Delete(key)
+	}
+
+	leafHash, err := t.hasher.Hash(value)
+	if err != nil {
+		return err
+	}
+
+	if err := t.updatePath(key, leafHash); err != nil {
+		return err
+	}
+
+	t.values[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// Delete removes the key from the tree, so its leaf holds an empty value again
+func (t *Tree) Delete(key []byte) error {
+	if len(key) != KeySize {
+		return errInvalidKeySize
+	}
+	if _, ok := t.values[string(key)]; !ok {
+		return nil
+	}
+
+	if err := t.updatePath(key, t.defaultHashes[Depth]); err != nil {
+		return err
+	}
+
+	delete(t.values, string(key))
+	return nil
+}
+
+// Prove returns the membership proof of the key if it is set, otherwise it returns its non-membership proof
+func (t *Tree) Prove(key []byte) (Proof, error) {
+	if len(key) != KeySize {
+		return Proof{}, errInvalidKeySize
+	}
+
+	sideNodes := make([][]byte, Depth)
+	for depth := Depth; depth > 0; depth-- {
+		sideNodes[Depth-depth] = t.node(depth, siblingPath(key, depth))
+	}
+
+	var value []byte
+	if v, ok := t.values[string(key)]; ok {
+		value = append([]byte{}, v...)
+	}
+
+	return NewProof(append([]byte{}, key...), value, sideNodes, t.hasher), nil
+}
+
+// ProveCompact returns the compact membership or non-membership proof of the key
+func (t *Tree) ProveCompact(key []byte) (CompactProof, error) {
+	proof, err := t.Prove(key)
+	if err != nil {
+		return CompactProof{}, err
+	}
+	return proof.compact(t.defaultHashes)
+}
+
+// updatePath sets the leaf hash of the key and recalculates the nodes from the leaf to the root. Nodes that are
+// equal to the default hashes are removed from the storage.
+func (t *Tree) updatePath(key, leafHash []byte) error {
+	current := leafHash
+	for depth := Depth; depth >= 0; depth-- {
+		t.setNode(depth, pathPrefix(key, depth), current)
+		if depth == 0 {
+			break
+		}
+
+		sibling := t.node(depth, siblingPath(key, depth))
+		parent, err := hashChildren(t.hasher, key, depth, current, sibling)
+		if err != nil {
+			return err
+		}
+		current = parent
+	}
+	return nil
+}
+
+// node returns the hash of the node at the given depth with the given path prefix
+func (t *Tree) node(depth int, prefix []byte) []byte {
+	if hash, ok := t.nodes[nodeID(depth, prefix)]; ok {
+		return hash
+	}
+	return t.defaultHashes[depth]
+}
+
+// setNode stores the hash of the node, or removes it if it's the default hash of the depth
+func (t *Tree) setNode(depth int, prefix, hash []byte) {
+	id := nodeID(depth, prefix)
+	if bytes.Equal(hash, t.defaultHashes[depth]) {
+		delete(t.nodes, id)
+		return
+	}
+	t.nodes[id] = hash
+}
+
+// calculateDefaultHashes returns the hashes of empty subtrees for every depth, where the last item is the
+// empty leaf and the first one is the root of an empty tree
+func calculateDefaultHashes(hasher types.Hasher) ([][]byte, error) {
+	// the empty leaf is a slice of zero bytes of the hash size
+	emptyHash, err := hasher.Hash([]byte{})
+	if err != nil {
+		return nil, err
+	}
+
+	defaultHashes := make([][]byte, Depth+1)
+	defaultHashes[Depth] = make([]byte, len(emptyHash))
+	for depth := Depth - 1; depth >= 0; depth-- {
+		child := defaultHashes[depth+1]
+		hash, err := hasher.Hash(append(append([]byte{}, child...), child...))
+		if err != nil {
+			return nil, err
+		}
+		defaultHashes[depth] = hash
+	}
+
+	return defaultHashes, nil
+}
+
+// hashChildren calculates the parent hash of the node of the key at the given depth and its sibling
+func hashChildren(hasher types.Hasher, key []byte, depth int, node, sibling []byte) ([]byte, error) {
+	if isRightChild(key, depth) {
+		return hasher.Hash(append(append([]byte{}, sibling...), node...))
+	}
+	return hasher.Hash(append(append([]byte{}, node...), sibling...))
+}
+
+// isRightChild returns true if the node of the key at the given depth is the right child of its parent, which is
+// the case when the key bit of that depth is set. The bits are read from the most significant one.
+func isRightChild(key []byte, depth int) bool {
+	return hasBit(key, depth-1)
+}
+
+// hasBit returns true if the bit at the given index is set, the bits are indexed from the most significant one
+func hasBit(key []byte, index int) bool {
+	return key[index/bitsInByte]&(1<<(bitsInByte-1-index%bitsInByte)) != 0
+}
+
+// pathPrefix returns the key with all of the bits after the given depth cleared
+func pathPrefix(key []byte, depth int) []byte {
+	prefix := make([]byte, KeySize)
+	copy(prefix, key[:(depth+bitsInByte-1)/bitsInByte])
+	if depth%bitsInByte != 0 {
+		prefix[depth/bitsInByte] &= 0xff << (bitsInByte - depth%bitsInByte)
+	}
+	return prefix
+}
+
+// siblingPath returns the path prefix of the sibling of the node of the key at the given depth
+func siblingPath(key []byte, depth int) []byte {
+	prefix := pathPrefix(key, depth)
+	index := depth - 1
+	prefix[index/bitsInByte] ^= 1 << (bitsInByte - 1 - index%bitsInByte)
+	return prefix
+}
+
+// nodeID returns the storage key of the node at the given depth with the given path prefix
+func nodeID(depth int, prefix []byte) string {
+	id := make([]byte, 2, 2+KeySize)
+	binary.BigEndian.PutUint16(id, uint16(depth))
+	if prefix == nil {
+		prefix = make([]byte, KeySize)
+	}
+	return string(append(id, prefix...))
+}
@@ -0,0 +1,249 @@
+package smt
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(i int) []byte {
+	key := sha256.Sum256([]byte{byte(i >> 8), byte(i)})
+	return key[:]
+}
+
+func newTestTree(t *testing.T, h types.Hasher, count int) *Tree {
+	tree, err := NewTree(h)
+	require.NoError(t, err)
+	for i := 0; i < count; i++ {
+		require.NoError(t, tree.Update(testKey(i), []byte{byte(i), 1}))
+	}
+	return tree
+}
+
+func TestEmptyTree(t *testing.T) {
+	for _, h := range []types.Hasher{hasher.Keccak256Hasher{}, hasher.Sha256Hasher{}} {
+		tree := newTestTree(t, h, 0)
+
+		defaultHashes, err := calculateDefaultHashes(h)
+		require.NoError(t, err)
+		require.Equal(t, defaultHashes[0], tree.Root())
+		require.Equal(t, make([]byte, 32), defaultHashes[Depth])
+
+		value, err := tree.Get(testKey(0))
+		require.NoError(t, err)
+		require.Nil(t, value)
+	}
+}
+
+func TestUpdateGetDelete(t *testing.T) {
+	tree := newTestTree(t, hasher.Keccak256Hasher{}, 0)
+	emptyRoot := tree.Root()
+
+	require.NoError(t, tree.Update(testKey(1), []byte("value")))
+	value, err := tree.Get(testKey(1))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), value)
+	require.NotEqual(t, emptyRoot, tree.Root())
+
+	require.NoError(t, tree.Update(testKey(1), []byte("new value")))
+	value, err = tree.Get(testKey(1))
+	require.NoError(t, err)
+	require.Equal(t, []byte("new value"), value)
+
+	require.NoError(t, tree.Delete(testKey(1)))
+	value, err = tree.Get(testKey(1))
+	require.NoError(t, err)
+	require.Nil(t, value)
+	require.Equal(t, emptyRoot, tree.Root())
+	require.Empty(t, tree.nodes)
+
+	// an empty value deletes the key as well
+	require.NoError(t, tree.Update(testKey(2), []byte("value")))
+	require.NoError(t, tree.Update(testKey(2), nil))
+	require.Equal(t, emptyRoot, tree.Root())
+
+	_, err = tree.Get([]byte{1})
+	require.ErrorIs(t, err, errInvalidKeySize)
+	require.ErrorIs(t, tree.Update([]byte{1}, []byte("value")), errInvalidKeySize)
+	require.ErrorIs(t, tree.Delete([]byte{1}), errInvalidKeySize)
+}
+
+func TestRootDoesNotDependOnUpdatesOrder(t *testing.T) {
+	tree := newTestTree(t, hasher.Sha256Hasher{}, 50)
+
+	reversed := newTestTree(t, hasher.Sha256Hasher{}, 0)
+	for i := 49; i >= 0; i-- {
+		require.NoError(t, reversed.Update(testKey(i), []byte{byte(i), 1}))
+	}
+	require.Equal(t, tree.RootHex(), reversed.RootHex())
+
+	// adding and removing keys brings the tree back to the same root
+	require.NoError(t, reversed.Update(testKey(100), []byte("value")))
+	require.NotEqual(t, tree.RootHex(), reversed.RootHex())
+	require.NoError(t, reversed.Delete(testKey(100)))
+	require.Equal(t, tree.RootHex(), reversed.RootHex())
+}
+
+func TestAdjacentKeys(t *testing.T) {
+	tree := newTestTree(t, hasher.Keccak256Hasher{}, 0)
+
+	// the keys differ only in the last bit, so they are siblings at the bottom of the tree
+	left := make([]byte, KeySize)
+	right := make([]byte, KeySize)
+	right[KeySize-1] = 1
+	require.NoError(t, tree.Update(left, []byte("left")))
+	require.NoError(t, tree.Update(right, []byte("right")))
+
+	for _, key := range [][]byte{left, right} {
+		proof, err := tree.Prove(key)
+		require.NoError(t, err)
+		verified, err := proof.Verify(tree.Root())
+		require.NoError(t, err)
+		require.True(t, verified)
+	}
+
+	leftLeaf, err := hasher.Keccak256Hasher{}.Hash([]byte("left"))
+	require.NoError(t, err)
+	proof, err := tree.Prove(right)
+	require.NoError(t, err)
+	require.Equal(t, leftLeaf, proof.SideNodes()[0])
+}
+
+func TestMembershipProofs(t *testing.T) {
+	for _, h := range []types.Hasher{hasher.Keccak256Hasher{}, hasher.Sha256Hasher{}} {
+		tree := newTestTree(t, h, 20)
+		root := tree.Root()
+
+		for i := 0; i < 20; i++ {
+			proof, err := tree.Prove(testKey(i))
+			require.NoError(t, err)
+			require.True(t, proof.IsMembership())
+			require.Equal(t, []byte{byte(i), 1}, proof.Value())
+			require.Len(t, proof.SideNodes(), Depth)
+
+			verified, err := proof.Verify(root)
+			require.NoError(t, err)
+			require.True(t, verified)
+
+			// the proof doesn't prove a different value or a different key
+			wrongValue := NewProof(proof.Key(), []byte("wrong"), proof.SideNodes(), h)
+			verified, err = wrongValue.Verify(root)
+			require.NoError(t, err)
+			require.False(t, verified)
+
+			wrongKey := NewProof(testKey(i+1), proof.Value(), proof.SideNodes(), h)
+			verified, err = wrongKey.Verify(root)
+			require.NoError(t, err)
+			require.False(t, verified)
+
+			// the key is not set anymore
+			nonMembership := NewProof(proof.Key(), nil, proof.SideNodes(), h)
+			verified, err = nonMembership.Verify(root)
+			require.NoError(t, err)
+			require.False(t, verified)
+		}
+	}
+}
+
+func TestNonMembershipProofs(t *testing.T) {
+	tree := newTestTree(t, hasher.Keccak256Hasher{}, 20)
+	root := tree.Root()
+
+	for i := 20; i < 40; i++ {
+		proof, err := tree.Prove(testKey(i))
+		require.NoError(t, err)
+		require.False(t, proof.IsMembership())
+		require.Nil(t, proof.Value())
+
+		verified, err := proof.Verify(root)
+		require.NoError(t, err)
+		require.True(t, verified)
+
+		// the key can't be proven to hold a value
+		membership := NewProof(proof.Key(), []byte{byte(i), 1}, proof.SideNodes(), hasher.Keccak256Hasher{})
+		verified, err = membership.Verify(root)
+		require.NoError(t, err)
+		require.False(t, verified)
+	}
+
+	// the proof of the empty tree consists only of default hashes
+	emptyTree := newTestTree(t, hasher.Keccak256Hasher{}, 0)
+	proof, err := emptyTree.Prove(testKey(0))
+	require.NoError(t, err)
+	verified, err := proof.Verify(emptyTree.Root())
+	require.NoError(t, err)
+	require.True(t, verified)
+}
+
+func TestCompactProofs(t *testing.T) {
+	tree := newTestTree(t, hasher.Sha256Hasher{}, 20)
+	root := tree.Root()
+
+	for i := 0; i < 40; i++ {
+		proof, err := tree.Prove(testKey(i))
+		require.NoError(t, err)
+
+		compactProof, err := tree.ProveCompact(testKey(i))
+		require.NoError(t, err)
+		require.Len(t, compactProof.Bitmask(), Depth/bitsInByte)
+		// with 20 random keys only the nodes close to the root have non empty siblings
+		require.Less(t, len(compactProof.SideNodes()), 10)
+
+		compacted, err := proof.Compact()
+		require.NoError(t, err)
+		require.Equal(t, compactProof, compacted)
+
+		decompacted, err := compactProof.Decompact()
+		require.NoError(t, err)
+		require.Equal(t, proof, decompacted)
+
+		verified, err := compactProof.Verify(root)
+		require.NoError(t, err)
+		require.True(t, verified)
+	}
+
+	compactProof, err := tree.ProveCompact(testKey(0))
+	require.NoError(t, err)
+
+	invalidBitmask := NewCompactProof(compactProof.Key(), compactProof.Value(), compactProof.Bitmask()[1:],
+		compactProof.SideNodes(), hasher.Sha256Hasher{})
+	_, err = invalidBitmask.Verify(root)
+	require.ErrorIs(t, err, errInvalidBitmask)
+
+	missingNode := NewCompactProof(compactProof.Key(), compactProof.Value(), compactProof.Bitmask(),
+		compactProof.SideNodes()[1:], hasher.Sha256Hasher{})
+	_, err = missingNode.Verify(root)
+	require.ErrorIs(t, err, errInvalidBitmask)
+
+	extraNode := NewCompactProof(compactProof.Key(), compactProof.Value(), compactProof.Bitmask(),
+		append(compactProof.SideNodes(), root), hasher.Sha256Hasher{})
+	_, err = extraNode.Verify(root)
+	require.ErrorIs(t, err, errInvalidBitmask)
+}
+
+func TestInvalidProofs(t *testing.T) {
+	tree := newTestTree(t, hasher.Keccak256Hasher{}, 5)
+
+	_, err := tree.Prove([]byte{1})
+	require.ErrorIs(t, err, errInvalidKeySize)
+
+	proof, err := tree.Prove(testKey(1))
+	require.NoError(t, err)
+
+	shortProof := NewProof(proof.Key(), proof.Value(), proof.SideNodes()[1:], hasher.Keccak256Hasher{})
+	_, err = shortProof.Verify(tree.Root())
+	require.ErrorIs(t, err, errInvalidProofSize)
+
+	invalidKey := NewProof(proof.Key()[1:], proof.Value(), proof.SideNodes(), hasher.Keccak256Hasher{})
+	_, err = invalidKey.Verify(tree.Root())
+	require.ErrorIs(t, err, errInvalidKeySize)
+
+	// the proof is not valid anymore after the tree is updated
+	require.NoError(t, tree.Update(testKey(6), []byte("value")))
+	verified, err := proof.Verify(tree.Root())
+	require.NoError(t, err)
+	require.False(t, verified)
+}
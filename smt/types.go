@@ -0,0 +1,67 @@
+package smt
+
+import (
+	"github.com/ComposableFi/go-merkle-trees/types"
+)
+
+const (
+	// KeySize is the size of the tree keys in bytes
+	KeySize = 32
+	// Depth is the number of layers between the leaves and the root, one layer for every bit of the key
+	Depth = KeySize * bitsInByte
+
+	bitsInByte = 8
+)
+
+// Tree is a sparse Merkle tree of the fixed depth of 256 layers, where every key addresses one leaf and all of the
+// leaves that are not set hold an empty value. Subtrees that contain only empty leaves are never stored, their
+// hashes are the default hashes that are calculated once for every depth.
+//
+// A leaf hash is the hash of its value and the hash of an empty leaf is a slice of zero bytes, while an inner node
+// hash is the hash of the concatenated children hashes.
+type Tree struct {
+	hasher        types.Hasher
+	defaultHashes [][]byte
+	nodes         map[string][]byte
+	values        map[string][]byte
+}
+
+// Proof is a membership or non-membership proof of a key in the sparse Merkle tree. The side nodes are sorted from the
+// bottom to the top of the tree. A nil value means that the proof is a non-membership proof.
+type Proof struct {
+	key       []byte
+	value     []byte
+	sideNodes [][]byte
+	hasher    types.Hasher
+}
+
+// NewProof creates a new instance of the sparse merkle proof. A nil value makes it a non-membership proof.
+func NewProof(key, value []byte, sideNodes [][]byte, hasher types.Hasher) Proof {
+	return Proof{
+		key:       key,
+		value:     value,
+		sideNodes: sideNodes,
+		hasher:    hasher,
+	}
+}
+
+// CompactProof is a Proof that doesn't keep the side nodes that are default hashes of empty subtrees. Bit i of the
+// bitmask is set when the side node i of the proof is a default hash.
+type CompactProof struct {
+	key       []byte
+	value     []byte
+	bitmask   []byte
+	sideNodes [][]byte
+	hasher    types.Hasher
+}
+
+// NewCompactProof creates a new instance of the compact sparse merkle proof
+func NewCompactProof(key, value, bitmask []byte, sideNodes [][]byte, hasher types.Hasher) CompactProof {
+	return CompactProof{
+		key:       key,
+		value:     value,
+		bitmask:   bitmask,
+		sideNodes: sideNodes,
+		hasher:    hasher,
+	}
+}
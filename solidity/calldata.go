@@ -0,0 +1,143 @@
+package solidity
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ComposableFi/go-merkle-trees/merkle"
+	"github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+const hashSize = 32
+
+// MerkleVerifierABI is the ABI of the generated merkle proof verifier contract
+const MerkleVerifierABI = `[{"type":"function","name":"verify","stateMutability":"pure","inputs":[
+	{"name":"root","type":"bytes32"},
+	{"name":"leafIndices","type":"uint256[]"},
+	{"name":"leafHashes","type":"bytes32[]"},
+	{"name":"proofHashes","type":"bytes32[]"},
+	{"name":"totalLeavesCount","type":"uint256"}
+],"outputs":[{"name":"","type":"bool"}]}]`
+
+// MMRVerifierABI is the ABI of the generated mmr proof verifier contract
+const MMRVerifierABI = `[{"type":"function","name":"verify","stateMutability":"pure","inputs":[
+	{"name":"root","type":"bytes32"},
+	{"name":"mmrSize","type":"uint256"},
+	{"name":"leafIndices","type":"uint256[]"},
+	{"name":"leafHashes","type":"bytes32[]"},
+	{"name":"proofItems","type":"bytes32[]"}
+],"outputs":[{"name":"","type":"bool"}]}]`
+
+var (
+	merkleVerifierABI = mustParseABI(MerkleVerifierABI)
+	mmrVerifierABI    = mustParseABI(MMRVerifierABI)
+)
+
+// MerkleProofCalldata returns the calldata of the verify function of the generated merkle proof verifier contract
+// for the proof and the expected root. The leaves are sorted by their indices as the contract expects.
+func MerkleProofCalldata(proof merkle.Proof, root []byte) ([]byte, error) {
+	leaves := sortedLeaves(proof.Leaves())
+	if len(leaves) == 0 {
+		return nil, errEmptyProof
+	}
+
+	rootHash, err := toHash(root)
+	if err != nil {
+		return nil, err
+	}
+	leafIndices, leafHashes, err := splitLeaves(leaves)
+	if err != nil {
+		return nil, err
+	}
+	proofHashes, err := toHashes(proof.ProofHashes())
+	if err != nil {
+		return nil, err
+	}
+
+	return merkleVerifierABI.Pack("verify", rootHash, leafIndices, leafHashes, proofHashes,
+		new(big.Int).SetUint64(proof.TotalLeavesCount()))
+}
+
+// MMRProofCalldata returns the calldata of the verify function of the generated mmr proof verifier contract for the
+// proof and the expected root. The leaves of the proof must have their hashes set, they are sorted by their indices
+// as the contract expects.
+func MMRProofCalldata(proof *mmr.Proof, root []byte) ([]byte, error) {
+	leaves := sortedLeaves(proof.Leaves)
+	if len(leaves) == 0 {
+		return nil, errEmptyProof
+	}
+
+	rootHash, err := toHash(root)
+	if err != nil {
+		return nil, err
+	}
+	leafIndices, leafHashes, err := splitLeaves(leaves)
+	if err != nil {
+		return nil, err
+	}
+	proofItems, err := toHashes(proof.ProofItems())
+	if err != nil {
+		return nil, err
+	}
+
+	return mmrVerifierABI.Pack("verify", rootHash, new(big.Int).SetUint64(proof.MMRSize()), leafIndices, leafHashes,
+		proofItems)
+}
+
+// sortedLeaves returns a copy of the leaves sorted by their indices
+func sortedLeaves(leaves []types.Leaf) []types.Leaf {
+	sorted := make([]types.Leaf, len(leaves))
+	copy(sorted, leaves)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	return sorted
+}
+
+// splitLeaves returns the indices and the hashes of the leaves in the ABI types
+func splitLeaves(leaves []types.Leaf) ([]*big.Int, [][hashSize]byte, error) {
+	indices := make([]*big.Int, len(leaves))
+	hashes := make([][hashSize]byte, len(leaves))
+	for i := 0; i < len(leaves); i++ {
+		hash, err := toHash(leaves[i].Hash)
+		if err != nil {
+			return nil, nil, err
+		}
+		indices[i] = new(big.Int).SetUint64(leaves[i].Index)
+		hashes[i] = hash
+	}
+	return indices, hashes, nil
+}
+
+// toHashes converts the hashes to the bytes32 ABI type
+func toHashes(list [][]byte) ([][hashSize]byte, error) {
+	hashes := make([][hashSize]byte, len(list))
+	for i := 0; i < len(list); i++ {
+		hash, err := toHash(list[i])
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hash
+	}
+	return hashes, nil
+}
+
+// toHash converts the hash to the bytes32 ABI type
+func toHash(b []byte) ([hashSize]byte, error) {
+	var hash [hashSize]byte
+	if len(b) != hashSize {
+		return hash, errInvalidHashSize
+	}
+	copy(hash[:], b)
+	return hash, nil
+}
+
+// mustParseABI parses the ABI definitions of the generated contracts, which are constants
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,11 @@
+package solidity
+
+import "errors"
+
+var (
+	errInvalidIdentifier = errors.New("library and contract names must be different solidity identifiers")
+	errInvalidPragma     = errors.New("invalid solidity version pragma")
+	errInvalidLicense    = errors.New("invalid SPDX license identifier")
+	errInvalidHashSize   = errors.New("only 32 bytes hashes can be verified on chain")
+	errEmptyProof        = errors.New("the proof doesn't have any leaves")
+)
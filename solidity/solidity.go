@@ -0,0 +1,89 @@
+// Package solidity generates the Solidity libraries that verify the merkle and mmr proofs of this library on
+// Ethereum, and exports the proofs in the calldata layout of the generated verifier contracts. The generated code
+// follows the exact tree shape of the merkle and mmr packages: the odd node of a merkle layer is promoted to the next
+// layer and the mmr peaks are bagged from the right to the left. Only the proofs of the trees built with the
+// Keccak256Hasher can be verified on chain.
+package solidity
+
+import (
+	"bytes"
+	_ "embed" // the Solidity templates are embedded into the package
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+var (
+	//go:embed templates/merkle.sol.tmpl
+	merkleTemplateSource string
+	//go:embed templates/mmr.sol.tmpl
+	mmrTemplateSource string
+
+	merkleTemplate = template.Must(template.New("merkle").Parse(merkleTemplateSource))
+	mmrTemplate    = template.Must(template.New("mmr").Parse(mmrTemplateSource))
+
+	identifierRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+)
+
+// Options customizes the generated Solidity source
+type Options struct {
+	// LibraryName is the name of the library that verifies the proofs
+	LibraryName string
+	// ContractName is the name of the contract that exposes the library to external calls
+	ContractName string
+	// Pragma is the solidity version pragma, for example ^0.8.0
+	Pragma string
+	// License is the SPDX license identifier of the generated file
+	License string
+}
+
+// DefaultMerkleOptions returns the default options of the merkle proof verifier
+func DefaultMerkleOptions() Options {
+	return Options{
+		LibraryName:  "MerkleProof",
+		ContractName: "MerkleProofVerifier",
+		Pragma:       "^0.8.0",
+		License:      "MIT",
+	}
+}
+
+// DefaultMMROptions returns the default options of the mmr proof verifier
+func DefaultMMROptions() Options {
+	return Options{
+		LibraryName:  "MMRProof",
+		ContractName: "MMRProofVerifier",
+		Pragma:       "^0.8.0",
+		License:      "MIT",
+	}
+}
+
+// GenerateMerkleVerifier returns the Solidity source of the library and the contract that verify merkle.Proof
+// multiproofs
+func GenerateMerkleVerifier(opts Options) (string, error) {
+	return generate(merkleTemplate, opts)
+}
+
+// GenerateMMRVerifier returns the Solidity source of the library and the contract that verify mmr.Proof proofs
+func GenerateMMRVerifier(opts Options) (string, error) {
+	return generate(mmrTemplate, opts)
+}
+
+// generate validates the options and executes the template
+func generate(tmpl *template.Template, opts Options) (string, error) {
+	if !identifierRegexp.MatchString(opts.LibraryName) || !identifierRegexp.MatchString(opts.ContractName) ||
+		opts.LibraryName == opts.ContractName {
+		return "", errInvalidIdentifier
+	}
+	if opts.Pragma == "" || strings.ContainsAny(opts.Pragma, ";\r\n") {
+		return "", errInvalidPragma
+	}
+	if opts.License == "" || strings.ContainsAny(opts.License, "\r\n") {
+		return "", errInvalidLicense
+	}
+
+	var source bytes.Buffer
+	if err := tmpl.Execute(&source, opts); err != nil {
+		return "", err
+	}
+	return source.String(), nil
+}
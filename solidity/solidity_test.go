@@ -0,0 +1,324 @@
+package solidity
+
+import (
+	"bytes"
+	"flag"
+	"math/big"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+	"github.com/ComposableFi/go-merkle-trees/merkle"
+	"github.com/ComposableFi/go-merkle-trees/mmr"
+	"github.com/ComposableFi/go-merkle-trees/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the generated sources in testdata")
+
+// vector is a proof produced by this library in the calldata layout of a verifier contract
+type vector struct {
+	name     string
+	calldata []byte
+	root     []byte
+}
+
+func keccakLeaf(t testing.TB, i int) []byte {
+	h, err := hasher.Keccak256Hasher{}.Hash([]byte{byte(i >> 8), byte(i)})
+	require.NoError(t, err)
+	return h
+}
+
+func merkleVectors(t testing.TB) []vector {
+	var vectors []vector
+	var leaves [][]byte
+	for size := 1; size <= 13; size++ {
+		leaves = append(leaves, keccakLeaf(t, size-1))
+		tree, err := merkle.NewTree(hasher.Keccak256Hasher{}).FromLeaves(leaves)
+		require.NoError(t, err)
+
+		var indicesList [][]uint64
+		for i := uint64(0); i < uint64(size); i++ {
+			indicesList = append(indicesList, []uint64{i})
+			for j := i + 1; j < uint64(size); j++ {
+				indicesList = append(indicesList, []uint64{i, j})
+			}
+		}
+		indicesList = append(indicesList, []uint64{0, uint64(size) / 2, uint64(size) - 1})
+
+		for _, indices := range indicesList {
+			indices = uniqueIndices(indices)
+			proof := tree.Proof(indices)
+			verified, err := proof.Verify(tree.Root())
+			require.NoError(t, err)
+			require.True(t, verified)
+
+			calldata, err := MerkleProofCalldata(proof, tree.Root())
+			require.NoError(t, err)
+			vectors = append(vectors, vector{name: "merkle", calldata: calldata, root: tree.Root()})
+		}
+	}
+	return vectors
+}
+
+func mmrVectors(t testing.TB) []vector {
+	var vectors []vector
+	for size := uint64(1); size <= 25; size++ {
+		mmrTree := mmr.NewMMR(0, mmr.NewMemStore(), []types.Leaf{}, hasher.Keccak256Hasher{})
+		var positions []uint64
+		for i := uint64(0); i < size; i++ {
+			pos, err := mmrTree.Push(keccakLeaf(t, int(i)))
+			require.NoError(t, err)
+			positions = append(positions, pos)
+		}
+		root, err := mmrTree.Root()
+		require.NoError(t, err)
+
+		var indicesList [][]uint64
+		for i := uint64(0); i < size; i++ {
+			indicesList = append(indicesList, []uint64{i})
+		}
+		indicesList = append(indicesList, []uint64{0, size / 2, size - 1}, []uint64{size / 3, size / 2})
+
+		for _, indices := range indicesList {
+			indices = uniqueIndices(indices)
+			var posList []uint64
+			var leaves []types.Leaf
+			for _, i := range indices {
+				posList = append(posList, positions[i])
+				leaves = append(leaves, types.Leaf{Index: i, Hash: keccakLeaf(t, int(i))})
+			}
+			proof, err := mmrTree.GenProof(posList)
+			require.NoError(t, err)
+			proof.LeavesToVerify(leaves)
+
+			calldata, err := MMRProofCalldata(proof, root)
+			require.NoError(t, err)
+			require.True(t, proof.Verify(root))
+			vectors = append(vectors, vector{name: "mmr", calldata: calldata, root: root})
+		}
+	}
+	return vectors
+}
+
+// uniqueIndices removes the repeated indices of a sorted list
+func uniqueIndices(indices []uint64) []uint64 {
+	var unique []uint64
+	for i, index := range indices {
+		if i == 0 || index != indices[i-1] {
+			unique = append(unique, index)
+		}
+	}
+	return unique
+}
+
+func TestGeneratedSources(t *testing.T) {
+	merkleSource, err := GenerateMerkleVerifier(DefaultMerkleOptions())
+	require.NoError(t, err)
+	mmrSource, err := GenerateMMRVerifier(DefaultMMROptions())
+	require.NoError(t, err)
+
+	for name, source := range map[string]string{"MerkleProof.sol": merkleSource, "MMRProof.sol": mmrSource} {
+		path := filepath.Join("testdata", name)
+		if *update {
+			require.NoError(t, os.WriteFile(path, []byte(source), 0o600))
+		}
+		expected, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, string(expected), source, "%s is outdated, run the tests with -update", path)
+	}
+
+	opts := Options{LibraryName: "TreeProof", ContractName: "TreeVerifier", Pragma: "0.8.19", License: "Apache-2.0"}
+	source, err := GenerateMerkleVerifier(opts)
+	require.NoError(t, err)
+	require.Contains(t, source, "// SPDX-License-Identifier: Apache-2.0\n")
+	require.Contains(t, source, "pragma solidity 0.8.19;\n")
+	require.Contains(t, source, "library TreeProof {")
+	require.Contains(t, source, "contract TreeVerifier {")
+	require.Contains(t, source, "return TreeProof.verify(")
+}
+
+func TestGenerateInvalidOptions(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*Options)
+		err    error
+	}{
+		{"empty library name", func(o *Options) { o.LibraryName = "" }, errInvalidIdentifier},
+		{"invalid library name", func(o *Options) { o.LibraryName = "Merkle Proof" }, errInvalidIdentifier},
+		{"invalid contract name", func(o *Options) { o.ContractName = "1Verifier" }, errInvalidIdentifier},
+		{"same names", func(o *Options) { o.ContractName = o.LibraryName }, errInvalidIdentifier},
+		{"empty pragma", func(o *Options) { o.Pragma = "" }, errInvalidPragma},
+		{"injected pragma", func(o *Options) { o.Pragma = "^0.8.0; contract X {}" }, errInvalidPragma},
+		{"multiline license", func(o *Options) { o.License = "MIT\ncontract X {}" }, errInvalidLicense},
+	}
+
+	for _, c := range cases {
+		opts := DefaultMerkleOptions()
+		c.modify(&opts)
+		_, err := GenerateMerkleVerifier(opts)
+		require.ErrorIs(t, err, c.err, c.name)
+		_, err = GenerateMMRVerifier(opts)
+		require.ErrorIs(t, err, c.err, c.name)
+	}
+}
+
+func TestMerkleProofCalldata(t *testing.T) {
+	var leaves [][]byte
+	for i := 0; i < 6; i++ {
+		leaves = append(leaves, keccakLeaf(t, i))
+	}
+	tree, err := merkle.NewTree(hasher.Keccak256Hasher{}).FromLeaves(leaves)
+	require.NoError(t, err)
+	proof := tree.Proof([]uint64{1, 4})
+
+	calldata, err := MerkleProofCalldata(proof, tree.Root())
+	require.NoError(t, err)
+	selector := crypto.Keccak256([]byte("verify(bytes32,uint256[],bytes32[],bytes32[],uint256)"))[:4]
+	require.Equal(t, selector, calldata[:4])
+
+	args, err := merkleVerifierABI.Methods["verify"].Inputs.Unpack(calldata[4:])
+	require.NoError(t, err)
+	require.Equal(t, common.BytesToHash(tree.Root()), common.Hash(args[0].([32]byte)))
+	require.Equal(t, []*big.Int{big.NewInt(1), big.NewInt(4)}, args[1])
+	require.Equal(t, [][32]byte{common.BytesToHash(leaves[1]), common.BytesToHash(leaves[4])}, args[2])
+	require.Len(t, args[3], len(proof.ProofHashes()))
+	require.Equal(t, big.NewInt(6), args[4])
+
+	_, err = MerkleProofCalldata(proof, tree.Root()[1:])
+	require.ErrorIs(t, err, errInvalidHashSize)
+	_, err = MerkleProofCalldata(merkle.NewProof(nil, nil, 6, hasher.Keccak256Hasher{}), tree.Root())
+	require.ErrorIs(t, err, errEmptyProof)
+
+	sha256Tree, err := merkle.NewTree(hasher.Sha256Hasher{}).FromLeaves([][]byte{{1}, {2}})
+	require.NoError(t, err)
+	_, err = MerkleProofCalldata(sha256Tree.Proof([]uint64{0}), tree.Root())
+	require.ErrorIs(t, err, errInvalidHashSize)
+}
+
+func TestMMRProofCalldata(t *testing.T) {
+	proof := mmr.NewProof(mmr.LeafIndexToMMRSize(6), [][]byte{keccakLeaf(t, 100)},
+		[]types.Leaf{{Index: 5, Hash: keccakLeaf(t, 5)}, {Index: 2, Hash: keccakLeaf(t, 2)}}, hasher.Keccak256Hasher{})
+	root := keccakLeaf(t, 200)
+
+	calldata, err := MMRProofCalldata(proof, root)
+	require.NoError(t, err)
+	selector := crypto.Keccak256([]byte("verify(bytes32,uint256,uint256[],bytes32[],bytes32[])"))[:4]
+	require.Equal(t, selector, calldata[:4])
+
+	args, err := mmrVerifierABI.Methods["verify"].Inputs.Unpack(calldata[4:])
+	require.NoError(t, err)
+	require.Equal(t, common.BytesToHash(root), common.Hash(args[0].([32]byte)))
+	require.Equal(t, new(big.Int).SetUint64(mmr.LeafIndexToMMRSize(6)), args[1])
+	require.Equal(t, []*big.Int{big.NewInt(2), big.NewInt(5)}, args[2])
+	require.Equal(t, [][32]byte{common.BytesToHash(keccakLeaf(t, 2)), common.BytesToHash(keccakLeaf(t, 5))}, args[3])
+	require.Equal(t, [][32]byte{common.BytesToHash(keccakLeaf(t, 100))}, args[4])
+
+	proof.LeavesToVerify([]types.Leaf{{Index: 2}})
+	_, err = MMRProofCalldata(proof, root)
+	require.ErrorIs(t, err, errInvalidHashSize)
+}
+
+// TestVerifiersWithSolc compiles the generated sources and runs the verifier contracts in the EVM against the proofs
+// produced by this library. It requires solc in the PATH, it's skipped without solc unless the CI variable is set.
+func TestVerifiersWithSolc(t *testing.T) {
+	solc, err := exec.LookPath("solc")
+	if err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatal("solc is not installed, the generated verifiers can't be executed in CI")
+		}
+		t.Skip("solc is not installed, the generated verifiers are not executed")
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"MerkleProof.sol", "MMRProof.sol"} {
+		output, err := exec.Command(solc, "--optimize", "--bin-runtime", "-o", dir, filepath.Join("testdata", name)).
+			CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	for contract, vectors := range map[string][]vector{
+		"MerkleProofVerifier": merkleVectors(t),
+		"MMRProofVerifier":    mmrVectors(t),
+	} {
+		hexCode, err := os.ReadFile(filepath.Join(dir, contract+".bin-runtime"))
+		require.NoError(t, err)
+		code := common.FromHex(strings.TrimSpace(string(hexCode)))
+
+		for i, v := range vectors {
+			result, _, err := runtime.Execute(code, v.calldata, nil)
+			require.NoError(t, err, "%s vector %d", v.name, i)
+			require.Equal(t, common.LeftPadBytes([]byte{1}, 32), result, "%s vector %d", v.name, i)
+
+			// the same proof doesn't verify a different root
+			result, _, err = runtime.Execute(code, wrongRootCalldata(v), nil)
+			require.NoError(t, err, "%s vector %d", v.name, i)
+			require.Equal(t, make([]byte, 32), result, "%s vector %d", v.name, i)
+		}
+	}
+}
+
+// TestVectorsVerify decodes the calldata of the vectors with the ABI of the contracts and verifies the decoded proofs
+// with this library, so the calldata executed by TestVerifiersWithSolc carries exactly the proofs and the roots.
+func TestVectorsVerify(t *testing.T) {
+	for _, vectors := range [][]vector{merkleVectors(t), mmrVectors(t)} {
+		require.NotEmpty(t, vectors)
+		for i, v := range vectors {
+			require.True(t, verifyCalldata(t, v.name, v.calldata), "%s vector %d", v.name, i)
+			require.False(t, verifyCalldata(t, v.name, wrongRootCalldata(v)), "%s vector %d", v.name, i)
+		}
+	}
+}
+
+// wrongRootCalldata returns the calldata of the vector with a zero root
+func wrongRootCalldata(v vector) []byte {
+	return bytes.Replace(v.calldata, v.root, make([]byte, hashSize), 1)
+}
+
+// verifyCalldata decodes the arguments of the verify call of a verifier contract and verifies the proof like the
+// contract does
+func verifyCalldata(t *testing.T, name string, calldata []byte) bool {
+	verifierABI := merkleVerifierABI
+	if name == "mmr" {
+		verifierABI = mmrVerifierABI
+	}
+	method := verifierABI.Methods["verify"]
+	require.Equal(t, method.ID, calldata[:4])
+	args, err := method.Inputs.Unpack(calldata[4:])
+	require.NoError(t, err)
+
+	root := args[0].([32]byte)
+	if name == "mmr" {
+		proof := mmr.NewProof(args[1].(*big.Int).Uint64(), hashesOf(args[4].([][32]byte)),
+			leavesOf(args[2].([]*big.Int), args[3].([][32]byte)), hasher.Keccak256Hasher{})
+		return proof.Verify(root[:])
+	}
+
+	proof := merkle.NewProof(leavesOf(args[1].([]*big.Int), args[2].([][32]byte)), hashesOf(args[3].([][32]byte)),
+		args[4].(*big.Int).Uint64(), hasher.Keccak256Hasher{})
+	verified, err := proof.Verify(root[:])
+	require.NoError(t, err)
+	return verified
+}
+
+func leavesOf(indices []*big.Int, hashes [][32]byte) []types.Leaf {
+	leaves := make([]types.Leaf, len(indices))
+	for i := range indices {
+		leaves[i] = types.Leaf{Index: indices[i].Uint64(), Hash: append([]byte{}, hashes[i][:]...)}
+	}
+	return leaves
+}
+
+func hashesOf(hashes [][32]byte) [][]byte {
+	result := make([][]byte, len(hashes))
+	for i := range hashes {
+		result[i] = append([]byte{}, hashes[i][:]...)
+	}
+	return result
+}
@@ -0,0 +1,302 @@
+// SPDX-License-Identifier: MIT
+// Code generated by github.com/ComposableFi/go-merkle-trees/solidity. DO NOT EDIT.
+pragma solidity ^0.8.0;
+
+/// @title MMRProof
+/// @notice Verifies the proofs of github.com/ComposableFi/go-merkle-trees/mmr merkle mountain ranges built with the
+/// Keccak256Hasher. A parent node is keccak256(left, right) and the peaks are bagged from the right to the left,
+/// keccak256(right, left).
+library MMRProof {
+    /// @dev a node of the mmr, the position starts with 0
+    struct Node {
+        uint256 pos;
+        bytes32 hash;
+        uint256 height;
+    }
+
+    /// @dev the proven leaves and the proof items with the indices of the next unused ones
+    struct Proof {
+        uint256[] positions;
+        bytes32[] leafHashes;
+        bytes32[] items;
+        uint256 leafCursor;
+        uint256 itemCursor;
+    }
+
+    /// @notice Verifies that the leaves are a part of the mmr of the given root
+    /// @param root the root of the mmr
+    /// @param mmrSize the number of the nodes of the mmr
+    /// @param leafIndices the indices of the proven leaves sorted in ascending order
+    /// @param leafHashes the hashes of the proven leaves in the order of leafIndices
+    /// @param proofItems the proof items in the order of the mmr proof
+    function verify(
+        bytes32 root,
+        uint256 mmrSize,
+        uint256[] memory leafIndices,
+        bytes32[] memory leafHashes,
+        bytes32[] memory proofItems
+    ) internal pure returns (bool) {
+        return calculateRoot(mmrSize, leafIndices, leafHashes, proofItems) == root;
+    }
+
+    /// @notice Calculates the root of the mmr from the proven leaves and the proof items
+    function calculateRoot(
+        uint256 mmrSize,
+        uint256[] memory leafIndices,
+        bytes32[] memory leafHashes,
+        bytes32[] memory proofItems
+    ) internal pure returns (bytes32) {
+        Proof memory proof = Proof(leafPositions(mmrSize, leafIndices, leafHashes), leafHashes, proofItems, 0, 0);
+        uint256[] memory peaks = getPeaks(mmrSize);
+        bytes32[] memory peaksHashes = new bytes32[](peaks.length + 1);
+        uint256 peaksCount = 0;
+        for (uint256 i = 0; i < peaks.length; i++) {
+            uint256 start = proof.leafCursor;
+            while (proof.leafCursor < proof.positions.length && proof.positions[proof.leafCursor] <= peaks[i]) {
+                proof.leafCursor++;
+            }
+
+            if (proof.leafCursor == start) {
+                // the next proof item is the peak hash or the bagged hash of the right peaks
+                if (proof.itemCursor == proof.items.length) {
+                    break;
+                }
+                peaksHashes[peaksCount] = nextItem(proof);
+            } else if (proof.leafCursor - start == 1 && proof.positions[start] == peaks[i]) {
+                peaksHashes[peaksCount] = leafHashes[start];
+            } else {
+                peaksHashes[peaksCount] = calculatePeakRoot(proof, start, peaks[i]);
+            }
+            peaksCount++;
+        }
+        require(proof.leafCursor == proof.positions.length, "MMRProof: corrupted proof");
+
+        // the bagged hash of the right peaks without proven leaves
+        if (proof.itemCursor < proof.items.length) {
+            peaksHashes[peaksCount] = nextItem(proof);
+            peaksCount++;
+        }
+        require(proof.itemCursor == proof.items.length, "MMRProof: corrupted proof");
+
+        return bagPeaks(peaksHashes, peaksCount);
+    }
+
+    /// @notice Returns the positions of the leaves, which must be sorted by their indices
+    function leafPositions(
+        uint256 mmrSize,
+        uint256[] memory leafIndices,
+        bytes32[] memory leafHashes
+    ) private pure returns (uint256[] memory positions) {
+        uint256 count = leafIndices.length;
+        require(count > 0 && count == leafHashes.length, "MMRProof: invalid leaves");
+
+        positions = new uint256[](count);
+        for (uint256 i = 0; i < count; i++) {
+            require(i == 0 || leafIndices[i] > leafIndices[i - 1], "MMRProof: leaves are not sorted");
+            positions[i] = leafIndexToPos(leafIndices[i]);
+            require(positions[i] < mmrSize, "MMRProof: leaf index out of range");
+        }
+    }
+
+    /// @notice Calculates the peak hash from the proven leaves from start to the leaf cursor, which are under the peak
+    function calculatePeakRoot(Proof memory proof, uint256 start, uint256 peakPos) private pure returns (bytes32) {
+        // the queue of the nodes sorted by height and position, the leaves are already sorted by position
+        Node[] memory queue = new Node[](proof.leafCursor - start);
+        for (uint256 i = start; i < proof.leafCursor; i++) {
+            queue[i - start] = Node(proof.positions[i], proof.leafHashes[i], 0);
+        }
+
+        uint256 head = 0;
+        uint256 tail = queue.length;
+        while (head < tail) {
+            Node memory node = queue[head];
+            head++;
+            if (node.pos == peakPos) {
+                return node.hash;
+            }
+
+            (Node memory parent, uint256 siblingPos) = parentOf(node);
+            bytes32 sibling;
+            if (head < tail && queue[head].pos == siblingPos) {
+                sibling = queue[head].hash;
+                head++;
+            } else {
+                sibling = nextItem(proof);
+            }
+
+            parent.hash = siblingPos < node.pos ? hashPair(sibling, node.hash) : hashPair(node.hash, sibling);
+            if (parent.pos >= peakPos) {
+                return parent.hash;
+            }
+
+            // the parent takes the slot of the popped node
+            head--;
+            insert(queue, head, tail, parent);
+        }
+
+        revert("MMRProof: corrupted proof");
+    }
+
+    /// @notice Returns the parent of the node without its hash and the position of the sibling of the node
+    function parentOf(Node memory node) private pure returns (Node memory, uint256) {
+        if (posHeightInTree(node.pos + 1) > node.height) {
+            // the next position is higher, so the node is the right child
+            return (Node(node.pos + 1, bytes32(0), node.height + 1), node.pos - siblingOffset(node.height));
+        }
+        return (
+            Node(node.pos + parentOffset(node.height), bytes32(0), node.height + 1),
+            node.pos + siblingOffset(node.height)
+        );
+    }
+
+    /// @notice Inserts the node into the sorted queue at the free slot of the given index or after it
+    function insert(Node[] memory queue, uint256 index, uint256 tail, Node memory node) private pure {
+        while (index + 1 < tail && lessThan(queue[index + 1], node)) {
+            queue[index] = queue[index + 1];
+            index++;
+        }
+        queue[index] = node;
+    }
+
+    function nextItem(Proof memory proof) private pure returns (bytes32 item) {
+        require(proof.itemCursor < proof.items.length, "MMRProof: corrupted proof");
+        item = proof.items[proof.itemCursor];
+        proof.itemCursor++;
+    }
+
+    function hashPair(bytes32 left, bytes32 right) private pure returns (bytes32) {
+        return keccak256(abi.encodePacked(left, right));
+    }
+
+    /// @notice Merges the peak hashes from the right to the left into the root
+    function bagPeaks(bytes32[] memory peaksHashes, uint256 peaksCount) private pure returns (bytes32) {
+        require(peaksCount > 0, "MMRProof: corrupted proof");
+        while (peaksCount > 1) {
+            bytes32 right = peaksHashes[peaksCount - 1];
+            bytes32 left = peaksHashes[peaksCount - 2];
+            peaksHashes[peaksCount - 2] = hashPair(right, left);
+            peaksCount--;
+        }
+        return peaksHashes[0];
+    }
+
+    /// @notice Returns the positions of the peaks of the mmr
+    function getPeaks(uint256 mmrSize) internal pure returns (uint256[] memory) {
+        (uint256 height, uint256 pos) = leftPeakHeightPos(mmrSize);
+        uint256[] memory buffer = new uint256[](64);
+        uint256 count = 0;
+        buffer[count] = pos;
+        count++;
+
+        while (height > 0) {
+            // move to the right sibling and take its left child until the node is in the mmr
+            pos += siblingOffset(height);
+            bool found = true;
+            while (pos > mmrSize - 1) {
+                if (height == 0) {
+                    found = false;
+                    break;
+                }
+                pos -= parentOffset(height - 1);
+                height--;
+            }
+            if (!found) {
+                break;
+            }
+            buffer[count] = pos;
+            count++;
+        }
+
+        uint256[] memory peaks = new uint256[](count);
+        for (uint256 i = 0; i < count; i++) {
+            peaks[i] = buffer[i];
+        }
+        return peaks;
+    }
+
+    /// @notice Returns the height and the position of the leftmost peak of the mmr
+    function leftPeakHeightPos(uint256 mmrSize) private pure returns (uint256, uint256) {
+        uint256 prevPos = 0;
+        uint256 height = 1;
+        uint256 pos = peakPosByHeight(height);
+        while (pos < mmrSize) {
+            height++;
+            prevPos = pos;
+            pos = peakPosByHeight(height);
+        }
+        return (height - 1, prevPos);
+    }
+
+    /// @notice Returns the height of the node at the given position
+    function posHeightInTree(uint256 pos) internal pure returns (uint256) {
+        pos++;
+        // jump to the left until the position is all ones, which is the leftmost node of its height
+        while ((pos & (pos + 1)) != 0) {
+            pos -= (uint256(1) << (bitLength(pos) - 1)) - 1;
+        }
+        return bitLength(pos) - 1;
+    }
+
+    /// @notice Returns the position of the leaf of the given index
+    function leafIndexToPos(uint256 index) internal pure returns (uint256) {
+        return leafIndexToMMRSize(index) - trailingZeros(index + 1) - 1;
+    }
+
+    /// @notice Returns the size of the mmr whose last leaf has the given index
+    function leafIndexToMMRSize(uint256 index) internal pure returns (uint256) {
+        uint256 leavesCount = index + 1;
+        return 2 * leavesCount - onesCount(leavesCount);
+    }
+
+    function peakPosByHeight(uint256 height) private pure returns (uint256) {
+        return (uint256(1) << (height + 1)) - 2;
+    }
+
+    function siblingOffset(uint256 height) private pure returns (uint256) {
+        return (uint256(2) << height) - 1;
+    }
+
+    function parentOffset(uint256 height) private pure returns (uint256) {
+        return uint256(2) << height;
+    }
+
+    function lessThan(Node memory a, Node memory b) private pure returns (bool) {
+        return a.height < b.height || (a.height == b.height && a.pos < b.pos);
+    }
+
+    function bitLength(uint256 n) private pure returns (uint256 length) {
+        while (n != 0) {
+            n >>= 1;
+            length++;
+        }
+    }
+
+    function onesCount(uint256 n) private pure returns (uint256 count) {
+        while (n != 0) {
+            n &= n - 1;
+            count++;
+        }
+    }
+
+    function trailingZeros(uint256 n) private pure returns (uint256 count) {
+        while ((n & 1) == 0) {
+            n >>= 1;
+            count++;
+        }
+    }
+}
+
+/// @title MMRProofVerifier
+/// @notice Exposes MMRProof to external calls, the calldata of the verify function is produced by
+/// solidity.MMRProofCalldata
+contract MMRProofVerifier {
+    function verify(
+        bytes32 root,
+        uint256 mmrSize,
+        uint256[] calldata leafIndices,
+        bytes32[] calldata leafHashes,
+        bytes32[] calldata proofItems
+    ) external pure returns (bool) {
+        return MMRProof.verify(root, mmrSize, leafIndices, leafHashes, proofItems);
+    }
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: MIT
+// Code generated by github.com/ComposableFi/go-merkle-trees/solidity. DO NOT EDIT.
+pragma solidity ^0.8.0;
+
+/// @title MerkleProof
+/// @notice Verifies the merkle multiproofs of github.com/ComposableFi/go-merkle-trees/merkle trees built with the
+/// Keccak256Hasher. A parent node is keccak256(left, right) and the last node of a layer with an odd number of
+/// nodes is promoted to the next layer without hashing.
+library MerkleProof {
+    /// @dev the nodes of a layer of the tree that are known to the verifier
+    struct Layer {
+        uint256[] indices;
+        bytes32[] hashes;
+        uint256 count;
+        uint256 size;
+    }
+
+    /// @dev the proof hashes and the index of the next unused one
+    struct Proof {
+        bytes32[] hashes;
+        uint256 cursor;
+    }
+
+    /// @notice Verifies that the leaves are a part of the tree of the given root
+    /// @param root the root of the tree
+    /// @param leafIndices the indices of the proven leaves sorted in ascending order
+    /// @param leafHashes the hashes of the proven leaves in the order of leafIndices
+    /// @param proofHashes the proof hashes sorted from the left to the right and from the bottom to the top
+    /// @param totalLeavesCount the number of the leaves of the tree
+    function verify(
+        bytes32 root,
+        uint256[] memory leafIndices,
+        bytes32[] memory leafHashes,
+        bytes32[] memory proofHashes,
+        uint256 totalLeavesCount
+    ) internal pure returns (bool) {
+        return calculateRoot(leafIndices, leafHashes, proofHashes, totalLeavesCount) == root;
+    }
+
+    /// @notice Calculates the root of the tree from the proven leaves and the proof hashes
+    function calculateRoot(
+        uint256[] memory leafIndices,
+        bytes32[] memory leafHashes,
+        bytes32[] memory proofHashes,
+        uint256 totalLeavesCount
+    ) internal pure returns (bytes32) {
+        uint256 count = leafIndices.length;
+        require(count > 0 && count == leafHashes.length, "MerkleProof: invalid leaves");
+
+        // the leaves are copied, so the arguments are never modified
+        Layer memory layer = Layer(new uint256[](count), new bytes32[](count), count, totalLeavesCount);
+        for (uint256 i = 0; i < count; i++) {
+            require(i == 0 || leafIndices[i] > leafIndices[i - 1], "MerkleProof: leaves are not sorted");
+            require(leafIndices[i] < totalLeavesCount, "MerkleProof: leaf index out of range");
+            layer.indices[i] = leafIndices[i];
+            layer.hashes[i] = leafHashes[i];
+        }
+
+        Proof memory proof = Proof(proofHashes, 0);
+        while (layer.size > 1) {
+            calculateParents(layer, proof);
+        }
+
+        require(proof.cursor == proof.hashes.length, "MerkleProof: too many proof hashes");
+        return layer.hashes[0];
+    }
+
+    /// @notice Replaces the nodes of the layer with their parents
+    function calculateParents(Layer memory layer, Proof memory proof) private pure {
+        uint256 parentsCount = 0;
+        for (uint256 i = 0; i < layer.count; i++) {
+            uint256 index = layer.indices[i];
+            bytes32 parent;
+            if (index % 2 == 1) {
+                // the left sibling would have been merged with this node if it was proven
+                parent = hashPair(nextProofHash(proof), layer.hashes[i]);
+            } else if (index == layer.size - 1) {
+                // the last node of a layer of an odd size is promoted
+                parent = layer.hashes[i];
+            } else if (i + 1 < layer.count && layer.indices[i + 1] == index + 1) {
+                parent = hashPair(layer.hashes[i], layer.hashes[i + 1]);
+                i++;
+            } else {
+                parent = hashPair(layer.hashes[i], nextProofHash(proof));
+            }
+
+            layer.indices[parentsCount] = index / 2;
+            layer.hashes[parentsCount] = parent;
+            parentsCount++;
+        }
+
+        layer.count = parentsCount;
+        layer.size = (layer.size + 1) / 2;
+    }
+
+    function nextProofHash(Proof memory proof) private pure returns (bytes32 hash) {
+        require(proof.cursor < proof.hashes.length, "MerkleProof: not enough proof hashes");
+        hash = proof.hashes[proof.cursor];
+        proof.cursor++;
+    }
+
+    function hashPair(bytes32 left, bytes32 right) private pure returns (bytes32) {
+        return keccak256(abi.encodePacked(left, right));
+    }
+}
+
+/// @title MerkleProofVerifier
+/// @notice Exposes MerkleProof to external calls, the calldata of the verify function is produced by
+/// solidity.MerkleProofCalldata
+contract MerkleProofVerifier {
+    function verify(
+        bytes32 root,
+        uint256[] calldata leafIndices,
+        bytes32[] calldata leafHashes,
+        bytes32[] calldata proofHashes,
+        uint256 totalLeavesCount
+    ) external pure returns (bool) {
+        return MerkleProof.verify(root, leafIndices, leafHashes, proofHashes, totalLeavesCount);
+    }
+}
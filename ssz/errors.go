@@ -0,0 +1,12 @@
+package ssz
+
+import "errors"
+
+var (
+	errInvalidChunkSize   = errors.New("the chunk size is not 32 bytes")
+	errTooManyChunks      = errors.New("the number of the chunks exceeds the limit")
+	errInvalidIndex       = errors.New("the generalized index is not in the tree")
+	errInvalidProofSize   = errors.New("the proof size doesn't match the generalized indices")
+	errInvalidLeavesCount = errors.New("the number of the leaves doesn't match the generalized indices")
+	errInvalidDepth       = errors.New("the tree depth is out of the range from 0 to MaxDepth")
+)
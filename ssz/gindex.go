@@ -0,0 +1,104 @@
+package ssz
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// GeneralizedIndex is the index of a node in the binary tree of an SSZ object, the root is 1 and the children of the
+// node i are 2i and 2i+1
+type GeneralizedIndex uint64
+
+// NewGeneralizedIndex returns the generalized index of the node at the position of the layer of the given depth
+func NewGeneralizedIndex(depth int, position uint64) GeneralizedIndex {
+	return GeneralizedIndex(1<<depth + position)
+}
+
+// ConcatGeneralizedIndices returns the generalized index of the node addressed by the path of generalized indices,
+// where every index is relative to the subtree of the previous one, like concat_generalized_indices
+func ConcatGeneralizedIndices(indices ...GeneralizedIndex) GeneralizedIndex {
+	o := GeneralizedIndex(1)
+	for _, i := range indices {
+		depth := i.Depth()
+		o = o<<depth + i - 1<<depth
+	}
+	return o
+}
+
+// Depth returns the depth of the node, the length of its path from the root, like get_generalized_index_length
+func (i GeneralizedIndex) Depth() int {
+	return bits.Len64(uint64(i)) - 1
+}
+
+// Position returns the position of the node in its layer
+func (i GeneralizedIndex) Position() uint64 {
+	return uint64(i) - 1<<i.Depth()
+}
+
+// Bit returns the bit of the path of the node at the position from the bottom, true means the right child, like
+// get_generalized_index_bit
+func (i GeneralizedIndex) Bit(position int) bool {
+	return i&(1<<position) != 0
+}
+
+// Sibling returns the index of the sibling of the node
+func (i GeneralizedIndex) Sibling() GeneralizedIndex {
+	return i ^ 1
+}
+
+// Child returns the index of the left or the right child of the node
+func (i GeneralizedIndex) Child(right bool) GeneralizedIndex {
+	if right {
+		return i*2 + 1
+	}
+	return i * 2
+}
+
+// Parent returns the index of the parent of the node
+func (i GeneralizedIndex) Parent() GeneralizedIndex {
+	return i / 2
+}
+
+// branchIndices returns the indices of the siblings of the path of the node from the bottom to the top, the proof of
+// the node, like get_branch_indices
+func (i GeneralizedIndex) branchIndices() []GeneralizedIndex {
+	var indices []GeneralizedIndex
+	for index := i; index > 1; index = index.Parent() {
+		indices = append(indices, index.Sibling())
+	}
+	return indices
+}
+
+// pathIndices returns the indices of the path of the node from the bottom to the top without the root, like
+// get_path_indices
+func (i GeneralizedIndex) pathIndices() []GeneralizedIndex {
+	var indices []GeneralizedIndex
+	for index := i; index > 1; index = index.Parent() {
+		indices = append(indices, index)
+	}
+	return indices
+}
+
+// HelperIndices returns the indices of the nodes of the multiproof of the nodes, sorted in the descending order like
+// get_helper_indices. They are the siblings of the paths of the nodes that are not on any of the paths.
+func HelperIndices(indices []GeneralizedIndex) []GeneralizedIndex {
+	helpers := make(map[GeneralizedIndex]bool)
+	paths := make(map[GeneralizedIndex]bool)
+	for _, index := range indices {
+		for _, branch := range index.branchIndices() {
+			helpers[branch] = true
+		}
+		for _, path := range index.pathIndices() {
+			paths[path] = true
+		}
+	}
+
+	var result []GeneralizedIndex
+	for index := range helpers {
+		if !paths[index] {
+			result = append(result, index)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] > result[j] })
+	return result
+}
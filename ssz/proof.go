@@ -0,0 +1,195 @@
+package ssz
+
+import (
+	"bytes"
+	"sort"
+)
+
+// Tree is the merkle tree of the chunks of an SSZ object padded to a power of two. Only the nodes that have a chunk in
+// their subtree are kept, the other nodes are the cached zero hashes.
+type Tree struct {
+	depth int
+	// layers are the nodes of the tree from the chunks to the root
+	layers [][][]byte
+}
+
+// NewTree builds the tree of the chunks padded with zero chunks to the next power of two of the limit, a zero limit
+// means the number of the chunks
+func NewTree(chunks [][]byte, limit uint64) (*Tree, error) {
+	if limit == 0 {
+		limit = uint64(len(chunks))
+	}
+	if uint64(len(chunks)) > limit {
+		return nil, errTooManyChunks
+	}
+	for _, chunk := range chunks {
+		if len(chunk) != ChunkSize {
+			return nil, errInvalidChunkSize
+		}
+	}
+
+	t := &Tree{depth: depthOf(limit), layers: [][][]byte{chunks}}
+	for depth := 0; depth < t.depth; depth++ {
+		layer := t.layers[depth]
+		parents := make([][]byte, (len(layer)+1)/2)
+		for i := range parents {
+			right := zeroHashes[depth]
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			}
+			parent, err := hashPair(layer[2*i], right)
+			if err != nil {
+				return nil, err
+			}
+			parents[i] = parent
+		}
+		t.layers = append(t.layers, parents)
+	}
+	return t, nil
+}
+
+// Depth returns the depth of the tree, the generalized index of the chunk i is 2^depth + i
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Root returns the root of the tree
+func (t *Tree) Root() []byte {
+	return t.Node(1)
+}
+
+// Node returns the node of the generalized index, it returns nil if the index is deeper than the tree
+func (t *Tree) Node(index GeneralizedIndex) []byte {
+	depth := index.Depth()
+	if index == 0 || depth > t.depth {
+		return nil
+	}
+
+	layer := t.layers[t.depth-depth]
+	if position := index.Position(); position < uint64(len(layer)) {
+		return layer[position]
+	}
+	return zeroHashes[t.depth-depth]
+}
+
+// Proof returns the proof of the node of the generalized index, the hashes of the siblings of its path from the bottom
+// to the top like the merkle branches of the consensus specification
+func (t *Tree) Proof(index GeneralizedIndex) ([][]byte, error) {
+	if index == 0 || index.Depth() > t.depth {
+		return nil, errInvalidIndex
+	}
+
+	branch := index.branchIndices()
+	proof := make([][]byte, len(branch))
+	for i, sibling := range branch {
+		proof[i] = t.Node(sibling)
+	}
+	return proof, nil
+}
+
+// MultiProof returns the proof of the nodes of the generalized indices, the nodes of HelperIndices
+func (t *Tree) MultiProof(indices []GeneralizedIndex) ([][]byte, error) {
+	for _, index := range indices {
+		if index == 0 || index.Depth() > t.depth {
+			return nil, errInvalidIndex
+		}
+	}
+
+	helpers := HelperIndices(indices)
+	proof := make([][]byte, len(helpers))
+	for i, helper := range helpers {
+		proof[i] = t.Node(helper)
+	}
+	return proof, nil
+}
+
+// CalculateMerkleRoot returns the root of the proof of the leaf at the generalized index, like calculate_merkle_root
+func CalculateMerkleRoot(leaf []byte, proof [][]byte, index GeneralizedIndex) ([]byte, error) {
+	if index == 0 || len(proof) != index.Depth() {
+		return nil, errInvalidProofSize
+	}
+
+	root := leaf
+	for i, hash := range proof {
+		var err error
+		if index.Bit(i) {
+			root, err = hashPair(hash, root)
+		} else {
+			root, err = hashPair(root, hash)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return root, nil
+}
+
+// VerifyMerkleProof verifies the proof of the leaf at the generalized index against the root, like
+// verify_merkle_proof
+func VerifyMerkleProof(leaf []byte, proof [][]byte, index GeneralizedIndex, root []byte) (bool, error) {
+	calculated, err := CalculateMerkleRoot(leaf, proof, index)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(calculated, root), nil
+}
+
+// CalculateMultiMerkleRoot returns the root of the multiproof of the leaves at the generalized indices, like
+// calculate_multi_merkle_root. The proof hashes are the nodes of HelperIndices of the indices.
+func CalculateMultiMerkleRoot(leaves, proof [][]byte, indices []GeneralizedIndex) ([]byte, error) {
+	if len(leaves) != len(indices) {
+		return nil, errInvalidLeavesCount
+	}
+	helpers := HelperIndices(indices)
+	if len(proof) != len(helpers) {
+		return nil, errInvalidProofSize
+	}
+
+	objects := make(map[GeneralizedIndex][]byte, len(leaves)+len(proof))
+	for i, index := range indices {
+		if index == 0 {
+			return nil, errInvalidIndex
+		}
+		objects[index] = leaves[i]
+	}
+	for i, index := range helpers {
+		objects[index] = proof[i]
+	}
+
+	keys := make([]GeneralizedIndex, 0, len(objects))
+	for index := range objects {
+		keys = append(keys, index)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] > keys[j] })
+
+	// the parents are appended to the keys, so they are processed after all of the deeper nodes
+	for pos := 0; pos < len(keys); pos++ {
+		k := keys[pos]
+		_, hasSibling := objects[k.Sibling()]
+		_, hasParent := objects[k.Parent()]
+		if k > 1 && hasSibling && !hasParent {
+			parent, err := hashPair(objects[(k|1)^1], objects[k|1])
+			if err != nil {
+				return nil, err
+			}
+			objects[k.Parent()] = parent
+			keys = append(keys, k.Parent())
+		}
+	}
+
+	root, ok := objects[1]
+	if !ok {
+		return nil, errInvalidIndex
+	}
+	return root, nil
+}
+
+// VerifyMerkleMultiproof verifies the multiproof of the leaves at the generalized indices against the root, like
+// verify_merkle_multiproof
+func VerifyMerkleMultiproof(leaves, proof [][]byte, indices []GeneralizedIndex, root []byte) (bool, error) {
+	calculated, err := CalculateMultiMerkleRoot(leaves, proof, indices)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(calculated, root), nil
+}
@@ -0,0 +1,86 @@
+// Package ssz is responsible for the SSZ merkleization of the Ethereum consensus layer, the hash_tree_root of the
+// consensus objects, and the generalized index proofs of the merkleized trees
+package ssz
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/ComposableFi/go-merkle-trees/hasher"
+)
+
+const (
+	// ChunkSize is the size of the chunks and the hashes of the SSZ merkle trees
+	ChunkSize = 32
+	// MaxDepth is the depth of the deepest tree that can be merkleized, the depth of a tree of 2^64 chunks
+	MaxDepth = 64
+)
+
+// zeroHashes caches the roots of the trees of zero chunks, zeroHashes[i] is the root of the tree of depth i
+var zeroHashes = calculateZeroHashes()
+
+// calculateZeroHashes calculates the roots of the zero chunk trees of every depth
+func calculateZeroHashes() [][]byte {
+	hashes := make([][]byte, MaxDepth+1)
+	hashes[0] = make([]byte, ChunkSize)
+	for i := 1; i <= MaxDepth; i++ {
+		hash, err := hashPair(hashes[i-1], hashes[i-1])
+		if err != nil {
+			panic(err)
+		}
+		hashes[i] = hash
+	}
+	return hashes
+}
+
+// ZeroHash returns the root of the tree of zero chunks of the given depth, which pads the merkleized chunks. The depth
+// must be in the range from 0 to MaxDepth.
+func ZeroHash(depth int) ([]byte, error) {
+	if depth < 0 || depth > MaxDepth {
+		return nil, errInvalidDepth
+	}
+	return zeroHashes[depth], nil
+}
+
+// Pack splits the serialized basic values into chunks, the last chunk is padded with zero bytes. The chunks of the
+// empty data are empty.
+func Pack(data []byte) [][]byte {
+	chunks := make([][]byte, (len(data)+ChunkSize-1)/ChunkSize)
+	for i := range chunks {
+		chunks[i] = make([]byte, ChunkSize)
+		copy(chunks[i], data[i*ChunkSize:])
+	}
+	return chunks
+}
+
+// Merkleize returns the root of the chunks padded with zero chunks to the next power of two of the limit, like the
+// merkleize function of the SSZ specification. A zero limit means the number of the chunks.
+func Merkleize(chunks [][]byte, limit uint64) ([]byte, error) {
+	tree, err := NewTree(chunks, limit)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Root(), nil
+}
+
+// MixInLength mixes the length of a list or a bitlist into its root, the hash of the root and the little endian
+// length as a 32 bytes chunk
+func MixInLength(root []byte, length uint64) ([]byte, error) {
+	chunk := make([]byte, ChunkSize)
+	binary.LittleEndian.PutUint64(chunk, length)
+	return hashPair(root, chunk)
+}
+
+// depthOf returns the depth of the tree of the next power of two chunks
+func depthOf(count uint64) int {
+	if count <= 1 {
+		return 0
+	}
+	return bits.Len64(count - 1)
+}
+
+// hashPair returns the sha256 hash of the concatenated hashes
+func hashPair(left, right []byte) ([]byte, error) {
+	pair := make([]byte, 0, len(left)+len(right))
+	return hasher.Sha256Hasher{}.Hash(append(append(pair, left...), right...))
+}
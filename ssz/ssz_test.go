@@ -0,0 +1,169 @@
+package ssz
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testChunks(count int) [][]byte {
+	chunks := make([][]byte, count)
+	for i := range chunks {
+		chunks[i] = make([]byte, ChunkSize)
+		chunks[i][0], chunks[i][ChunkSize-1] = byte(i+1), byte(count)
+	}
+	return chunks
+}
+
+func isAncestor(ancestor, index GeneralizedIndex) bool {
+	return index>>(index.Depth()-ancestor.Depth()) == ancestor
+}
+
+// zeroHash returns the zero hash of the depth in the supported range
+func zeroHash(t *testing.T, depth int) []byte {
+	hash, err := ZeroHash(depth)
+	require.NoError(t, err)
+	return hash
+}
+
+func TestZeroHashes(t *testing.T) {
+	require.Equal(t, make([]byte, ChunkSize), zeroHash(t, 0))
+	require.Equal(t, "f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a92759fb4b", hex.EncodeToString(zeroHash(t, 1)))
+	require.Equal(t, "db56114e00fdd4c1f85c892bf35ac9a89289aaecb1ebd0a96cde606a748b5d71", hex.EncodeToString(zeroHash(t, 2)))
+
+	// the root of the empty deposit contract is the empty tree of depth 32 mixed in with the zero deposit count
+	root, err := MixInLength(zeroHash(t, 32), 0)
+	require.NoError(t, err)
+	require.Equal(t, "d70a234731285c6804c2a4f56711ddb8c82c99740f207854891028af34e27e5e", hex.EncodeToString(root))
+
+	_, err = ZeroHash(MaxDepth)
+	require.NoError(t, err)
+	_, err = ZeroHash(MaxDepth + 1)
+	require.ErrorIs(t, err, errInvalidDepth)
+	_, err = ZeroHash(-1)
+	require.ErrorIs(t, err, errInvalidDepth)
+}
+
+func TestMerkleize(t *testing.T) {
+	root, err := Merkleize(nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, zeroHash(t, 0), root)
+
+	root, err = Merkleize(nil, 4)
+	require.NoError(t, err)
+	require.Equal(t, zeroHash(t, 2), root)
+
+	chunks := testChunks(3)
+	left, err := hashPair(chunks[0], chunks[1])
+	require.NoError(t, err)
+	right, err := hashPair(chunks[2], zeroHash(t, 0))
+	require.NoError(t, err)
+	expected, err := hashPair(left, right)
+	require.NoError(t, err)
+	root, err = Merkleize(chunks, 0)
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+
+	// the limit pads the tree with the zero subtrees
+	expected, err = hashPair(expected, zeroHash(t, 2))
+	require.NoError(t, err)
+	root, err = Merkleize(chunks, 5)
+	require.NoError(t, err)
+	require.Equal(t, expected, root)
+
+	_, err = Merkleize(chunks, 2)
+	require.ErrorIs(t, err, errTooManyChunks)
+	_, err = Merkleize([][]byte{{1}}, 0)
+	require.ErrorIs(t, err, errInvalidChunkSize)
+
+	require.Equal(t, [][]byte{}, Pack(nil))
+	packed := Pack(make([]byte, ChunkSize+1))
+	require.Len(t, packed, 2)
+	require.Equal(t, make([]byte, ChunkSize), packed[1])
+}
+
+func TestGeneralizedIndex(t *testing.T) {
+	require.Equal(t, GeneralizedIndex(5), ConcatGeneralizedIndices(2, 3))
+	require.Equal(t, GeneralizedIndex(1), ConcatGeneralizedIndices())
+	require.Equal(t, GeneralizedIndex(2*16+3), ConcatGeneralizedIndices(2, NewGeneralizedIndex(4, 3)))
+
+	index := NewGeneralizedIndex(3, 5)
+	require.Equal(t, GeneralizedIndex(13), index)
+	require.Equal(t, 3, index.Depth())
+	require.Equal(t, uint64(5), index.Position())
+	require.True(t, index.Bit(0))
+	require.False(t, index.Bit(1))
+	require.Equal(t, GeneralizedIndex(12), index.Sibling())
+	require.Equal(t, GeneralizedIndex(6), index.Parent())
+	require.Equal(t, GeneralizedIndex(27), index.Child(true))
+
+	require.Equal(t, []GeneralizedIndex{8, 5, 3}, HelperIndices([]GeneralizedIndex{9}))
+	require.Equal(t, []GeneralizedIndex{11, 8, 3}, HelperIndices([]GeneralizedIndex{9, 10}))
+}
+
+func TestProofs(t *testing.T) {
+	chunks := testChunks(11)
+	tree, err := NewTree(chunks, 16)
+	require.NoError(t, err)
+	require.Equal(t, 4, tree.Depth())
+	root, err := Merkleize(chunks, 16)
+	require.NoError(t, err)
+	require.Equal(t, root, tree.Root())
+
+	// every chunk, the padding chunks and the inner nodes
+	var indices []GeneralizedIndex
+	for i := uint64(0); i < 16; i++ {
+		indices = append(indices, NewGeneralizedIndex(4, i))
+	}
+	indices = append(indices, 2, 3, 7, 13)
+
+	for _, index := range indices {
+		proof, err := tree.Proof(index)
+		require.NoError(t, err)
+		verified, err := VerifyMerkleProof(tree.Node(index), proof, index, root)
+		require.NoError(t, err)
+		require.True(t, verified, "index %d", index)
+
+		// the padding chunks are equal to their siblings
+		verified, err = VerifyMerkleProof(tree.Node(index), proof, index.Sibling(), root)
+		require.NoError(t, err)
+		require.Equal(t, bytes.Equal(tree.Node(index), tree.Node(index.Sibling())), verified, "index %d", index)
+	}
+
+	for i, a := range indices[:16] {
+		for _, b := range indices[i+1 : 16] {
+			for _, multiIndices := range [][]GeneralizedIndex{{a, b}, {b, a, 3}, {a, b, 7}} {
+				if extra := multiIndices[len(multiIndices)-1]; isAncestor(extra, a) || isAncestor(extra, b) {
+					// a node and its ancestor can't be proven together
+					multiIndices = multiIndices[:2]
+				}
+				proof, err := tree.MultiProof(multiIndices)
+				require.NoError(t, err)
+				leaves := make([][]byte, len(multiIndices))
+				for j, index := range multiIndices {
+					leaves[j] = tree.Node(index)
+				}
+
+				verified, err := VerifyMerkleMultiproof(leaves, proof, multiIndices, root)
+				require.NoError(t, err)
+				require.True(t, verified, "indices %v", multiIndices)
+
+				leaves[0] = zeroHash(t, 1)
+				verified, err = VerifyMerkleMultiproof(leaves, proof, multiIndices, root)
+				require.NoError(t, err)
+				require.False(t, verified, "indices %v", multiIndices)
+			}
+		}
+	}
+
+	_, err = tree.Proof(NewGeneralizedIndex(5, 0))
+	require.ErrorIs(t, err, errInvalidIndex)
+	_, err = CalculateMerkleRoot(chunks[0], nil, 16)
+	require.ErrorIs(t, err, errInvalidProofSize)
+	_, err = CalculateMultiMerkleRoot(chunks[:1], nil, []GeneralizedIndex{16, 17})
+	require.ErrorIs(t, err, errInvalidLeavesCount)
+	_, err = CalculateMultiMerkleRoot(chunks[:2], nil, []GeneralizedIndex{16, 17})
+	require.ErrorIs(t, err, errInvalidProofSize)
+}
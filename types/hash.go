@@ -5,3 +5,10 @@ package types
 type Hasher interface {
 	Hash(data []byte) ([]byte, error)
 }
+
+// LeafHasher is a Hasher which hashes the leaves in a different domain than the inner nodes. Hash is used to hash the
+// concatenated children of the inner nodes and HashLeaf to hash the data of the leaves.
+type LeafHasher interface {
+	Hasher
+	HashLeaf(data []byte) ([]byte, error)
+}
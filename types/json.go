@@ -0,0 +1,54 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var errMissingLeafHash = errors.New("leaf hash is missing")
+
+// leafJSON is the JSON representation of Leaf, the hash is a 0x prefixed hex string
+type leafJSON struct {
+	Index uint64        `json:"index"`
+	Hash  hexutil.Bytes `json:"hash"`
+}
+
+// MarshalJSON encodes the leaf as {"index": 1, "hash": "0x..."}
+func (l Leaf) MarshalJSON() ([]byte, error) {
+	return json.Marshal(leafJSON{Index: l.Index, Hash: l.Hash})
+}
+
+// UnmarshalJSON decodes the leaf from the format of MarshalJSON
+func (l *Leaf) UnmarshalJSON(data []byte) error {
+	var decoded leafJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if len(decoded.Hash) == 0 {
+		return errMissingLeafHash
+	}
+
+	l.Index = decoded.Index
+	l.Hash = decoded.Hash
+	return nil
+}
+
+// HexList converts the hashes to the JSON representation of a list of 0x prefixed hex strings
+func HexList(hashes [][]byte) []hexutil.Bytes {
+	list := make([]hexutil.Bytes, len(hashes))
+	for i := 0; i < len(hashes); i++ {
+		list[i] = hashes[i]
+	}
+	return list
+}
+
+// BytesList converts a decoded list of hex strings back to the hashes
+func BytesList(list []hexutil.Bytes) [][]byte {
+	hashes := make([][]byte, len(list))
+	for i := 0; i < len(list); i++ {
+		hashes[i] = list[i]
+	}
+	return hashes
+}